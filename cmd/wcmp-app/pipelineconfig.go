@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineconfigctrl "github.com/onosproject/wcmp-app/pkg/controller/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/manager"
+	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/spf13/cobra"
+	"strconv"
+	"time"
+)
+
+const pipelineConfigCommandTimeout = 30 * time.Second
+
+func getPipelineConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "pipelineconfig",
+		Short: "Manage pipeline configs",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:   "history <pipeline config ID | target ID>",
+		Short: "List the retained revisions of a pipeline config",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPipelineConfigHistoryCommand,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "rollback <pipeline config ID | target ID> <revision>",
+		Short: "Roll a pipeline config back to a retained revision",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPipelineConfigRollbackCommand,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "status <pipeline config ID | target ID>",
+		Short: "Show the status of a pipeline config",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPipelineConfigStatusCommand,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "reset <pipeline config ID | target ID>",
+		Short: "Retry a failed pipeline config with its attempts starting over",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runPipelineConfigResetCommand,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "save <pipeline config ID | target ID>...",
+		Short: "Verify and save pipeline configs on their targets without running them",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runPipelineConfigSaveCommand,
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "commit <pipeline config ID | target ID>...",
+		Short: "Run pipeline configs saved on their targets",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runPipelineConfigCommitCommand,
+	})
+	return cmd
+}
+
+// newPipelineConfigStore creates a pipeline config store of the type given by the pipelineConfigStore flag.
+// The in-memory store only lives in the running wcmp-app, so a command opening it would act on a fresh and
+// empty store rather than on the pipeline configs of the app.
+func newPipelineConfigStore(cmd *cobra.Command) (pipelineconfig.Store, error) {
+	storeType, _ := cmd.Flags().GetString("pipelineConfigStore")
+	if storeType == manager.MemoryPipelineConfigStore {
+		return nil, errors.NewNotSupported("the %s pipeline config store cannot be shared with the %s command", storeType, cmd.CommandPath())
+	}
+	return manager.NewPipelineConfigStore(storeType)
+}
+
+func runPipelineConfigHistoryCommand(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineConfigCommandTimeout)
+	defer cancel()
+	store, err := newPipelineConfigStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	id, err := pipelineconfigctrl.ResolvePipelineConfigID(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+	history, err := store.History(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, pipelineConfig := range history {
+		var cookie uint64
+		if pipelineConfig.Cookie != nil {
+			cookie = pipelineConfig.Cookie.Cookie
+		}
+		cmd.Printf("%d\t%s\t%s\t%#x\n", pipelineConfig.Revision, pipelineConfig.Updated.Format(time.RFC3339), pipelineConfig.Action, cookie)
+	}
+	return nil
+}
+
+func runPipelineConfigRollbackCommand(cmd *cobra.Command, args []string) error {
+	revision, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineConfigCommandTimeout)
+	defer cancel()
+	store, err := newPipelineConfigStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	id, err := pipelineconfigctrl.ResolvePipelineConfigID(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+	return pipelineconfigctrl.RollbackPipelineConfig(ctx, store, id, p4rtapi.Revision(revision))
+}
+
+func runPipelineConfigStatusCommand(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineConfigCommandTimeout)
+	defer cancel()
+	store, err := newPipelineConfigStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	id, err := pipelineconfigctrl.ResolvePipelineConfigID(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+	pipelineConfig, err := store.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	details, err := store.GetStatusDetails(ctx, pipelineConfig.ID)
+	if err != nil {
+		return err
+	}
+	cmd.Printf("%s\t%s\t%s\t%s\t%s\n", pipelineConfig.ID, pipelineConfig.TargetID, pipelineConfig.Action, pipelineConfig.Status.State, details.Message)
+	if details.Attempts > 0 {
+		cmd.Printf("attempts: %d, last attempt: %s, last error: %s\n", details.Attempts, details.LastAttempt.Format(time.RFC3339), details.LastError)
+	}
+	return nil
+}
+
+func runPipelineConfigResetCommand(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineConfigCommandTimeout)
+	defer cancel()
+	store, err := newPipelineConfigStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	id, err := pipelineconfigctrl.ResolvePipelineConfigID(ctx, store, args[0])
+	if err != nil {
+		return err
+	}
+	return pipelineconfigctrl.ResetPipelineConfig(ctx, store, id)
+}
+
+func runPipelineConfigSaveCommand(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineConfigCommandTimeout)
+	defer cancel()
+	store, err := newPipelineConfigStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	ids, err := resolvePipelineConfigIDs(ctx, store, args)
+	if err != nil {
+		return err
+	}
+	return pipelineconfigctrl.SavePipelineConfigs(ctx, store, ids...)
+}
+
+func runPipelineConfigCommitCommand(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineConfigCommandTimeout)
+	defer cancel()
+	store, err := newPipelineConfigStore(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close(ctx)
+
+	ids, err := resolvePipelineConfigIDs(ctx, store, args)
+	if err != nil {
+		return err
+	}
+	return pipelineconfigctrl.CommitPipelineConfigs(ctx, store, ids...)
+}
+
+// resolvePipelineConfigIDs resolves each argument to a pipeline config ID, accepting target IDs for the
+// active pipeline config of the target
+func resolvePipelineConfigIDs(ctx context.Context, store pipelineconfig.Store, args []string) ([]p4rtapi.PipelineConfigID, error) {
+	ids := make([]p4rtapi.PipelineConfigID, 0, len(args))
+	for _, arg := range args {
+		id, err := pipelineconfigctrl.ResolvePipelineConfigID(ctx, store, arg)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	adminapi "github.com/onosproject/wcmp-app/api/admin/v1"
+	"github.com/spf13/cobra"
+)
+
+func getPluginCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Inspect the P4 plugins of the running wcmp-app",
+	}
+	diffCmd := &cobra.Command{
+		Use:   "diff <from P4 plugin ID> <to P4 plugin ID>",
+		Short: "List the P4Info changes between two P4 plugins and whether they are compatible or breaking",
+		Args:  cobra.ExactArgs(2),
+		RunE:  runPluginDiffCommand,
+	}
+	diffCmd.Flags().Bool("wcmp", false, "list only the changes to the entities of the WCMP mappings")
+	cmd.AddCommand(diffCmd)
+	return cmd
+}
+
+func runPluginDiffCommand(cmd *cobra.Command, args []string) error {
+	caPath, _ := cmd.Flags().GetString("caPath")
+	keyPath, _ := cmd.Flags().GetString("keyPath")
+	certPath, _ := cmd.Flags().GetString("certPath")
+	wcmpEndpoint, _ := cmd.Flags().GetString("wcmpEndpoint")
+	wcmpOnly, _ := cmd.Flags().GetBool("wcmp")
+
+	ctx, cancel := context.WithTimeout(context.Background(), pipelineConfigCommandTimeout)
+	defer cancel()
+
+	conn, err := newAdminConn(ctx, caPath, keyPath, certPath, wcmpEndpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	response, err := adminapi.NewAdminClient(conn).ComparePlugins(ctx, &adminapi.ComparePluginsRequest{
+		FromPluginId: args[0],
+		ToPluginId:   args[1],
+		WcmpOnly:     wcmpOnly,
+	})
+	if err != nil {
+		return errors.FromGRPC(err)
+	}
+	for _, change := range response.Changes {
+		compatibility := "compatible"
+		if change.Breaking {
+			compatibility = "breaking"
+		}
+		cmd.Printf("%s\t%s %s\t%s\t%s\n", change.Type, change.Kind, change.Name, compatibility, change.Details)
+	}
+	cmd.Printf("compatible: %t\n", response.Compatible)
+	return nil
+}
@@ -0,0 +1,87 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"github.com/onosproject/onos-lib-go/pkg/certs"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	adminapi "github.com/onosproject/wcmp-app/api/admin/v1"
+	"github.com/spf13/cobra"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"time"
+)
+
+func getUpgradeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade <P4 plugin ID>",
+		Short: "Upgrade the pipeline of targets to the pipeline of a P4 plugin, canaries first and then in batches",
+		Long: `Upgrade the pipeline of targets to the pipeline of a P4 plugin, canaries first and then in batches.
+The command asks the running wcmp-app to upgrade the targets and waits for the outcome. The wcmp-app relabels
+the targets in topo and checks that each upgraded target has a master and runs the new pipeline config.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runUpgradeCommand,
+	}
+	cmd.Flags().StringSlice("canary", []string{}, "target upgraded first, one at a time")
+	cmd.Flags().StringSlice("target", []string{}, "target upgraded in batches once the canaries are healthy")
+	cmd.Flags().Uint32("batchSize", 1, "number of targets upgraded concurrently")
+	cmd.Flags().Duration("targetTimeout", 5*time.Minute, "maximum time to wait for a target to run the new pipeline")
+	cmd.Flags().Bool("rollback", false, "roll the upgraded targets back on failure instead of pausing the upgrade")
+	return cmd
+}
+
+func runUpgradeCommand(cmd *cobra.Command, args []string) error {
+	caPath, _ := cmd.Flags().GetString("caPath")
+	keyPath, _ := cmd.Flags().GetString("keyPath")
+	certPath, _ := cmd.Flags().GetString("certPath")
+	wcmpEndpoint, _ := cmd.Flags().GetString("wcmpEndpoint")
+	canaries, _ := cmd.Flags().GetStringSlice("canary")
+	targets, _ := cmd.Flags().GetStringSlice("target")
+	batchSize, _ := cmd.Flags().GetUint32("batchSize")
+	targetTimeout, _ := cmd.Flags().GetDuration("targetTimeout")
+	rollback, _ := cmd.Flags().GetBool("rollback")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := newAdminConn(ctx, caPath, keyPath, certPath, wcmpEndpoint)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	response, err := adminapi.NewAdminClient(conn).Upgrade(ctx, &adminapi.UpgradeRequest{
+		PluginId:      args[0],
+		Canaries:      canaries,
+		Targets:       targets,
+		BatchSize:     batchSize,
+		TargetTimeout: durationpb.New(targetTimeout),
+		Rollback:      rollback,
+	})
+	if err != nil {
+		return errors.FromGRPC(err)
+	}
+	cmd.Printf("state: %s\n", response.State)
+	cmd.Printf("upgraded: %v\n", response.Upgraded)
+	cmd.Printf("failed: %v\n", response.Failed)
+	cmd.Printf("remaining: %v\n", response.Remaining)
+	if response.State == adminapi.UpgradeState_ROLLED_BACK {
+		cmd.Printf("rolled back: %v\n", response.RolledBack)
+	}
+	if response.Error != "" {
+		return errors.NewUnknown("upgrade stopped: %s", response.Error)
+	}
+	return nil
+}
+
+// newAdminConn connects to the admin service of the running wcmp-app
+func newAdminConn(ctx context.Context, caPath, keyPath, certPath, wcmpEndpoint string) (*grpc.ClientConn, error) {
+	opts, err := certs.HandleCertPaths(caPath, keyPath, certPath, true)
+	if err != nil {
+		return nil, err
+	}
+	return grpc.DialContext(ctx, wcmpEndpoint, opts...)
+}
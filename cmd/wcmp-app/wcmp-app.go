@@ -5,12 +5,16 @@
 package main
 
 import (
+	"bytes"
 	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"github.com/onosproject/wcmp-app/pkg/app/hosttracker"
+	"github.com/onosproject/wcmp-app/pkg/app/linkdiscovery"
 	"github.com/onosproject/wcmp-app/pkg/manager"
 	"github.com/spf13/cobra"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 )
 
 var log = logging.GetLogger()
@@ -29,11 +33,23 @@ func getRootCommand() *cobra.Command {
 		Short: "wcmp-app",
 		RunE:  runRootCommand,
 	}
-	cmd.Flags().String("caPath", "", "path to CA certificate")
-	cmd.Flags().String("keyPath", "", "path to client private key")
-	cmd.Flags().String("certPath", "", "path to client certificate")
-	cmd.Flags().String("topoEndpoint", "onos-topo:5150", "topology service endpoint")
-	cmd.Flags().StringSlice("p4Plugin", []string{}, "p4 plugin")
+	cmd.PersistentFlags().String("caPath", "", "path to CA certificate")
+	cmd.PersistentFlags().String("keyPath", "", "path to client private key")
+	cmd.PersistentFlags().String("certPath", "", "path to client certificate")
+	cmd.PersistentFlags().String("topoEndpoint", "onos-topo:5150", "topology service endpoint")
+	cmd.PersistentFlags().String("wcmpEndpoint", "wcmp-app:5150", "wcmp-app service endpoint used by the commands of the running app")
+	cmd.PersistentFlags().StringSlice("p4Plugin", []string{}, "p4 plugin")
+	cmd.PersistentFlags().String("p4PluginDir", "", "directory with a subdirectory of P4 artifacts for each p4 plugin")
+	cmd.PersistentFlags().String("pipelineConfigStore", manager.AtomixPipelineConfigStore, "pipeline config store type: atomix or memory")
+	cmd.Flags().Bool("warmRestart", false, "adopt the pipeline and forwarding state already installed on targets")
+	cmd.Flags().Int("pipelineConfigMaxAttempts", 5, "maximum number of attempts to push a pipeline config")
+	cmd.Flags().Duration("pipelineConfigGCGracePeriod", 5*time.Minute, "time a target must be missing from topo before its pipeline configs are deleted")
+	cmd.Flags().Duration("linkDiscoveryInterval", linkdiscovery.DefaultProbeInterval, "interval between two rounds of link discovery probes")
+	cmd.Flags().String("linkDiscoverySecretFile", "", "file with the secret link discovery probes are signed with, shared by all the replicas")
+	cmd.Flags().Duration("hostTimeout", hosttracker.DefaultHostTimeout, "time after which a host which sent no ARP or NDP packet is removed")
+	cmd.AddCommand(getPipelineConfigCommand())
+	cmd.AddCommand(getUpgradeCommand())
+	cmd.AddCommand(getPluginCommand())
 	return cmd
 }
 
@@ -43,21 +59,49 @@ func runRootCommand(cmd *cobra.Command, args []string) error {
 	certPath, _ := cmd.Flags().GetString("certPath")
 	topoEndpoint, _ := cmd.Flags().GetString("topoEndpoint")
 	p4Plugins, _ := cmd.Flags().GetStringSlice("p4Plugin")
+	p4PluginDir, _ := cmd.Flags().GetString("p4PluginDir")
+	warmRestart, _ := cmd.Flags().GetBool("warmRestart")
+	pipelineConfigMaxAttempts, _ := cmd.Flags().GetInt("pipelineConfigMaxAttempts")
+	pipelineConfigGCGracePeriod, _ := cmd.Flags().GetDuration("pipelineConfigGCGracePeriod")
+	pipelineConfigStore, _ := cmd.Flags().GetString("pipelineConfigStore")
+	linkDiscoveryInterval, _ := cmd.Flags().GetDuration("linkDiscoveryInterval")
+	linkDiscoverySecretFile, _ := cmd.Flags().GetString("linkDiscoverySecretFile")
+	hostTimeout, _ := cmd.Flags().GetDuration("hostTimeout")
 
 	log.Infow("Starting wcmp-app",
 		"CAPath", caPath,
 		"KeyPath", keyPath,
 		"CertPath", certPath,
 		"TopoAddress", topoEndpoint,
+		"P4PluginDir", p4PluginDir,
+		"WarmRestart", warmRestart,
+		"PipelineConfigStore", pipelineConfigStore,
 	)
 
+	var linkDiscoverySecret []byte
+	if linkDiscoverySecretFile != "" {
+		secret, err := os.ReadFile(linkDiscoverySecretFile)
+		if err != nil {
+			return err
+		}
+		linkDiscoverySecret = bytes.TrimSpace(secret)
+	}
+
 	cfg := manager.Config{
-		CAPath:      caPath,
-		KeyPath:     keyPath,
-		CertPath:    certPath,
-		TopoAddress: topoEndpoint,
-		GRPCPort:    5150,
-		P4Plugins:   p4Plugins,
+		CAPath:                      caPath,
+		KeyPath:                     keyPath,
+		CertPath:                    certPath,
+		TopoAddress:                 topoEndpoint,
+		GRPCPort:                    5150,
+		P4Plugins:                   p4Plugins,
+		P4PluginDir:                 p4PluginDir,
+		WarmRestart:                 warmRestart,
+		PipelineConfigStore:         pipelineConfigStore,
+		PipelineConfigMaxAttempts:   pipelineConfigMaxAttempts,
+		PipelineConfigGCGracePeriod: pipelineConfigGCGracePeriod,
+		LinkDiscoveryInterval:       linkDiscoveryInterval,
+		LinkDiscoverySecret:         linkDiscoverySecret,
+		HostTimeout:                 hostTimeout,
 	}
 
 	mgr := manager.NewManager(cfg)
@@ -5,6 +5,7 @@ go 1.16
 require (
 	github.com/atomix/atomix-go-client v0.6.2
 	github.com/atomix/atomix-go-framework v0.10.1
+	github.com/fsnotify/fsnotify v1.5.1
 	github.com/gogo/protobuf v1.3.2
 	github.com/google/uuid v1.3.0
 	github.com/onosproject/helmit v0.6.19
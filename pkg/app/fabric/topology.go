@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package fabric
+
+import (
+	"context"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/wcmp-app/pkg/controller/utils"
+	"github.com/onosproject/wcmp-app/pkg/store/topo"
+)
+
+const (
+	// RoleLabel is the label of switch entities holding the role of the switch in the fabric
+	RoleLabel = "role"
+	// LeafRole is the role of the switches hosts are attached to
+	LeafRole = "leaf"
+)
+
+// Topology is a snapshot of the switches, ports and links of the fabric
+type Topology struct {
+	// mastered are the switches mastered by this controller
+	mastered map[topoapi.ID]bool
+	// leaves are the switches with the leaf role
+	leaves map[topoapi.ID]bool
+	// ports are the port entities of each switch by port number
+	ports map[topoapi.ID]map[uint32]topoapi.ID
+	// portSwitches are the switches of each port entity
+	portSwitches map[topoapi.ID]topoapi.ID
+	// links are the link relations by ID
+	links map[topoapi.ID]*topoapi.Relation
+	// linkPorts are the port entities which are an end of a link
+	linkPorts map[topoapi.ID]bool
+}
+
+// LoadTopology loads a snapshot of the fabric topology from the topo store
+func LoadTopology(ctx context.Context, topoStore topo.Store) (*Topology, error) {
+	objects, err := topoStore.List(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	t := &Topology{
+		mastered:     make(map[topoapi.ID]bool),
+		leaves:       make(map[topoapi.ID]bool),
+		ports:        make(map[topoapi.ID]map[uint32]topoapi.ID),
+		portSwitches: make(map[topoapi.ID]topoapi.ID),
+		links:        make(map[topoapi.ID]*topoapi.Relation),
+		linkPorts:    make(map[topoapi.ID]bool),
+	}
+	relations := make(map[topoapi.ID]*topoapi.Relation)
+	portNumbers := make(map[topoapi.ID]uint32)
+	var switches []topoapi.Object
+	for _, object := range objects {
+		switch obj := object.Obj.(type) {
+		case *topoapi.Object_Relation:
+			relations[object.ID] = obj.Relation
+		case *topoapi.Object_Entity:
+			phyPort := &topoapi.PhyPort{}
+			if err := object.GetAspect(phyPort); err == nil {
+				portNumbers[object.ID] = phyPort.PortNumber
+			}
+			if err := object.GetAspect(&topoapi.P4RTServerInfo{}); err == nil {
+				switches = append(switches, object)
+			}
+		}
+	}
+
+	controllerID := utils.GetControllerID()
+	for _, object := range switches {
+		if object.Labels[RoleLabel] == LeafRole {
+			t.leaves[object.ID] = true
+		}
+		mastership := &topoapi.P4RTMastershipState{}
+		if err := object.GetAspect(mastership); err != nil {
+			continue
+		}
+		if relation, ok := relations[topoapi.ID(mastership.NodeId)]; ok && relation.SrcEntityID == controllerID {
+			t.mastered[object.ID] = true
+		}
+	}
+	for id, relation := range relations {
+		switch relation.KindID {
+		case topoapi.CONTAINS:
+			portNumber, ok := portNumbers[relation.TgtEntityID]
+			if !ok {
+				continue
+			}
+			ports, ok := t.ports[relation.SrcEntityID]
+			if !ok {
+				ports = make(map[uint32]topoapi.ID)
+				t.ports[relation.SrcEntityID] = ports
+			}
+			ports[portNumber] = relation.TgtEntityID
+			t.portSwitches[relation.TgtEntityID] = relation.SrcEntityID
+		case topoapi.LinkKind:
+			t.links[id] = relation
+			t.linkPorts[relation.SrcEntityID] = true
+			t.linkPorts[relation.TgtEntityID] = true
+		}
+	}
+	return t, nil
+}
+
+// IsMastered returns whether a switch is mastered by this controller
+func (t *Topology) IsMastered(switchID topoapi.ID) bool {
+	return t.mastered[switchID]
+}
+
+// IsLeaf returns whether a switch has the leaf role
+func (t *Topology) IsLeaf(switchID topoapi.ID) bool {
+	return t.leaves[switchID]
+}
+
+// GetMasteredSwitches returns the switches mastered by this controller
+func (t *Topology) GetMasteredSwitches() []topoapi.ID {
+	switches := make([]topoapi.ID, 0, len(t.mastered))
+	for switchID := range t.mastered {
+		switches = append(switches, switchID)
+	}
+	return switches
+}
+
+// GetPorts returns the port entities of a switch by port number
+func (t *Topology) GetPorts(switchID topoapi.ID) map[uint32]topoapi.ID {
+	return t.ports[switchID]
+}
+
+// GetPort returns the port entity of a switch port
+func (t *Topology) GetPort(switchID topoapi.ID, portNumber uint32) (topoapi.ID, bool) {
+	portID, ok := t.ports[switchID][portNumber]
+	return portID, ok
+}
+
+// GetSwitch returns the switch of a port entity
+func (t *Topology) GetSwitch(portID topoapi.ID) (topoapi.ID, bool) {
+	switchID, ok := t.portSwitches[portID]
+	return switchID, ok
+}
+
+// GetLinks returns the link relations by ID
+func (t *Topology) GetLinks() map[topoapi.ID]*topoapi.Relation {
+	return t.links
+}
+
+// IsEdgePort returns whether a port entity is not an end of any link, i.e. whether hosts may be attached to it
+func (t *Topology) IsEdgePort(portID topoapi.ID) bool {
+	return !t.linkPorts[portID]
+}
@@ -0,0 +1,86 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hosttracker
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+)
+
+const (
+	// HostKind is the kind of host entities
+	HostKind = "host"
+	// HostAttachmentKind is the kind of relations from a host entity to the edge port entity it is attached to
+	HostAttachmentKind = "attachment"
+	// HostAspect is the type of the aspect of host entities describing the host
+	HostAspect = "wcmp-app.Host"
+)
+
+// Host is a host attached to an edge port of the fabric
+type Host struct {
+	MAC string `json:"mac"`
+	// VLANID is the VLAN the host was seen on, or zero if its packets are untagged
+	VLANID uint16 `json:"vlanId,omitempty"`
+	// IPs are the IPv4 and IPv6 addresses of the host
+	IPs []string `json:"ips,omitempty"`
+	// Location is the edge port entity the host is attached to
+	Location topoapi.ID `json:"location"`
+}
+
+// NewHostID returns the ID of the entity of the host with the given MAC address and VLAN
+func NewHostID(mac net.HardwareAddr, vlanID uint16) topoapi.ID {
+	return topoapi.ID(fmt.Sprintf("host:%s/%d", mac, vlanID))
+}
+
+// GetHost returns the host described by a host entity
+func GetHost(object *topoapi.Object) (*Host, error) {
+	bytes, err := object.GetAspectBytes(HostAspect)
+	if err != nil {
+		return nil, err
+	}
+	host := &Host{}
+	if err := json.Unmarshal(bytes, host); err != nil {
+		return nil, err
+	}
+	return host, nil
+}
+
+func setHost(object *topoapi.Object, host *Host) error {
+	bytes, err := json.Marshal(host)
+	if err != nil {
+		return err
+	}
+	return object.SetAspectBytes(HostAspect, bytes)
+}
+
+func newAttachmentID(hostID, portID topoapi.ID) topoapi.ID {
+	return topoapi.ID(fmt.Sprintf("%s-%s", hostID, portID))
+}
+
+// hasIP returns whether the host has the given address
+func (h *Host) hasIP(ip string) bool {
+	for _, hostIP := range h.IPs {
+		if hostIP == ip {
+			return true
+		}
+	}
+	return false
+}
+
+// contains returns whether the host is at the location of an update and already has all its addresses
+func (h *Host) contains(update *Host) bool {
+	if h.Location != update.Location {
+		return false
+	}
+	for _, ip := range update.IPs {
+		if !h.hasIP(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,470 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hosttracker
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"github.com/onosproject/wcmp-app/pkg/app/fabric"
+	"github.com/onosproject/wcmp-app/pkg/packet"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	"github.com/onosproject/wcmp-app/pkg/store/topo"
+)
+
+var log = logging.GetLogger()
+
+const (
+	// DefaultHostTimeout is the default time after which a host which sent no ARP or NDP packet is removed
+	DefaultHostTimeout = 5 * time.Minute
+	// topologyRefreshInterval is the interval between two refreshes of the snapshot of the fabric topology
+	topologyRefreshInterval = 5 * time.Second
+	defaultTimeout          = 30 * time.Second
+	queueSize               = 100
+
+	// ingressPortMetadata is the packet-in metadata holding the port a packet is received on
+	ingressPortMetadata = "ingress_port"
+)
+
+// Service learns the hosts attached to the edge ports of the leaf switches mastered by this controller from
+// the ARP and NDP packets they send, and maintains their host entities and attachment relations in topo
+type Service struct {
+	topo        topo.Store
+	conns       p4rt.ConnManager
+	hostTimeout time.Duration
+	topology    *fabric.Topology
+	// hosts are the hosts learned by this controller
+	hosts map[topoapi.ID]*Host
+	// seen are the hosts attached to the mastered switches with the time they were last seen
+	seen   map[topoapi.ID]time.Time
+	mu     sync.RWMutex
+	cancel context.CancelFunc
+	// learnMu serializes the updates of the host entities
+	learnMu sync.Mutex
+}
+
+// NewService returns a new host tracker
+func NewService(topo topo.Store, conns p4rt.ConnManager, hostTimeout time.Duration) *Service {
+	if hostTimeout <= 0 {
+		hostTimeout = DefaultHostTimeout
+	}
+	return &Service{
+		topo:        topo,
+		conns:       conns,
+		hostTimeout: hostTimeout,
+		hosts:       make(map[topoapi.ID]*Host),
+		seen:        make(map[topoapi.ID]time.Time),
+	}
+}
+
+// Start starts learning hosts from the packets received by the switches
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return nil
+	}
+
+	connCh := make(chan p4rt.Conn, queueSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.conns.Watch(ctx, connCh); err != nil {
+		cancel()
+		return err
+	}
+	s.cancel = cancel
+	go s.watchConns(ctx, connCh)
+	go s.run(ctx)
+	return nil
+}
+
+// Stop stops the host tracker
+func (s *Service) Stop() {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.mu.Unlock()
+}
+
+// watchConns subscribes to the packet-ins of each connection while it is open
+func (s *Service) watchConns(ctx context.Context, connCh <-chan p4rt.Conn) {
+	subscriptions := make(map[p4rt.ConnID]context.CancelFunc)
+	for conn := range connCh {
+		if _, ok := s.conns.Get(ctx, conn.ID()); !ok {
+			if cancelSubscription, ok := subscriptions[conn.ID()]; ok {
+				cancelSubscription()
+				delete(subscriptions, conn.ID())
+			}
+			continue
+		}
+		if _, ok := subscriptions[conn.ID()]; ok {
+			continue
+		}
+		subscriptionCtx, cancelSubscription := context.WithCancel(ctx)
+		packetCh := make(chan *p4rt.PacketIn, queueSize)
+		if err := conn.SubscribePacketIn(subscriptionCtx, packetCh); err != nil {
+			log.Warnw("Failed subscribing to packet-ins", "targetID", conn.TargetID(), "error", err)
+			cancelSubscription()
+			continue
+		}
+		subscriptions[conn.ID()] = cancelSubscription
+		go func(targetID topoapi.ID) {
+			for packetIn := range packetCh {
+				s.processPacketIn(subscriptionCtx, targetID, packetIn)
+			}
+		}(conn.TargetID())
+	}
+}
+
+func (s *Service) run(ctx context.Context) {
+	s.refreshTopology(ctx)
+	ticker := time.NewTicker(topologyRefreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if t := s.refreshTopology(ctx); t != nil {
+				s.expireHosts(ctx, t)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// refreshTopology refreshes the topology snapshot, returning nil if the topology cannot be loaded
+func (s *Service) refreshTopology(ctx context.Context) *fabric.Topology {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	t, err := fabric.LoadTopology(ctx, s.topo)
+	if err != nil {
+		log.Warnw("Failed loading topology for host tracking", "error", err)
+		return nil
+	}
+	s.mu.Lock()
+	s.topology = t
+	s.mu.Unlock()
+	return t
+}
+
+// expireHosts deletes the hosts attached to the mastered switches that were not seen for the host timeout
+func (s *Service) expireHosts(ctx context.Context, t *fabric.Topology) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+	hosts, err := s.listHosts(ctx)
+	if err != nil {
+		log.Warnw("Failed listing hosts", "error", err)
+		return
+	}
+
+	s.learnMu.Lock()
+	defer s.learnMu.Unlock()
+	now := time.Now()
+	var expired []*topoapi.Object
+	attached := make(map[topoapi.ID]bool)
+	s.mu.Lock()
+	for i := range hosts {
+		object := &hosts[i]
+		host, err := GetHost(object)
+		if err != nil {
+			continue
+		}
+		if switchID, ok := t.GetSwitch(host.Location); !ok || !t.IsMastered(switchID) {
+			continue
+		}
+		attached[object.ID] = true
+		// Hosts learned before a restart or a mastership change are given a chance to be seen again
+		lastSeen, ok := s.seen[object.ID]
+		if !ok {
+			s.seen[object.ID] = now
+			continue
+		}
+		if now.Sub(lastSeen) > s.hostTimeout {
+			expired = append(expired, object)
+		}
+	}
+	// Forget the hosts removed from topo or moved to switches mastered by other controllers
+	for hostID := range s.seen {
+		if !attached[hostID] {
+			delete(s.seen, hostID)
+			delete(s.hosts, hostID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, object := range expired {
+		if err := s.deleteHost(ctx, object); err != nil {
+			log.Warnw("Failed deleting expired host", "hostID", object.ID, "error", err)
+		}
+	}
+}
+
+// deleteHost deletes a host entity and its attachment relation
+func (s *Service) deleteHost(ctx context.Context, object *topoapi.Object) error {
+	host, err := GetHost(object)
+	if err != nil {
+		return err
+	}
+	log.Infow("Host expired", "hostID", object.ID, "location", host.Location)
+	if err := s.deleteAttachment(ctx, newAttachmentID(object.ID, host.Location)); err != nil {
+		return err
+	}
+	if err := s.topo.Delete(ctx, object); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	s.mu.Lock()
+	delete(s.seen, object.ID)
+	delete(s.hosts, object.ID)
+	s.mu.Unlock()
+	return nil
+}
+
+// listHosts lists the host entities
+func (s *Service) listHosts(ctx context.Context) ([]topoapi.Object, error) {
+	objects, err := s.topo.List(ctx, &topoapi.Filters{
+		KindFilter: &topoapi.Filter{
+			Filter: &topoapi.Filter_Equal_{
+				Equal_: &topoapi.EqualFilter{
+					Value: HostKind,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	hosts := objects[:0]
+	for _, object := range objects {
+		if object.GetEntity().GetKindID() == HostKind {
+			hosts = append(hosts, object)
+		}
+	}
+	return hosts, nil
+}
+
+// processPacketIn learns the host which sent an ARP or NDP packet received on an edge port of a leaf switch
+func (s *Service) processPacketIn(ctx context.Context, targetID topoapi.ID, packetIn *p4rt.PacketIn) {
+	frame, err := packet.ParseEthernet(packetIn.Payload)
+	if err != nil || isMulticast(frame.Src) {
+		return
+	}
+	var ip net.IP
+	switch frame.EtherType {
+	case packet.EtherTypeARP:
+		arp, err := packet.ParseARP(frame.Payload)
+		if err != nil {
+			return
+		}
+		ip = arp.SenderIP
+	case packet.EtherTypeIPv6:
+		ndp, err := packet.ParseNDP(frame.Payload)
+		if err != nil {
+			return
+		}
+		ip = ndp.SrcIP
+	default:
+		return
+	}
+	portValue, ok := packetIn.Metadata[ingressPortMetadata]
+	if !ok {
+		log.Warnw("Ignoring packet-in without ingress port", "targetID", targetID)
+		return
+	}
+
+	s.mu.RLock()
+	t := s.topology
+	s.mu.RUnlock()
+	// No host is attached to spine switches, whose ports may not all be known as link ports yet
+	if t == nil || !t.IsLeaf(targetID) {
+		return
+	}
+	portID, ok := t.GetPort(targetID, uint32(p4rt.DecodeUint(portValue)))
+	if !ok || !t.IsEdgePort(portID) {
+		return
+	}
+
+	host := &Host{
+		MAC:      frame.Src.String(),
+		VLANID:   frame.VLANID,
+		Location: portID,
+	}
+	// Duplicate address detection probes and link-local addresses do not identify a routable host address
+	if !ip.IsUnspecified() && !ip.IsLinkLocalUnicast() {
+		host.IPs = []string{ip.String()}
+	}
+	if err := s.learn(ctx, NewHostID(frame.Src, frame.VLANID), host); err != nil {
+		log.Warnw("Failed updating host", "MAC", host.MAC, "location", portID, "error", err)
+	}
+}
+
+// learn merges the location and addresses of a host into the host entity and its attachment relation
+func (s *Service) learn(ctx context.Context, hostID topoapi.ID, update *Host) error {
+	s.learnMu.Lock()
+	defer s.learnMu.Unlock()
+
+	s.mu.Lock()
+	s.seen[hostID] = time.Now()
+	current, ok := s.hosts[hostID]
+	s.mu.Unlock()
+	if ok && current.contains(update) {
+		return nil
+	}
+
+	object, err := s.topo.Get(ctx, hostID)
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if !ok && object != nil {
+		// Hosts learned before a restart or by another controller
+		if current, err = GetHost(object); err != nil {
+			current = nil
+		}
+	}
+
+	host := &Host{MAC: update.MAC, VLANID: update.VLANID, Location: update.Location}
+	changed := current == nil || current.Location != update.Location
+	if current != nil {
+		host.IPs = append(host.IPs, current.IPs...)
+	}
+	var learnedIPs []string
+	for _, ip := range update.IPs {
+		if !host.hasIP(ip) {
+			host.IPs = append(host.IPs, ip)
+			learnedIPs = append(learnedIPs, ip)
+			changed = true
+		}
+	}
+	if !changed {
+		s.mu.Lock()
+		s.hosts[hostID] = host
+		s.mu.Unlock()
+		return nil
+	}
+
+	if object == nil {
+		log.Infow("Discovered host", "hostID", hostID, "location", host.Location, "IPs", host.IPs)
+		object = &topoapi.Object{
+			ID:   hostID,
+			Type: topoapi.Object_ENTITY,
+			Obj: &topoapi.Object_Entity{
+				Entity: &topoapi.Entity{
+					KindID: HostKind,
+				},
+			},
+		}
+		if err := setHost(object, host); err != nil {
+			return err
+		}
+		if err := s.topo.Create(ctx, object); err != nil {
+			return err
+		}
+	} else {
+		log.Infow("Updating host", "hostID", hostID, "location", host.Location, "IPs", host.IPs)
+		if err := setHost(object, host); err != nil {
+			return err
+		}
+		if err := s.topo.Update(ctx, object); err != nil {
+			return err
+		}
+	}
+
+	if current != nil && current.Location != host.Location {
+		log.Infow("Host moved", "hostID", hostID, "previous location", current.Location, "location", host.Location)
+		if err := s.deleteAttachment(ctx, newAttachmentID(hostID, current.Location)); err != nil {
+			return err
+		}
+	}
+	err = s.topo.Create(ctx, &topoapi.Object{
+		ID:   newAttachmentID(hostID, host.Location),
+		Type: topoapi.Object_RELATION,
+		Obj: &topoapi.Object_Relation{
+			Relation: &topoapi.Relation{
+				KindID:      HostAttachmentKind,
+				SrcEntityID: hostID,
+				TgtEntityID: host.Location,
+			},
+		},
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	s.mu.Lock()
+	s.hosts[hostID] = host
+	s.mu.Unlock()
+	if len(learnedIPs) > 0 {
+		return s.releaseIPs(ctx, hostID, learnedIPs)
+	}
+	return nil
+}
+
+// releaseIPs removes the addresses learned for a host from the hosts which previously had them
+func (s *Service) releaseIPs(ctx context.Context, hostID topoapi.ID, ips []string) error {
+	hosts, err := s.listHosts(ctx)
+	if err != nil {
+		return err
+	}
+	moved := make(map[string]bool)
+	for _, ip := range ips {
+		moved[ip] = true
+	}
+	for i := range hosts {
+		object := &hosts[i]
+		if object.ID == hostID {
+			continue
+		}
+		previous, err := GetHost(object)
+		if err != nil {
+			continue
+		}
+		host := &Host{MAC: previous.MAC, VLANID: previous.VLANID, Location: previous.Location}
+		for _, ip := range previous.IPs {
+			if !moved[ip] {
+				host.IPs = append(host.IPs, ip)
+			}
+		}
+		if len(host.IPs) == len(previous.IPs) {
+			continue
+		}
+		log.Infow("Host addresses moved to another host", "hostID", object.ID, "new hostID", hostID, "IPs", ips)
+		if err := setHost(object, host); err != nil {
+			return err
+		}
+		if err := s.topo.Update(ctx, object); err != nil {
+			return err
+		}
+		s.mu.Lock()
+		if _, ok := s.hosts[object.ID]; ok {
+			s.hosts[object.ID] = host
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (s *Service) deleteAttachment(ctx context.Context, relationID topoapi.ID) error {
+	object, err := s.topo.Get(ctx, relationID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if err := s.topo.Delete(ctx, object); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// isMulticast returns whether a MAC address is a multicast or broadcast address
+func isMulticast(mac net.HardwareAddr) bool {
+	return len(mac) > 0 && mac[0]&0x01 != 0
+}
@@ -0,0 +1,252 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package hosttracker
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/app/fabric"
+	"github.com/onosproject/wcmp-app/pkg/controller/utils"
+	"github.com/onosproject/wcmp-app/pkg/packet"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTopo is a topology store with helpers building the topology of the tests
+type testTopo struct {
+	*topotest.Store
+}
+
+func newTestTopo() *testTopo {
+	return &testTopo{Store: topotest.NewStore()}
+}
+
+// object returns the stored topology object with the given ID, or nil if there is none
+func (t *testTopo) object(id topoapi.ID) *topoapi.Object {
+	object, _ := t.Get(context.Background(), id)
+	return object
+}
+
+// len returns the number of stored topology objects
+func (t *testTopo) len() int {
+	objects, _ := t.List(context.Background(), nil)
+	return len(objects)
+}
+
+func (t *testTopo) addRelation(id topoapi.ID, kind topoapi.ID, src, tgt topoapi.ID) {
+	_ = t.Update(context.Background(), &topoapi.Object{
+		ID:   id,
+		Type: topoapi.Object_RELATION,
+		Obj: &topoapi.Object_Relation{
+			Relation: &topoapi.Relation{KindID: kind, SrcEntityID: src, TgtEntityID: tgt},
+		},
+	})
+}
+
+// addSwitch adds a switch with a given role mastered by this controller with the given ports
+func (t *testTopo) addSwitch(tt *testing.T, switchID topoapi.ID, role string, portNumbers ...uint32) {
+	object := &topoapi.Object{
+		ID:     switchID,
+		Type:   topoapi.Object_ENTITY,
+		Obj:    &topoapi.Object_Entity{Entity: &topoapi.Entity{}},
+		Labels: map[string]string{fabric.RoleLabel: role},
+	}
+	assert.NoError(tt, object.SetAspect(&topoapi.P4RTServerInfo{}))
+	controlsID := topoapi.ID(fmt.Sprintf("%s-controls", switchID))
+	t.addRelation(controlsID, topoapi.CONTROLS, utils.GetControllerID(), switchID)
+	assert.NoError(tt, object.SetAspect(&topoapi.P4RTMastershipState{NodeId: string(controlsID)}))
+	assert.NoError(tt, t.Create(context.Background(), object))
+
+	for _, portNumber := range portNumbers {
+		portID := topoapi.ID(fmt.Sprintf("%s/%d", switchID, portNumber))
+		port := &topoapi.Object{
+			ID:   portID,
+			Type: topoapi.Object_ENTITY,
+			Obj:  &topoapi.Object_Entity{Entity: &topoapi.Entity{}},
+		}
+		assert.NoError(tt, port.SetAspect(&topoapi.PhyPort{PortNumber: portNumber}))
+		assert.NoError(tt, t.Create(context.Background(), port))
+		t.addRelation(topoapi.ID(fmt.Sprintf("%s-contains", portID)), topoapi.CONTAINS, switchID, portID)
+	}
+}
+
+func newTestPacketIn(frame *packet.Ethernet, ingressPort uint32) *p4rt.PacketIn {
+	return &p4rt.PacketIn{
+		Payload:  frame.Serialize(),
+		Metadata: map[string][]byte{ingressPortMetadata: p4rt.EncodeUint(uint64(ingressPort))},
+	}
+}
+
+func newTestARP(mac net.HardwareAddr, ip net.IP) *packet.Ethernet {
+	arp := &packet.ARP{
+		Operation: packet.ARPRequest,
+		SenderMAC: mac,
+		SenderIP:  ip.To4(),
+		TargetMAC: net.HardwareAddr{0, 0, 0, 0, 0, 0},
+		TargetIP:  net.IPv4(10, 0, 0, 254).To4(),
+	}
+	return &packet.Ethernet{Dst: packet.BroadcastMAC, Src: mac, EtherType: packet.EtherTypeARP, Payload: arp.Serialize()}
+}
+
+func newTestNDP(mac net.HardwareAddr, ip net.IP) *packet.Ethernet {
+	ndp := &packet.NDP{
+		SrcIP:         ip,
+		DstIP:         net.ParseIP("ff02::1:ff00:fe"),
+		Type:          packet.ICMPv6NeighborSolicitation,
+		TargetIP:      net.ParseIP("2001:db8::fe"),
+		LinkLayerAddr: mac,
+	}
+	return &packet.Ethernet{Dst: net.HardwareAddr{0x33, 0x33, 0xff, 0, 0, 0xfe}, Src: mac, EtherType: packet.EtherTypeIPv6, Payload: ndp.Serialize()}
+}
+
+func TestHostTracker(t *testing.T) {
+	topo := newTestTopo()
+	topo.addSwitch(t, "switch-1", fabric.LeafRole, 1, 2, 3)
+	topo.addSwitch(t, "switch-2", fabric.LeafRole, 1)
+	topo.addRelation("switch-1/3-switch-2/1", topoapi.LinkKind, "switch-1/3", "switch-2/1")
+	s := NewService(topo, p4rt.NewConnManager(), time.Hour)
+	ctx := context.Background()
+	s.refreshTopology(ctx)
+
+	mac := net.HardwareAddr{0x02, 0, 0, 0, 0, 1}
+	hostID := NewHostID(mac, 0)
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac, net.IPv4(10, 0, 0, 1)), 1))
+	object, err := topo.Get(ctx, hostID)
+	assert.NoError(t, err)
+	assert.Equal(t, topoapi.ID(HostKind), object.GetEntity().KindID)
+	host, err := GetHost(object)
+	assert.NoError(t, err)
+	assert.Equal(t, &Host{MAC: mac.String(), IPs: []string{"10.0.0.1"}, Location: "switch-1/1"}, host)
+	_, err = topo.Get(ctx, newAttachmentID(hostID, "switch-1/1"))
+	assert.NoError(t, err)
+
+	// IPv6 addresses are learned from NDP; link-local addresses are ignored
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestNDP(mac, net.ParseIP("fe80::1")), 1))
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestNDP(mac, net.ParseIP("2001:db8::1")), 1))
+	host, err = GetHost(topo.object(hostID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "2001:db8::1"}, host.IPs)
+
+	// Hosts moving to another edge port keep their addresses
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac, net.IPv4(10, 0, 0, 1)), 2))
+	host, err = GetHost(topo.object(hostID))
+	assert.NoError(t, err)
+	assert.Equal(t, topoapi.ID("switch-1/2"), host.Location)
+	assert.Equal(t, []string{"10.0.0.1", "2001:db8::1"}, host.IPs)
+	_, err = topo.Get(ctx, newAttachmentID(hostID, "switch-1/1"))
+	assert.True(t, errors.IsNotFound(err))
+	_, err = topo.Get(ctx, newAttachmentID(hostID, "switch-1/2"))
+	assert.NoError(t, err)
+
+	// Packets received on link ports or unknown ports are ignored
+	otherMAC := net.HardwareAddr{0x02, 0, 0, 0, 0, 2}
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(otherMAC, net.IPv4(10, 0, 0, 2)), 3))
+	s.processPacketIn(ctx, "switch-2", newTestPacketIn(newTestARP(otherMAC, net.IPv4(10, 0, 0, 2)), 1))
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(otherMAC, net.IPv4(10, 0, 0, 2)), 4))
+	_, err = topo.Get(ctx, NewHostID(otherMAC, 0))
+	assert.True(t, errors.IsNotFound(err))
+
+	// Hosts learned before a restart are merged with the learned addresses
+	s = NewService(topo, p4rt.NewConnManager(), time.Hour)
+	s.refreshTopology(ctx)
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac, net.IPv4(10, 0, 0, 3)), 2))
+	host, err = GetHost(topo.object(hostID))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "2001:db8::1", "10.0.0.3"}, host.IPs)
+}
+
+func TestHostTrackerSpine(t *testing.T) {
+	topo := newTestTopo()
+	topo.addSwitch(t, "spine-1", "spine", 1)
+	topo.addSwitch(t, "switch-1", "", 1)
+	s := NewService(topo, p4rt.NewConnManager(), time.Hour)
+	ctx := context.Background()
+	s.refreshTopology(ctx)
+
+	// Hosts are only learned on leaf switches, even on ports without links
+	mac := net.HardwareAddr{0x02, 0, 0, 0, 0, 1}
+	s.processPacketIn(ctx, "spine-1", newTestPacketIn(newTestARP(mac, net.IPv4(10, 0, 0, 1)), 1))
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac, net.IPv4(10, 0, 0, 1)), 1))
+	_, err := topo.Get(ctx, NewHostID(mac, 0))
+	assert.True(t, errors.IsNotFound(err))
+}
+
+func TestHostTrackerIPMove(t *testing.T) {
+	topo := newTestTopo()
+	topo.addSwitch(t, "switch-1", fabric.LeafRole, 1, 2)
+	s := NewService(topo, p4rt.NewConnManager(), time.Hour)
+	ctx := context.Background()
+	s.refreshTopology(ctx)
+
+	mac1 := net.HardwareAddr{0x02, 0, 0, 0, 0, 1}
+	mac2 := net.HardwareAddr{0x02, 0, 0, 0, 0, 2}
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac1, net.IPv4(10, 0, 0, 1)), 1))
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestNDP(mac1, net.ParseIP("2001:db8::1")), 1))
+
+	// An address taken over by another host is removed from its previous owner
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac2, net.IPv4(10, 0, 0, 1)), 2))
+	host1, err := GetHost(topo.object(NewHostID(mac1, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::1"}, host1.IPs)
+	host2, err := GetHost(topo.object(NewHostID(mac2, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1"}, host2.IPs)
+
+	// and learned again by the previous owner when it claims it back
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac1, net.IPv4(10, 0, 0, 1)), 1))
+	host1, err = GetHost(topo.object(NewHostID(mac1, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2001:db8::1", "10.0.0.1"}, host1.IPs)
+	host2, err = GetHost(topo.object(NewHostID(mac2, 0)))
+	assert.NoError(t, err)
+	assert.Empty(t, host2.IPs)
+}
+
+func TestHostTrackerAging(t *testing.T) {
+	topo := newTestTopo()
+	topo.addSwitch(t, "switch-1", fabric.LeafRole, 1, 2)
+	s := NewService(topo, p4rt.NewConnManager(), time.Hour)
+	ctx := context.Background()
+	topology := s.refreshTopology(ctx)
+
+	mac1 := net.HardwareAddr{0x02, 0, 0, 0, 0, 1}
+	mac2 := net.HardwareAddr{0x02, 0, 0, 0, 0, 2}
+	hostID1 := NewHostID(mac1, 0)
+	hostID2 := NewHostID(mac2, 0)
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac1, net.IPv4(10, 0, 0, 1)), 1))
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(newTestARP(mac2, net.IPv4(10, 0, 0, 2)), 2))
+
+	// Hosts which were not seen for the host timeout are removed with their attachment
+	s.mu.Lock()
+	s.seen[hostID1] = time.Now().Add(-2 * time.Hour)
+	s.mu.Unlock()
+	s.expireHosts(ctx, topology)
+	_, err := topo.Get(ctx, hostID1)
+	assert.True(t, errors.IsNotFound(err))
+	_, err = topo.Get(ctx, newAttachmentID(hostID1, "switch-1/1"))
+	assert.True(t, errors.IsNotFound(err))
+	_, err = topo.Get(ctx, hostID2)
+	assert.NoError(t, err)
+
+	// Hosts learned before a restart are given the host timeout to be seen again
+	s = NewService(topo, p4rt.NewConnManager(), time.Hour)
+	topology = s.refreshTopology(ctx)
+	s.expireHosts(ctx, topology)
+	_, err = topo.Get(ctx, hostID2)
+	assert.NoError(t, err)
+	s.mu.Lock()
+	s.seen[hostID2] = time.Now().Add(-2 * time.Hour)
+	s.mu.Unlock()
+	s.expireHosts(ctx, topology)
+	_, err = topo.Get(ctx, hostID2)
+	assert.True(t, errors.IsNotFound(err))
+}
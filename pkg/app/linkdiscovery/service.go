@@ -0,0 +1,343 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package linkdiscovery
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"github.com/onosproject/wcmp-app/pkg/app/fabric"
+	"github.com/onosproject/wcmp-app/pkg/packet"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	"github.com/onosproject/wcmp-app/pkg/store/topo"
+)
+
+var log = logging.GetLogger()
+
+const (
+	// DefaultProbeInterval is the default interval between two rounds of discovery probes
+	DefaultProbeInterval = 5 * time.Second
+	// linkTimeoutProbes is the number of probe rounds without receiving a probe after which a link expires
+	linkTimeoutProbes = 3
+	defaultTimeout    = 30 * time.Second
+	queueSize         = 100
+
+	// ingressPortMetadata is the packet-in metadata holding the port a packet is received on
+	ingressPortMetadata = "ingress_port"
+	// egressPortMetadata is the packet-out metadata holding the port a packet is sent to
+	egressPortMetadata = "egress_port"
+	// probeSecretSize is the size in bytes of the secret the discovery probes are signed with
+	probeSecretSize = 32
+)
+
+// probeMAC is the source MAC address of the discovery probes
+var probeMAC = net.HardwareAddr{0x02, 0x77, 0x63, 0x6d, 0x70, 0x00}
+
+// Service discovers the links between the switches mastered by this controller by sending LLDP and BDDP
+// probes on their ports and maintains the link relations of the discovered links in topo. Probes are
+// signed with a secret shared by the controllers so that hosts cannot forge links by sending probes.
+type Service struct {
+	topo          topo.Store
+	conns         p4rt.ConnManager
+	probeInterval time.Duration
+	secret        []byte
+	topology      *fabric.Topology
+	// links are the links discovered by this controller with the time they were last seen
+	links  map[topoapi.ID]time.Time
+	mu     sync.Mutex
+	cancel context.CancelFunc
+}
+
+// NewService returns a new link discovery service signing its probes with the given secret. The secret must
+// be shared by all the controllers for the links between switches mastered by different controllers to be
+// discovered; without a secret, a random one only known to this controller is used.
+func NewService(topo topo.Store, conns p4rt.ConnManager, probeInterval time.Duration, secret []byte) *Service {
+	if probeInterval <= 0 {
+		probeInterval = DefaultProbeInterval
+	}
+	if len(secret) == 0 {
+		log.Warnw("No secret is shared for discovery probes; Links between switches mastered by other controllers will not be discovered")
+		secret = make([]byte, probeSecretSize)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatalw("Unable to generate the secret of discovery probes", "error", err)
+		}
+	}
+	return &Service{
+		topo:          topo,
+		conns:         conns,
+		probeInterval: probeInterval,
+		secret:        secret,
+		links:         make(map[topoapi.ID]time.Time),
+	}
+}
+
+// Start starts sending discovery probes and processing the probes received by the switches
+func (s *Service) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		return nil
+	}
+
+	connCh := make(chan p4rt.Conn, queueSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := s.conns.Watch(ctx, connCh); err != nil {
+		cancel()
+		return err
+	}
+	s.cancel = cancel
+	go s.watchConns(ctx, connCh)
+	go s.run(ctx)
+	return nil
+}
+
+// Stop stops the link discovery
+func (s *Service) Stop() {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.mu.Unlock()
+}
+
+// watchConns subscribes to the packet-ins of each connection while it is open
+func (s *Service) watchConns(ctx context.Context, connCh <-chan p4rt.Conn) {
+	subscriptions := make(map[p4rt.ConnID]context.CancelFunc)
+	for conn := range connCh {
+		if _, ok := s.conns.Get(ctx, conn.ID()); !ok {
+			if cancelSubscription, ok := subscriptions[conn.ID()]; ok {
+				cancelSubscription()
+				delete(subscriptions, conn.ID())
+			}
+			continue
+		}
+		if _, ok := subscriptions[conn.ID()]; ok {
+			continue
+		}
+		subscriptionCtx, cancelSubscription := context.WithCancel(ctx)
+		packetCh := make(chan *p4rt.PacketIn, queueSize)
+		if err := conn.SubscribePacketIn(subscriptionCtx, packetCh); err != nil {
+			log.Warnw("Failed subscribing to packet-ins", "targetID", conn.TargetID(), "error", err)
+			cancelSubscription()
+			continue
+		}
+		subscriptions[conn.ID()] = cancelSubscription
+		go func(targetID topoapi.ID) {
+			for packetIn := range packetCh {
+				s.processPacketIn(subscriptionCtx, targetID, packetIn)
+			}
+		}(conn.TargetID())
+	}
+}
+
+func (s *Service) run(ctx context.Context) {
+	ticker := time.NewTicker(s.probeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.probe(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// probe refreshes the topology snapshot, expires the links which were not seen for a while and sends
+// discovery probes on the ports of the mastered switches
+func (s *Service) probe(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	t, err := fabric.LoadTopology(ctx, s.topo)
+	if err != nil {
+		log.Warnw("Failed loading topology for link discovery", "error", err)
+		return
+	}
+	s.mu.Lock()
+	s.topology = t
+	s.mu.Unlock()
+
+	s.expireLinks(ctx, t)
+
+	for _, switchID := range t.GetMasteredSwitches() {
+		conn, err := s.conns.GetByTarget(ctx, switchID)
+		if err != nil {
+			log.Debugw("Unable to send discovery probes", "targetID", switchID, "error", err)
+			continue
+		}
+		for portNumber := range t.GetPorts(switchID) {
+			for _, probe := range s.newProbes(switchID, portNumber) {
+				err := conn.SendPacketOut(ctx, &p4rt.PacketOut{
+					Payload: probe,
+					Metadata: map[string][]byte{
+						egressPortMetadata: p4rt.EncodeUint(uint64(portNumber)),
+					},
+				})
+				if err != nil {
+					log.Debugw("Failed sending discovery probe", "targetID", switchID, "port", portNumber, "error", err)
+				}
+			}
+		}
+	}
+}
+
+// newProbes returns the LLDP and BDDP frames advertising a switch port
+func (s *Service) newProbes(switchID topoapi.ID, portNumber uint32) [][]byte {
+	lldp := &packet.LLDP{
+		ChassisID: string(switchID),
+		PortID:    strconv.FormatUint(uint64(portNumber), 10),
+		TTL:       uint16((linkTimeoutProbes * s.probeInterval).Seconds()),
+	}
+	lldp.Signature = s.sign(lldp)
+	payload := lldp.Serialize()
+	return [][]byte{
+		(&packet.Ethernet{Dst: packet.LLDPMulticastMAC, Src: probeMAC, EtherType: packet.EtherTypeLLDP, Payload: payload}).Serialize(),
+		(&packet.Ethernet{Dst: packet.BroadcastMAC, Src: probeMAC, EtherType: packet.EtherTypeBDDP, Payload: payload}).Serialize(),
+	}
+}
+
+// sign returns the signature of the contents of a discovery probe
+func (s *Service) sign(lldp *packet.LLDP) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	_, _ = fmt.Fprintf(mac, "%s\x00%s\x00%d", lldp.ChassisID, lldp.PortID, lldp.TTL)
+	return mac.Sum(nil)
+}
+
+// expireLinks deletes the links to the mastered switches that were not seen for several probe rounds
+func (s *Service) expireLinks(ctx context.Context, t *fabric.Topology) {
+	now := time.Now()
+	timeout := linkTimeoutProbes * s.probeInterval
+	var expired []topoapi.ID
+	s.mu.Lock()
+	links := t.GetLinks()
+	for linkID, relation := range links {
+		if switchID, ok := t.GetSwitch(relation.TgtEntityID); !ok || !t.IsMastered(switchID) {
+			continue
+		}
+		// Links discovered before a restart or a mastership change are given a chance to be seen again
+		lastSeen, ok := s.links[linkID]
+		if !ok {
+			s.links[linkID] = now
+			continue
+		}
+		if now.Sub(lastSeen) > timeout {
+			expired = append(expired, linkID)
+			delete(s.links, linkID)
+		}
+	}
+	// Forget the links removed from topo by someone else
+	for linkID := range s.links {
+		if _, ok := links[linkID]; !ok {
+			delete(s.links, linkID)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, linkID := range expired {
+		log.Infow("Link expired", "linkID", linkID)
+		object, err := s.topo.Get(ctx, linkID)
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				log.Warnw("Failed deleting expired link", "linkID", linkID, "error", err)
+			}
+			continue
+		}
+		if err := s.topo.Delete(ctx, object); err != nil && !errors.IsNotFound(err) {
+			log.Warnw("Failed deleting expired link", "linkID", linkID, "error", err)
+		}
+	}
+}
+
+// processPacketIn creates or refreshes the link a discovery probe was received on
+func (s *Service) processPacketIn(ctx context.Context, targetID topoapi.ID, packetIn *p4rt.PacketIn) {
+	frame, err := packet.ParseEthernet(packetIn.Payload)
+	if err != nil || (frame.EtherType != packet.EtherTypeLLDP && frame.EtherType != packet.EtherTypeBDDP) {
+		return
+	}
+	lldp, err := packet.ParseLLDP(frame.Payload)
+	if err != nil {
+		log.Debugw("Ignoring invalid discovery probe", "targetID", targetID, "error", err)
+		return
+	}
+	if !hmac.Equal(lldp.Signature, s.sign(lldp)) {
+		log.Debugw("Ignoring discovery probe not signed with the shared secret", "targetID", targetID, "chassis ID", lldp.ChassisID)
+		return
+	}
+	srcPortNumber, err := strconv.ParseUint(lldp.PortID, 10, 32)
+	if err != nil {
+		log.Debugw("Ignoring discovery probe of unknown port", "targetID", targetID, "port ID", lldp.PortID)
+		return
+	}
+	tgtPortValue, ok := packetIn.Metadata[ingressPortMetadata]
+	if !ok {
+		log.Warnw("Ignoring discovery probe without ingress port", "targetID", targetID)
+		return
+	}
+
+	s.mu.Lock()
+	t := s.topology
+	s.mu.Unlock()
+	if t == nil {
+		return
+	}
+	srcPortID, ok := t.GetPort(topoapi.ID(lldp.ChassisID), uint32(srcPortNumber))
+	if !ok {
+		log.Debugw("Ignoring discovery probe of unknown port", "targetID", targetID, "chassis ID", lldp.ChassisID, "port ID", lldp.PortID)
+		return
+	}
+	tgtPortID, ok := t.GetPort(targetID, uint32(p4rt.DecodeUint(tgtPortValue)))
+	if !ok {
+		log.Debugw("Ignoring discovery probe received on unknown port", "targetID", targetID, "port", p4rt.DecodeUint(tgtPortValue))
+		return
+	}
+
+	linkID := newLinkID(srcPortID, tgtPortID)
+	s.mu.Lock()
+	_, known := s.links[linkID]
+	s.links[linkID] = time.Now()
+	s.mu.Unlock()
+	if known {
+		return
+	}
+	if _, ok := t.GetLinks()[linkID]; ok {
+		return
+	}
+
+	log.Infow("Discovered link", "linkID", linkID, "source port", srcPortID, "target port", tgtPortID)
+	err = s.topo.Create(ctx, &topoapi.Object{
+		ID:   linkID,
+		Type: topoapi.Object_RELATION,
+		Obj: &topoapi.Object_Relation{
+			Relation: &topoapi.Relation{
+				KindID:      topoapi.LinkKind,
+				SrcEntityID: srcPortID,
+				TgtEntityID: tgtPortID,
+			},
+		},
+	})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		log.Warnw("Failed creating link", "linkID", linkID, "error", err)
+		s.mu.Lock()
+		delete(s.links, linkID)
+		s.mu.Unlock()
+	}
+}
+
+// newLinkID returns the ID of the link relation from a source port entity to a target port entity
+func newLinkID(srcPortID, tgtPortID topoapi.ID) topoapi.ID {
+	return topoapi.ID(fmt.Sprintf("%s-%s", srcPortID, tgtPortID))
+}
@@ -0,0 +1,226 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package linkdiscovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/controller/utils"
+	"github.com/onosproject/wcmp-app/pkg/packet"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTopo is a topology store with helpers building the topology of the tests
+type testTopo struct {
+	*topotest.Store
+}
+
+func newTestTopo() *testTopo {
+	return &testTopo{Store: topotest.NewStore()}
+}
+
+// object returns the stored topology object with the given ID, or nil if there is none
+func (t *testTopo) object(id topoapi.ID) *topoapi.Object {
+	object, _ := t.Get(context.Background(), id)
+	return object
+}
+
+// len returns the number of stored topology objects
+func (t *testTopo) len() int {
+	objects, _ := t.List(context.Background(), nil)
+	return len(objects)
+}
+
+func (t *testTopo) addRelation(id topoapi.ID, kind topoapi.ID, src, tgt topoapi.ID) {
+	_ = t.Update(context.Background(), &topoapi.Object{
+		ID:   id,
+		Type: topoapi.Object_RELATION,
+		Obj: &topoapi.Object_Relation{
+			Relation: &topoapi.Relation{KindID: kind, SrcEntityID: src, TgtEntityID: tgt},
+		},
+	})
+}
+
+// addSwitch adds a switch mastered by this controller with the given ports
+func (t *testTopo) addSwitch(tt *testing.T, switchID topoapi.ID, portNumbers ...uint32) {
+	object := &topoapi.Object{
+		ID:   switchID,
+		Type: topoapi.Object_ENTITY,
+		Obj:  &topoapi.Object_Entity{Entity: &topoapi.Entity{}},
+	}
+	assert.NoError(tt, object.SetAspect(&topoapi.P4RTServerInfo{}))
+	controlsID := topoapi.ID(fmt.Sprintf("%s-controls", switchID))
+	t.addRelation(controlsID, topoapi.CONTROLS, utils.GetControllerID(), switchID)
+	assert.NoError(tt, object.SetAspect(&topoapi.P4RTMastershipState{NodeId: string(controlsID)}))
+	assert.NoError(tt, t.Create(context.Background(), object))
+
+	for _, portNumber := range portNumbers {
+		portID := topoapi.ID(fmt.Sprintf("%s/%d", switchID, portNumber))
+		port := &topoapi.Object{
+			ID:   portID,
+			Type: topoapi.Object_ENTITY,
+			Obj:  &topoapi.Object_Entity{Entity: &topoapi.Entity{}},
+		}
+		assert.NoError(tt, port.SetAspect(&topoapi.PhyPort{PortNumber: portNumber}))
+		assert.NoError(tt, t.Create(context.Background(), port))
+		t.addRelation(topoapi.ID(fmt.Sprintf("%s-contains", portID)), topoapi.CONTAINS, switchID, portID)
+	}
+}
+
+func newTestPacketIn(s *Service, switchID topoapi.ID, portNumber uint32, ingressPort uint32, etherType uint16) *p4rt.PacketIn {
+	probes := s.newProbes(switchID, portNumber)
+	payload := probes[0]
+	if etherType == packet.EtherTypeBDDP {
+		payload = probes[1]
+	}
+	return &p4rt.PacketIn{
+		Payload:  payload,
+		Metadata: map[string][]byte{ingressPortMetadata: p4rt.EncodeUint(uint64(ingressPort))},
+	}
+}
+
+func TestLinkDiscovery(t *testing.T) {
+	topo := newTestTopo()
+	topo.addSwitch(t, "switch-1", 1, 2)
+	topo.addSwitch(t, "switch-2", 1, 2)
+	s := NewService(topo, p4rt.NewConnManager(), time.Hour, nil)
+	ctx := context.Background()
+
+	// Probes are ignored until the topology is loaded
+	s.processPacketIn(ctx, "switch-2", newTestPacketIn(s, "switch-1", 1, 2, packet.EtherTypeLLDP))
+	assert.Equal(t, 12, topo.len())
+
+	s.probe(ctx)
+	s.processPacketIn(ctx, "switch-2", newTestPacketIn(s, "switch-1", 1, 2, packet.EtherTypeLLDP))
+	s.processPacketIn(ctx, "switch-1", newTestPacketIn(s, "switch-2", 2, 1, packet.EtherTypeBDDP))
+	link, err := topo.Get(ctx, "switch-1/1-switch-2/2")
+	assert.NoError(t, err)
+	assert.Equal(t, topoapi.ID(topoapi.LinkKind), link.GetRelation().KindID)
+	assert.Equal(t, topoapi.ID("switch-1/1"), link.GetRelation().SrcEntityID)
+	assert.Equal(t, topoapi.ID("switch-2/2"), link.GetRelation().TgtEntityID)
+	_, err = topo.Get(ctx, "switch-2/2-switch-1/1")
+	assert.NoError(t, err)
+
+	// Probes from unknown ports and other packets are ignored
+	s.processPacketIn(ctx, "switch-2", newTestPacketIn(s, "switch-1", 3, 2, packet.EtherTypeLLDP))
+	s.processPacketIn(ctx, "switch-2", newTestPacketIn(s, "switch-3", 1, 2, packet.EtherTypeLLDP))
+	s.processPacketIn(ctx, "switch-2", &p4rt.PacketIn{Payload: []byte{0x01, 0x02}})
+	assert.Equal(t, 14, topo.len())
+
+	// Unsigned probes and probes signed by another controller are ignored
+	unsigned := &packet.LLDP{ChassisID: "switch-1", PortID: "2", TTL: 1}
+	other := NewService(topo, p4rt.NewConnManager(), time.Hour, nil)
+	for _, payload := range [][]byte{
+		(&packet.Ethernet{Dst: packet.LLDPMulticastMAC, Src: probeMAC, EtherType: packet.EtherTypeLLDP, Payload: unsigned.Serialize()}).Serialize(),
+		other.newProbes("switch-1", 2)[0],
+	} {
+		s.processPacketIn(ctx, "switch-2", &p4rt.PacketIn{
+			Payload:  payload,
+			Metadata: map[string][]byte{ingressPortMetadata: p4rt.EncodeUint(1)},
+		})
+	}
+	assert.Equal(t, 14, topo.len())
+
+	// Links which are still seen are kept
+	s.probe(ctx)
+	_, err = topo.Get(ctx, "switch-1/1-switch-2/2")
+	assert.NoError(t, err)
+
+	// Links which are no longer seen expire
+	s.mu.Lock()
+	s.links["switch-1/1-switch-2/2"] = time.Now().Add(-linkTimeoutProbes * time.Hour * 2)
+	s.mu.Unlock()
+	s.probe(ctx)
+	_, err = topo.Get(ctx, "switch-1/1-switch-2/2")
+	assert.True(t, errors.IsNotFound(err))
+	_, err = topo.Get(ctx, "switch-2/2-switch-1/1")
+	assert.NoError(t, err)
+}
+
+func TestExpireLinks(t *testing.T) {
+	topo := newTestTopo()
+	topo.addSwitch(t, "switch-1", 1)
+	topo.addSwitch(t, "switch-2", 1)
+	// switch-3 is mastered by another controller
+	topo.addSwitch(t, "switch-3", 1)
+	assert.NoError(t, topo.object("switch-3").SetAspect(&topoapi.P4RTMastershipState{NodeId: "other"}))
+	topo.addRelation("switch-1/1-switch-2/1", topoapi.LinkKind, "switch-1/1", "switch-2/1")
+	topo.addRelation("switch-2/1-switch-1/1", topoapi.LinkKind, "switch-2/1", "switch-1/1")
+	topo.addRelation("switch-1/1-switch-3/1", topoapi.LinkKind, "switch-1/1", "switch-3/1")
+	s := NewService(topo, p4rt.NewConnManager(), time.Hour, nil)
+	ctx := context.Background()
+	timeout := linkTimeoutProbes * s.probeInterval
+
+	// Links found in topo are given a chance to be seen, except the links to switches mastered by others
+	s.probe(ctx)
+	s.mu.Lock()
+	assert.Len(t, s.links, 2)
+	assert.NotContains(t, s.links, topoapi.ID("switch-1/1-switch-3/1"))
+	s.links["switch-1/1-switch-2/1"] = time.Now().Add(-timeout - time.Minute)
+	s.links["switch-2/1-switch-1/1"] = time.Now().Add(-timeout + time.Minute)
+	s.mu.Unlock()
+
+	// Links not seen for the timeout are deleted and the others are kept
+	s.probe(ctx)
+	_, err := topo.Get(ctx, "switch-1/1-switch-2/1")
+	assert.True(t, errors.IsNotFound(err))
+	_, err = topo.Get(ctx, "switch-2/1-switch-1/1")
+	assert.NoError(t, err)
+	_, err = topo.Get(ctx, "switch-1/1-switch-3/1")
+	assert.NoError(t, err)
+	s.mu.Lock()
+	assert.Len(t, s.links, 1)
+	s.mu.Unlock()
+
+	// Links removed from topo by someone else are forgotten
+	assert.NoError(t, topo.Delete(ctx, topo.object("switch-2/1-switch-1/1")))
+	s.probe(ctx)
+	s.mu.Lock()
+	assert.Empty(t, s.links)
+	s.mu.Unlock()
+}
+
+func TestSharedSecret(t *testing.T) {
+	// Each controller masters one of the switches
+	topo1 := newTestTopo()
+	topo1.addSwitch(t, "switch-1", 1)
+	topo1.addSwitch(t, "switch-2", 1)
+	assert.NoError(t, topo1.object("switch-2").SetAspect(&topoapi.P4RTMastershipState{NodeId: "other"}))
+	topo2 := newTestTopo()
+	topo2.addSwitch(t, "switch-1", 1)
+	topo2.addSwitch(t, "switch-2", 1)
+	assert.NoError(t, topo2.object("switch-1").SetAspect(&topoapi.P4RTMastershipState{NodeId: "other"}))
+	secret := []byte("shared secret")
+	s1 := NewService(topo1, p4rt.NewConnManager(), time.Hour, secret)
+	s2 := NewService(topo2, p4rt.NewConnManager(), time.Hour, secret)
+	ctx := context.Background()
+	s1.probe(ctx)
+	s2.probe(ctx)
+
+	// The probes sent by one controller are accepted by the other one
+	s2.processPacketIn(ctx, "switch-2", newTestPacketIn(s1, "switch-1", 1, 1, packet.EtherTypeLLDP))
+	s1.processPacketIn(ctx, "switch-1", newTestPacketIn(s2, "switch-2", 1, 1, packet.EtherTypeLLDP))
+	_, err := topo2.Get(ctx, "switch-1/1-switch-2/1")
+	assert.NoError(t, err)
+	_, err = topo1.Get(ctx, "switch-2/1-switch-1/1")
+	assert.NoError(t, err)
+
+	// Controllers with another secret cannot forge links
+	other := NewService(topo2, p4rt.NewConnManager(), time.Hour, []byte("other secret"))
+	assert.NoError(t, topo2.Delete(ctx, topo2.object("switch-1/1-switch-2/1")))
+	s2.mu.Lock()
+	delete(s2.links, "switch-1/1-switch-2/1")
+	s2.mu.Unlock()
+	s2.processPacketIn(ctx, "switch-2", newTestPacketIn(other, "switch-1", 1, 1, packet.EtherTypeLLDP))
+	_, err = topo2.Get(ctx, "switch-1/1-switch-2/1")
+	assert.True(t, errors.IsNotFound(err))
+}
@@ -35,6 +35,12 @@ func NewController(topo topo.Store, pipelineConfigs pipelineconfig.Store, p4Plug
 		pipelineConfigs: pipelineConfigs,
 	})
 
+	c.Watch(&PluginWatcher{
+		topo:             topo,
+		pipelineConfigs:  pipelineConfigs,
+		p4PluginRegistry: p4PluginRegistry,
+	})
+
 	c.Reconcile(&Reconciler{
 		topo:             topo,
 		pipelineConfigs:  pipelineConfigs,
@@ -74,7 +80,25 @@ func (r *Reconciler) Reconcile(id controller.ID) (controller.Result, error) {
 		log.Warnw("Failed creating device pipeline config for target", "targetID", targetID, "error", err)
 		return controller.Result{}, err
 	}
-	pipelineInfo := p4rtServerInfo.Pipelines[0]
+	pipelineInfo, err := r.selectPipeline(target, p4rtServerInfo)
+	if err != nil {
+		if errors.IsInvalid(err) {
+			// The target is reconciled again once its labels or the registered P4 plugins change
+			log.Warnw("No pipeline can be assigned to target", "targetID", targetID, "error", err)
+			return controller.Result{}, r.setPipelineStatus(ctx, target, err.Error())
+		}
+		if !errors.IsNotFound(err) {
+			log.Errorw("Failed selecting pipeline for target", "targetID", targetID, "error", err)
+			return controller.Result{}, err
+		}
+		// The target is reconciled again once a P4 plugin for one of its pipelines is registered
+		log.Warnw("Waiting for a P4 plugin for the pipelines of target", "targetID", targetID, "error", err)
+		return controller.Result{}, r.setPipelineStatus(ctx, target, err.Error())
+	}
+	if err := r.setPipelineStatus(ctx, target, ""); err != nil {
+		log.Errorw("Failed clearing pipeline status of target", "targetID", targetID, "error", err)
+		return controller.Result{}, err
+	}
 	pipelineName := pipelineInfo.Name
 	pipelineVersion := pipelineInfo.Version
 	pipelineArch := pipelineInfo.Architecture
@@ -82,9 +106,25 @@ func (r *Reconciler) Reconcile(id controller.ID) (controller.Result, error) {
 	pluginID := p4rtapi.NewP4PluginID(pipelineName, pipelineVersion, pipelineArch)
 	p4Plugin, err := r.p4PluginRegistry.GetPlugin(pluginID)
 	if err != nil {
-		log.Errorw("Failed creating device pipeline config for target", "pipelineConfigID", pipelineID, "targetID", targetID, "error", err)
+		if !errors.IsNotFound(err) {
+			log.Errorw("Failed creating device pipeline config for target", "pipelineConfigID", pipelineID, "targetID", targetID, "error", err)
+			return controller.Result{}, err
+		}
+		log.Warnw("P4 plugin was unregistered while reconciling target", "pipelineConfigID", pipelineID, "targetID", targetID, "error", err)
+		return controller.Result{}, r.setPipelineStatus(ctx, target, err.Error())
+	}
+
+	// Switch pipelines only once the pipeline configs previously selected for the target are no longer being pushed
+	pending, err := r.hasPendingPipelineConfigs(ctx, p4rtapi.TargetID(targetID), pipelineID)
+	if err != nil {
+		log.Errorw("Failed listing device pipeline configs for target", "pipelineConfigID", pipelineID, "targetID", targetID, "error", err)
 		return controller.Result{}, err
 	}
+	if pending {
+		// The target is reconciled again once the status of the pending pipeline config changes
+		log.Infow("Waiting for device pipeline config to be pushed before switching pipelines", "pipelineConfigID", pipelineID, "targetID", targetID)
+		return controller.Result{}, nil
+	}
 
 	deviceConfig, err := p4Plugin.GetP4DeviceConfig()
 	if err != nil {
@@ -107,28 +147,146 @@ func (r *Reconciler) Reconcile(id controller.ID) (controller.Result, error) {
 		}
 		return controller.Result{}, nil
 	}
-	p4InfoBytes, err := proto.Marshal(p4Info)
+	p4InfoBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(p4Info)
 	if err != nil {
 		log.Errorw("Failed creating device pipeline config for target", "pipelineConfigID", pipelineID, "targetID", targetID, "error", err)
 		return controller.Result{}, err
 	}
 
+	spec := &p4rtapi.PipelineConfigSpec{
+		P4DeviceConfig: deviceConfig,
+		P4Info:         p4InfoBytes,
+	}
 	err = r.pipelineConfigs.Create(ctx, &p4rtapi.PipelineConfig{
 		ID:       pipelineID,
 		TargetID: p4rtapi.TargetID(targetID),
 		Action:   p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT,
-		Spec: &p4rtapi.PipelineConfigSpec{
-			P4DeviceConfig: deviceConfig,
-			P4Info:         p4InfoBytes,
-		},
-	})
+		Cookie:   pipelineconfig.NewPipelineConfigCookie(spec),
+		Spec:     spec,
+	}, pipelineconfig.WithPluginID(pluginID))
 	if err != nil {
 		if !errors.IsAlreadyExists(err) {
 			log.Errorw("Failed Reconciling creating pipeline config for target", "targetID", targetID, "error", err)
 			return controller.Result{}, err
 		}
-		return controller.Result{}, nil
+		return r.activatePipelineConfig(ctx, pipelineID, pluginID, spec)
 	}
 	log.Infow("Device Pipeline config is created successfully in pipeline config data store", "pipelineConfigID", pipelineID, "target ID", targetID)
 	return controller.Result{}, nil
 }
+
+// hasPendingPipelineConfigs returns whether one of the active pipeline configs of a target other than the
+// selected one is still pending, in which case the pipeline cannot be switched yet
+func (r *Reconciler) hasPendingPipelineConfigs(ctx context.Context, targetID p4rtapi.TargetID, selectedID p4rtapi.PipelineConfigID) (bool, error) {
+	pipelineConfigs, err := r.pipelineConfigs.List(ctx, pipelineconfig.WithTargetID(targetID))
+	if err != nil {
+		return false, err
+	}
+	for _, pipelineConfig := range pipelineConfigs {
+		if pipelineConfig.ID == selectedID || pipelineConfig.Action == p4rtapi.ConfigurationAction_UNSPECIFIED {
+			continue
+		}
+		if pipelineConfig.Status.State == p4rtapi.PipelineConfigStatus_PENDING {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// deactivatePipelineConfigs deactivates the pipeline configs of a target other than the selected one. The
+// previous pipeline configs stay active until the selected one runs on the target, so that a failed switch
+// leaves the target with a pipeline config describing what it still runs.
+func (r *Reconciler) deactivatePipelineConfigs(ctx context.Context, targetID p4rtapi.TargetID, selected *p4rtapi.PipelineConfig) error {
+	if selected.Status.State != p4rtapi.PipelineConfigStatus_COMPLETE {
+		// The target is reconciled again once the status of the selected pipeline config changes
+		log.Debugw("Waiting for device pipeline config to complete before deactivating the previous ones", "pipelineConfigID", selected.ID, "targetID", targetID)
+		return nil
+	}
+	pipelineConfigs, err := r.pipelineConfigs.List(ctx, pipelineconfig.WithTargetID(targetID))
+	if err != nil {
+		return err
+	}
+	for _, pipelineConfig := range pipelineConfigs {
+		if pipelineConfig.ID == selected.ID || pipelineConfig.Action == p4rtapi.ConfigurationAction_UNSPECIFIED {
+			continue
+		}
+		log.Infow("Deactivating device pipeline config", "pipelineConfigID", pipelineConfig.ID, "targetID", targetID)
+		pipelineConfig.Action = p4rtapi.ConfigurationAction_UNSPECIFIED
+		if err := r.pipelineConfigs.Update(ctx, pipelineConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activatePipelineConfig reactivates a previously deactivated pipeline config, pushes the given spec again if
+// the P4 plugin was updated since the pipeline config was last synchronized with it, or deactivates the other
+// pipeline configs of the target once the selected one is running. A pipeline config whose spec was changed
+// while its P4 plugin was not, e.g. rolled back to a previous revision, is left as is.
+func (r *Reconciler) activatePipelineConfig(ctx context.Context, pipelineID p4rtapi.PipelineConfigID, pluginID p4rtapi.P4PluginID, spec *p4rtapi.PipelineConfigSpec) (controller.Result, error) {
+	pipelineConfig, err := r.pipelineConfigs.Get(ctx, pipelineID)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Errorw("Failed activating device pipeline config", "pipelineConfigID", pipelineID, "error", err)
+			return controller.Result{}, err
+		}
+		return controller.Result{}, nil
+	}
+	cookie := pipelineconfig.NewPipelineConfigCookie(spec)
+	pluginDetails, err := r.pipelineConfigs.GetPluginDetails(ctx, pipelineID)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Errorw("Failed activating device pipeline config", "pipelineConfigID", pipelineID, "error", err)
+			return controller.Result{}, err
+		}
+		// Without a recorded P4 plugin, the pipeline config is assumed to have been created from its current spec
+		pluginDetails = &pipelineconfig.PluginDetails{
+			PluginID: pluginID,
+			Cookie:   pipelineConfig.Cookie.GetCookie(),
+		}
+	}
+	updated := pluginDetails.PluginID != pluginID || pluginDetails.Cookie != cookie.Cookie
+	if pipelineConfig.Action != p4rtapi.ConfigurationAction_UNSPECIFIED && !updated {
+		if err := r.deactivatePipelineConfigs(ctx, pipelineConfig.TargetID, pipelineConfig); err != nil {
+			if !errors.IsConflict(err) {
+				log.Errorw("Failed deactivating device pipeline configs for target", "pipelineConfigID", pipelineID, "targetID", pipelineConfig.TargetID, "error", err)
+				return controller.Result{}, err
+			}
+			log.Warnw("Write conflict deactivating device pipeline configs", "pipelineConfigID", pipelineID, "error", err)
+		}
+		return controller.Result{}, nil
+	}
+	if updated {
+		log.Infow("P4 plugin was updated; Pushing device pipeline config again", "pipelineConfigID", pipelineID, "targetID", pipelineConfig.TargetID)
+		pipelineConfig.Spec = spec
+		pipelineConfig.Cookie = cookie
+		// COMMIT realizes the config saved on the target rather than the updated one
+		if pipelineConfig.Action == p4rtapi.ConfigurationAction_COMMIT {
+			pipelineConfig.Action = p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT
+		}
+	}
+	if pipelineConfig.Action == p4rtapi.ConfigurationAction_UNSPECIFIED {
+		log.Infow("Activating device pipeline config", "pipelineConfigID", pipelineID, "targetID", pipelineConfig.TargetID)
+		pipelineConfig.Action = p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT
+	}
+	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	if err := r.pipelineConfigs.Update(ctx, pipelineConfig); err != nil {
+		if !errors.IsConflict(err) {
+			log.Errorw("Failed activating device pipeline config", "pipelineConfigID", pipelineID, "error", err)
+			return controller.Result{}, err
+		}
+		log.Warnw("Write conflict activating device pipeline config", "pipelineConfigID", pipelineID, "error", err)
+		return controller.Result{}, nil
+	}
+	if updated {
+		pluginDetails = &pipelineconfig.PluginDetails{
+			PluginID: pluginID,
+			Cookie:   cookie.Cookie,
+		}
+		if err := r.pipelineConfigs.UpdatePluginDetails(ctx, pipelineID, pluginDetails); err != nil {
+			log.Errorw("Failed recording P4 plugin of device pipeline config", "pipelineConfigID", pipelineID, "error", err)
+			return controller.Result{}, err
+		}
+	}
+	return controller.Result{}, nil
+}
@@ -0,0 +1,412 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipeliner
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/controller"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineconfigctrl "github.com/onosproject/wcmp-app/pkg/controller/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPlugin struct {
+	pkgInfo *p4configapi.PkgInfo
+}
+
+func (p *testPlugin) GetPkgInfo() (*p4configapi.PkgInfo, error) {
+	return p.pkgInfo, nil
+}
+
+func (p *testPlugin) GetP4DeviceConfig() ([]byte, error) {
+	return []byte(p.pkgInfo.Version), nil
+}
+
+func (p *testPlugin) GetP4Info() (*p4configapi.P4Info, error) {
+	return &p4configapi.P4Info{PkgInfo: p.pkgInfo}, nil
+}
+
+type testRegistry struct {
+	plugins map[p4rtapi.P4PluginID]pluginregistry.P4Plugin
+}
+
+func (r *testRegistry) GetPlugins() map[p4rtapi.P4PluginID]pluginregistry.P4Plugin {
+	return r.plugins
+}
+
+func (r *testRegistry) GetPlugin(id p4rtapi.P4PluginID) (pluginregistry.P4Plugin, error) {
+	p4Plugin, ok := r.plugins[id]
+	if !ok {
+		return nil, errors.NewNotFound("P4 plugin with ID '%s' not found", id)
+	}
+	return p4Plugin, nil
+}
+
+func (r *testRegistry) RegisterPlugin(pluginName string) error {
+	return errors.NewNotSupported("plugins cannot be loaded in tests")
+}
+
+func (r *testRegistry) RegisterFilePlugin(dir string) error {
+	return errors.NewNotSupported("plugins cannot be loaded in tests")
+}
+
+func (r *testRegistry) UnregisterPlugin(id p4rtapi.P4PluginID) error {
+	delete(r.plugins, id)
+	return nil
+}
+
+func (r *testRegistry) WatchPluginDir(ctx context.Context, dir string) error {
+	return errors.NewNotSupported("plugins cannot be loaded in tests")
+}
+
+func (r *testRegistry) Watch(ctx context.Context, ch chan<- pluginregistry.PluginEvent) error {
+	return nil
+}
+
+func newTestTarget(id topoapi.ID, pipelines ...*topoapi.P4PipelineInfo) *topoapi.Object {
+	target := &topoapi.Object{
+		ID:   id,
+		Type: topoapi.Object_ENTITY,
+		Obj: &topoapi.Object_Entity{
+			Entity: &topoapi.Entity{
+				KindID: topoapi.SwitchKind,
+			},
+		},
+	}
+	_ = target.SetAspect(&topoapi.P4RTServerInfo{
+		Pipelines: pipelines,
+	})
+	return target
+}
+
+func TestReconcilePipelineSwitch(t *testing.T) {
+	v1 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "1.0.0", Architecture: "v1model"}
+	v2 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "2.0.0", Architecture: "v1model"}
+	target := newTestTarget("target-1", v1, v2)
+	topo := topotest.NewStore(target)
+	registry := &testRegistry{plugins: make(map[p4rtapi.P4PluginID]pluginregistry.P4Plugin)}
+	for _, pipelineInfo := range []*topoapi.P4PipelineInfo{v1, v2} {
+		registry.plugins[newPluginID(pipelineInfo)] = &testPlugin{
+			pkgInfo: &p4configapi.PkgInfo{Name: pipelineInfo.Name, Version: pipelineInfo.Version, Arch: pipelineInfo.Architecture},
+		}
+	}
+	pipelineConfigs := pipelineconfig.NewMemoryStore()
+	reconciler := &Reconciler{
+		topo:             topo,
+		pipelineConfigs:  pipelineConfigs,
+		p4PluginRegistry: registry,
+	}
+	ctx := context.Background()
+
+	// The first advertised pipeline is selected by default
+	_, err := reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1ID := pipelineconfig.NewPipelineConfigID("target-1", v1.Name, v1.Version, v1.Architecture)
+	v1Config, err := pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v1Config.Action)
+	assert.Equal(t, []byte("1.0.0"), v1Config.Spec.P4DeviceConfig)
+	assert.Equal(t, pipelineconfig.NewPipelineConfigCookie(v1Config.Spec), v1Config.Cookie)
+
+	// Switching pipelines waits for the pending pipeline config
+	target.Labels = map[string]string{PipelineOverrideLabel: string(newPluginID(v2))}
+	v1Config.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v1Config))
+	result, err := reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), result.RequeueAfter)
+	v2ID := pipelineconfig.NewPipelineConfigID("target-1", v2.Name, v2.Version, v2.Architecture)
+	_, err = pipelineConfigs.Get(ctx, v2ID)
+	assert.True(t, errors.IsNotFound(err))
+
+	// Once the previous pipeline config is complete the new one is created, while the previous one
+	// stays active until the new one runs on the target
+	v1Config.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v1Config))
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v2Config, err := pipelineConfigs.Get(ctx, v2ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v2Config.Action)
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v1Config.Action)
+
+	// A failed push of the new pipeline config does not deactivate the previous one
+	v2Config.Status.State = p4rtapi.PipelineConfigStatus_FAILED
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v2Config))
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v1Config.Action)
+
+	// The previous pipeline config is deactivated once the new one is complete
+	v2Config.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v2Config))
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_UNSPECIFIED, v1Config.Action)
+	v2Config, err = pipelineConfigs.Get(ctx, v2ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v2Config.Action)
+
+	// Removing the override moves the target back to the first pipeline
+	target.Labels = nil
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v1Config.Action)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_PENDING, v1Config.Status.State)
+	v2Config, err = pipelineConfigs.Get(ctx, v2ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v2Config.Action)
+
+	v1Config.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v1Config))
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v2Config, err = pipelineConfigs.Get(ctx, v2ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.ConfigurationAction_UNSPECIFIED, v2Config.Action)
+}
+
+type testCompatiblePlugin struct {
+	testPlugin
+	compatibility *pluginregistry.Compatibility
+}
+
+func (p *testCompatiblePlugin) GetCompatibility() (*pluginregistry.Compatibility, error) {
+	return p.compatibility, nil
+}
+
+func TestReconcileIncompatiblePipeline(t *testing.T) {
+	v1 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "1.0.0", Architecture: "v1model"}
+	v2 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "2.0.0", Architecture: "v1model"}
+	target := newTestTarget("target-1", v1, v2)
+	assert.NoError(t, target.SetAspect(&topoapi.Switch{ModelID: "bmv2"}))
+	topo := topotest.NewStore(target)
+	registry := &testRegistry{plugins: make(map[p4rtapi.P4PluginID]pluginregistry.P4Plugin)}
+	for _, pipelineInfo := range []*topoapi.P4PipelineInfo{v1, v2} {
+		registry.plugins[newPluginID(pipelineInfo)] = &testCompatiblePlugin{
+			testPlugin: testPlugin{
+				pkgInfo: &p4configapi.PkgInfo{Name: pipelineInfo.Name, Version: pipelineInfo.Version, Arch: pipelineInfo.Architecture},
+			},
+			compatibility: &pluginregistry.Compatibility{ModelIDs: []string{"tofino"}},
+		}
+	}
+	pipelineConfigs := pipelineconfig.NewMemoryStore()
+	reconciler := &Reconciler{
+		topo:             topo,
+		pipelineConfigs:  pipelineConfigs,
+		p4PluginRegistry: registry,
+	}
+	ctx := context.Background()
+
+	// Pipelines are not assigned to targets of incompatible models
+	_, err := reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	configs, err := pipelineConfigs.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, configs)
+	assert.Contains(t, target.Labels[PipelineStatusLabel], "not compatible with switch model 'bmv2'")
+
+	// Overriding the pipeline does not bypass the compatibility check
+	target.Labels[PipelineOverrideLabel] = string(newPluginID(v2))
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	assert.Contains(t, target.Labels[PipelineStatusLabel], "cannot run on target target-1")
+
+	// A compatible plugin is assigned and the status is cleared
+	registry.plugins[newPluginID(v2)].(*testCompatiblePlugin).compatibility.ModelIDs = []string{"tofino", "bmv2"}
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	_, ok := target.Labels[PipelineStatusLabel]
+	assert.False(t, ok)
+	v2ID := pipelineconfig.NewPipelineConfigID("target-1", v2.Name, v2.Version, v2.Architecture)
+	_, err = pipelineConfigs.Get(ctx, v2ID)
+	assert.NoError(t, err)
+}
+
+func TestReconcileUnloadedPlugin(t *testing.T) {
+	v1 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "1.0.0", Architecture: "v1model"}
+	target := newTestTarget("target-1", v1)
+	topo := topotest.NewStore(target)
+	registry := &testRegistry{plugins: make(map[p4rtapi.P4PluginID]pluginregistry.P4Plugin)}
+	pipelineConfigs := pipelineconfig.NewMemoryStore()
+	reconciler := &Reconciler{
+		topo:             topo,
+		pipelineConfigs:  pipelineConfigs,
+		p4PluginRegistry: registry,
+	}
+	ctx := context.Background()
+
+	// Targets whose pipelines have no loaded plugin wait for it with an explicit status
+	_, err := reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, "none of the P4 plugins [wcmp-1.0.0-v1model] of the pipelines of target target-1 is loaded", target.Labels[PipelineStatusLabel])
+	configs, err := pipelineConfigs.List(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, configs)
+
+	// Registering the plugin assigns the pipeline and clears the status
+	registry.plugins[newPluginID(v1)] = &testPlugin{
+		pkgInfo: &p4configapi.PkgInfo{Name: v1.Name, Version: v1.Version, Arch: v1.Architecture},
+	}
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	_, ok := target.Labels[PipelineStatusLabel]
+	assert.False(t, ok)
+	configs, err = pipelineConfigs.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, configs, 1)
+
+	// Unregistering the plugin of the running pipeline is reported in the target status
+	assert.NoError(t, registry.UnregisterPlugin(newPluginID(v1)))
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	assert.Contains(t, target.Labels[PipelineStatusLabel], "wcmp-1.0.0-v1model")
+}
+
+type testUpdatedPlugin struct {
+	testPlugin
+	deviceConfig []byte
+}
+
+func (p *testUpdatedPlugin) GetP4DeviceConfig() ([]byte, error) {
+	return p.deviceConfig, nil
+}
+
+func TestReconcileUpdatedPlugin(t *testing.T) {
+	v1 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "1.0.0", Architecture: "v1model"}
+	target := newTestTarget("target-1", v1)
+	topo := topotest.NewStore(target)
+	registry := &testRegistry{plugins: make(map[p4rtapi.P4PluginID]pluginregistry.P4Plugin)}
+	pkgInfo := &p4configapi.PkgInfo{Name: v1.Name, Version: v1.Version, Arch: v1.Architecture}
+	registry.plugins[newPluginID(v1)] = &testPlugin{pkgInfo: pkgInfo}
+	pipelineConfigs := pipelineconfig.NewMemoryStore()
+	reconciler := &Reconciler{
+		topo:             topo,
+		pipelineConfigs:  pipelineConfigs,
+		p4PluginRegistry: registry,
+	}
+	ctx := context.Background()
+
+	_, err := reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1ID := pipelineconfig.NewPipelineConfigID("target-1", v1.Name, v1.Version, v1.Architecture)
+	v1Config, err := pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	v1Config.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v1Config))
+
+	// Pipeline configs are left as is while their plugin is unchanged
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, v1Config.Status.State)
+	revision := v1Config.Revision
+
+	// The spec of an updated plugin is pushed again
+	registry.plugins[newPluginID(v1)] = &testUpdatedPlugin{
+		testPlugin:   testPlugin{pkgInfo: pkgInfo},
+		deviceConfig: []byte("updated"),
+	}
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("updated"), v1Config.Spec.P4DeviceConfig)
+	assert.Equal(t, pipelineconfig.NewPipelineConfigCookie(v1Config.Spec), v1Config.Cookie)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v1Config.Action)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_PENDING, v1Config.Status.State)
+	assert.Equal(t, revision+1, v1Config.Revision)
+
+	// A committed config is verified again with the updated spec rather than committing the saved one
+	v1Config.Action = p4rtapi.ConfigurationAction_COMMIT
+	v1Config.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	assert.NoError(t, pipelineConfigs.Update(ctx, v1Config))
+	registry.plugins[newPluginID(v1)].(*testUpdatedPlugin).deviceConfig = []byte("updated again")
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("updated again"), v1Config.Spec.P4DeviceConfig)
+	assert.Equal(t, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT, v1Config.Action)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_PENDING, v1Config.Status.State)
+}
+
+func TestReconcileRolledBackPipelineConfig(t *testing.T) {
+	v1 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "1.0.0", Architecture: "v1model"}
+	target := newTestTarget("target-1", v1)
+	topo := topotest.NewStore(target)
+	registry := &testRegistry{plugins: make(map[p4rtapi.P4PluginID]pluginregistry.P4Plugin)}
+	pkgInfo := &p4configapi.PkgInfo{Name: v1.Name, Version: v1.Version, Arch: v1.Architecture}
+	registry.plugins[newPluginID(v1)] = &testUpdatedPlugin{
+		testPlugin:   testPlugin{pkgInfo: pkgInfo},
+		deviceConfig: []byte("original"),
+	}
+	pipelineConfigs := pipelineconfig.NewMemoryStore()
+	reconciler := &Reconciler{
+		topo:             topo,
+		pipelineConfigs:  pipelineConfigs,
+		p4PluginRegistry: registry,
+	}
+	ctx := context.Background()
+
+	_, err := reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1ID := pipelineconfig.NewPipelineConfigID("target-1", v1.Name, v1.Version, v1.Architecture)
+	v1Config, err := pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	original := v1Config.Revision
+
+	// Update the plugin so that the pipeline config gets a new revision
+	registry.plugins[newPluginID(v1)].(*testUpdatedPlugin).deviceConfig = []byte("updated")
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("updated"), v1Config.Spec.P4DeviceConfig)
+
+	// The rolled back spec is kept while the plugin is unchanged
+	assert.NoError(t, pipelineconfigctrl.RollbackPipelineConfig(ctx, pipelineConfigs, v1ID, original))
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	v1Config.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v1Config))
+	revision := v1Config.Revision
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("original"), v1Config.Spec.P4DeviceConfig)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, v1Config.Status.State)
+	assert.Equal(t, revision, v1Config.Revision)
+
+	// Updating the plugin again pushes its spec
+	registry.plugins[newPluginID(v1)].(*testUpdatedPlugin).deviceConfig = []byte("updated again")
+	_, err = reconciler.Reconcile(controller.NewID(target.ID))
+	assert.NoError(t, err)
+	v1Config, err = pipelineConfigs.Get(ctx, v1ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("updated again"), v1Config.Spec.P4DeviceConfig)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_PENDING, v1Config.Status.State)
+}
@@ -0,0 +1,124 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipeliner
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+)
+
+const (
+	// PipelineOverrideLabel is the target label used by operators to force the P4 plugin ID of the pipeline to run
+	PipelineOverrideLabel = "wcmp-app/pipeline-override"
+	// PipelinePreferenceLabel is the target label holding the P4 plugin ID of the preferred pipeline
+	PipelinePreferenceLabel = "wcmp-app/pipeline-preference"
+	// PipelineStatusLabel is the target label explaining why no pipeline can be assigned to the target
+	PipelineStatusLabel = "wcmp-app/pipeline-status"
+)
+
+// selectPipeline selects the pipeline to run on a target among the pipelines it advertises. An operator
+// override takes precedence over the preferred pipeline, which takes precedence over the first advertised
+// pipeline for which a P4 plugin compatible with the target is loaded.
+func (r *Reconciler) selectPipeline(target *topoapi.Object, p4rtServerInfo *topoapi.P4RTServerInfo) (*topoapi.P4PipelineInfo, error) {
+	modelID := getModelID(target)
+	if overrideID, ok := target.Labels[PipelineOverrideLabel]; ok {
+		pipelineInfo := findPipeline(p4rtServerInfo, p4rtapi.P4PluginID(overrideID))
+		if pipelineInfo == nil {
+			return nil, errors.NewInvalid("pipeline %s is not supported by target %s", overrideID, target.ID)
+		}
+		if err := r.checkPlugin(modelID, pipelineInfo); err != nil {
+			if errors.IsInvalid(err) {
+				return nil, errors.NewInvalid("pipeline %s cannot run on target %s: %v", overrideID, target.ID, err)
+			}
+			return nil, err
+		}
+		return pipelineInfo, nil
+	}
+
+	if preferredID, ok := target.Labels[PipelinePreferenceLabel]; ok {
+		pipelineInfo := findPipeline(p4rtServerInfo, p4rtapi.P4PluginID(preferredID))
+		if pipelineInfo != nil && r.checkPlugin(modelID, pipelineInfo) == nil {
+			return pipelineInfo, nil
+		}
+		log.Warnw("Preferred pipeline is not available for target", "targetID", target.ID, "pipeline", preferredID)
+	}
+
+	var incompatible []string
+	for _, pipelineInfo := range p4rtServerInfo.Pipelines {
+		err := r.checkPlugin(modelID, pipelineInfo)
+		if err == nil {
+			return pipelineInfo, nil
+		}
+		if errors.IsInvalid(err) {
+			incompatible = append(incompatible, fmt.Sprintf("%s: %v", newPluginID(pipelineInfo), err))
+		}
+	}
+	if len(incompatible) > 0 {
+		return nil, errors.NewInvalid("no P4 plugin compatible with target %s found: %s", target.ID, strings.Join(incompatible, "; "))
+	}
+	pluginIDs := make([]string, 0, len(p4rtServerInfo.Pipelines))
+	for _, pipelineInfo := range p4rtServerInfo.Pipelines {
+		pluginIDs = append(pluginIDs, string(newPluginID(pipelineInfo)))
+	}
+	return nil, errors.NewNotFound("none of the P4 plugins %v of the pipelines of target %s is loaded", pluginIDs, target.ID)
+}
+
+// checkPlugin returns a NotFound error if no P4 plugin is loaded for the pipeline and an Invalid error
+// if the loaded plugin is not compatible with the target switch model
+func (r *Reconciler) checkPlugin(modelID string, pipelineInfo *topoapi.P4PipelineInfo) error {
+	pluginID := newPluginID(pipelineInfo)
+	p4Plugin, err := r.p4PluginRegistry.GetPlugin(pluginID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return errors.NewNotFound("P4 plugin '%s' is not loaded", pluginID)
+		}
+		return err
+	}
+	return pluginregistry.CheckCompatibility(p4Plugin, modelID, pipelineInfo.Architecture)
+}
+
+// setPipelineStatus records why no pipeline can be assigned to a target in its status label, or
+// removes the label if the status is empty
+func (r *Reconciler) setPipelineStatus(ctx context.Context, target *topoapi.Object, status string) error {
+	if target.Labels[PipelineStatusLabel] == status {
+		return nil
+	}
+	if status == "" {
+		delete(target.Labels, PipelineStatusLabel)
+	} else {
+		if target.Labels == nil {
+			target.Labels = make(map[string]string)
+		}
+		target.Labels[PipelineStatusLabel] = status
+	}
+	return r.topo.Update(ctx, target)
+}
+
+func getModelID(target *topoapi.Object) string {
+	switchInfo := &topoapi.Switch{}
+	if err := target.GetAspect(switchInfo); err != nil {
+		return ""
+	}
+	return switchInfo.ModelID
+}
+
+func findPipeline(p4rtServerInfo *topoapi.P4RTServerInfo, pluginID p4rtapi.P4PluginID) *topoapi.P4PipelineInfo {
+	for _, pipelineInfo := range p4rtServerInfo.Pipelines {
+		if newPluginID(pipelineInfo) == pluginID {
+			return pipelineInfo
+		}
+	}
+	return nil
+}
+
+func newPluginID(pipelineInfo *topoapi.P4PipelineInfo) p4rtapi.P4PluginID {
+	return p4rtapi.NewP4PluginID(pipelineInfo.Name, pipelineInfo.Version, pipelineInfo.Architecture)
+}
@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipeliner
+
+import (
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectPipeline(t *testing.T) {
+	v1 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "1.0.0", Architecture: "v1model"}
+	v2 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "2.0.0", Architecture: "v1model"}
+	v3 := &topoapi.P4PipelineInfo{Name: "wcmp", Version: "3.0.0", Architecture: "v1model"}
+
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		loaded   []*topoapi.P4PipelineInfo
+		selected *topoapi.P4PipelineInfo
+		invalid  bool
+		notFound bool
+	}{
+		{
+			name:     "first advertised pipeline",
+			loaded:   []*topoapi.P4PipelineInfo{v1, v2, v3},
+			selected: v1,
+		},
+		{
+			name:     "first loaded pipeline",
+			loaded:   []*topoapi.P4PipelineInfo{v2, v3},
+			selected: v2,
+		},
+		{
+			name:     "preferred pipeline",
+			labels:   map[string]string{PipelinePreferenceLabel: string(newPluginID(v3))},
+			loaded:   []*topoapi.P4PipelineInfo{v1, v2, v3},
+			selected: v3,
+		},
+		{
+			name:     "preferred pipeline not loaded",
+			labels:   map[string]string{PipelinePreferenceLabel: string(newPluginID(v3))},
+			loaded:   []*topoapi.P4PipelineInfo{v1, v2},
+			selected: v1,
+		},
+		{
+			name:     "preferred pipeline not advertised",
+			labels:   map[string]string{PipelinePreferenceLabel: "wcmp-4.0.0-v1model"},
+			loaded:   []*topoapi.P4PipelineInfo{v1, v2, v3},
+			selected: v1,
+		},
+		{
+			name: "override takes precedence over preference",
+			labels: map[string]string{
+				PipelineOverrideLabel:   string(newPluginID(v2)),
+				PipelinePreferenceLabel: string(newPluginID(v3)),
+			},
+			loaded:   []*topoapi.P4PipelineInfo{v1, v2, v3},
+			selected: v2,
+		},
+		{
+			name:    "override not advertised",
+			labels:  map[string]string{PipelineOverrideLabel: "wcmp-4.0.0-v1model"},
+			loaded:  []*topoapi.P4PipelineInfo{v1, v2, v3},
+			invalid: true,
+		},
+		{
+			name:     "override not loaded",
+			labels:   map[string]string{PipelineOverrideLabel: string(newPluginID(v3))},
+			loaded:   []*topoapi.P4PipelineInfo{v1, v2},
+			notFound: true,
+		},
+		{
+			name:     "no pipeline loaded",
+			notFound: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			target := newTestTarget("target-1", v1, v2, v3)
+			target.Labels = test.labels
+			registry := &testRegistry{plugins: make(map[p4rtapi.P4PluginID]pluginregistry.P4Plugin)}
+			for _, pipelineInfo := range test.loaded {
+				registry.plugins[newPluginID(pipelineInfo)] = &testPlugin{
+					pkgInfo: &p4configapi.PkgInfo{Name: pipelineInfo.Name, Version: pipelineInfo.Version, Arch: pipelineInfo.Architecture},
+				}
+			}
+			reconciler := &Reconciler{p4PluginRegistry: registry}
+			p4rtServerInfo := &topoapi.P4RTServerInfo{}
+			assert.NoError(t, target.GetAspect(p4rtServerInfo))
+
+			pipelineInfo, err := reconciler.selectPipeline(target, p4rtServerInfo)
+			switch {
+			case test.invalid:
+				assert.True(t, errors.IsInvalid(err), "%v", err)
+			case test.notFound:
+				assert.True(t, errors.IsNotFound(err), "%v", err)
+			default:
+				assert.NoError(t, err)
+				assert.Equal(t, newPluginID(test.selected), newPluginID(pipelineInfo))
+			}
+		})
+	}
+}
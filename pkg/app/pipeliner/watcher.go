@@ -9,6 +9,7 @@ import (
 	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
 	topoapi "github.com/onosproject/onos-api/go/onos/topo"
 	"github.com/onosproject/onos-lib-go/pkg/controller"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
 	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
 	"github.com/onosproject/wcmp-app/pkg/store/topo"
 	"sync"
@@ -108,3 +109,76 @@ func (w *TopoWatcher) Stop() {
 	}
 	w.mu.Unlock()
 }
+
+// PluginWatcher is a P4 plugin registry watcher
+type PluginWatcher struct {
+	topo             topo.Store
+	pipelineConfigs  pipelineconfig.Store
+	p4PluginRegistry pluginregistry.P4PluginRegistry
+	cancel           context.CancelFunc
+	mu               sync.Mutex
+}
+
+// Start starts the P4 plugin registry watcher
+func (w *PluginWatcher) Start(ch chan<- controller.ID) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return nil
+	}
+
+	eventCh := make(chan pluginregistry.PluginEvent, queueSize)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := w.p4PluginRegistry.Watch(ctx, eventCh)
+	if err != nil {
+		cancel()
+		return err
+	}
+	w.cancel = cancel
+	go func() {
+		for event := range eventCh {
+			log.Infow("Received P4 plugin event", "plugin ID", event.PluginID, "event type", event.Type)
+			// Reconcile the targets advertising the pipeline of the plugin
+			objects, err := w.topo.List(ctx, &topoapi.Filters{
+				ObjectTypes: []topoapi.Object_Type{topoapi.Object_ENTITY},
+			})
+			if err != nil {
+				log.Warnw("Failed listing targets", "plugin ID", event.PluginID, "error", err)
+				continue
+			}
+			for _, object := range objects {
+				p4rtServerInfo := &topoapi.P4RTServerInfo{}
+				if err := object.GetAspect(p4rtServerInfo); err != nil {
+					continue
+				}
+				if findPipeline(p4rtServerInfo, event.PluginID) != nil {
+					ch <- controller.NewID(object.ID)
+				}
+			}
+			if event.Type != pluginregistry.PluginUnregistered {
+				continue
+			}
+			// Targets may still be configured with the pipeline of an unloaded plugin they no longer advertise
+			pipelineConfigs, err := w.pipelineConfigs.List(ctx, pipelineconfig.WithPluginID(event.PluginID))
+			if err != nil {
+				log.Warnw("Failed listing pipeline configs", "plugin ID", event.PluginID, "error", err)
+				continue
+			}
+			for _, pipelineConfig := range pipelineConfigs {
+				ch <- controller.NewID(topoapi.ID(pipelineConfig.TargetID))
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the P4 plugin registry watcher
+func (w *PluginWatcher) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+	w.mu.Unlock()
+}
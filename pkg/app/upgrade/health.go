@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upgrade
+
+import (
+	"context"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	"github.com/onosproject/wcmp-app/pkg/store/topo"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+)
+
+// NewPipelineCookieCheck returns a health check verifying that the pipeline config cookie
+// installed on the target matches the cookie of the new pipeline config
+func NewPipelineCookieCheck(topo topo.Store, conns p4rt.ConnManager) HealthCheck {
+	return func(ctx context.Context, targetID topoapi.ID, pipelineConfig *p4rtapi.PipelineConfig) error {
+		target, err := topo.Get(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		p4rtServerInfo := &topoapi.P4RTServerInfo{}
+		if err := target.GetAspect(p4rtServerInfo); err != nil {
+			return err
+		}
+		conn, err := conns.GetByTarget(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		response, err := conn.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{
+			DeviceId:     p4rtServerInfo.DeviceID,
+			ResponseType: p4api.GetForwardingPipelineConfigRequest_COOKIE_ONLY,
+		})
+		if err != nil {
+			return err
+		}
+		if pipelineConfig.Cookie == nil || response.GetConfig().GetCookie().GetCookie() != pipelineConfig.Cookie.Cookie {
+			return errors.NewConflict("target %s does not run pipeline config %s", targetID, pipelineConfig.ID)
+		}
+		return nil
+	}
+}
+
+// NewMastershipCheck returns a health check verifying that the target has a master
+func NewMastershipCheck(topo topo.Store) HealthCheck {
+	return func(ctx context.Context, targetID topoapi.ID, pipelineConfig *p4rtapi.PipelineConfig) error {
+		target, err := topo.Get(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		mastership := &topoapi.P4RTMastershipState{}
+		_ = target.GetAspect(mastership)
+		if mastership.NodeId == "" {
+			return errors.NewUnavailable("target %s has no master", targetID)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upgrade
+
+import (
+	"context"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type testClient struct {
+	p4rt.Client
+	cookie uint64
+}
+
+func (c *testClient) GetForwardingPipelineConfig(ctx context.Context, request *p4api.GetForwardingPipelineConfigRequest, opts ...grpc.CallOption) (*p4api.GetForwardingPipelineConfigResponse, error) {
+	return &p4api.GetForwardingPipelineConfigResponse{
+		Config: &p4api.ForwardingPipelineConfig{
+			Cookie: &p4api.ForwardingPipelineConfig_Cookie{Cookie: c.cookie},
+		},
+	}, nil
+}
+
+type testConnManager struct {
+	p4rt.ConnManager
+	client *testClient
+}
+
+func (m *testConnManager) GetByTarget(ctx context.Context, targetID topoapi.ID) (p4rt.Client, error) {
+	return m.client, nil
+}
+
+func TestPipelineCookieCheck(t *testing.T) {
+	ctx := context.Background()
+	target := &topoapi.Object{ID: "target-1"}
+	assert.NoError(t, target.SetAspect(&topoapi.P4RTServerInfo{DeviceID: 1}))
+	topo := topotest.NewStore(target)
+	client := &testClient{cookie: 1}
+	check := NewPipelineCookieCheck(topo, &testConnManager{client: client})
+	pipelineConfig := &p4rtapi.PipelineConfig{
+		ID:     "target-1-wcmp-2.0.0-v1model",
+		Cookie: &p4rtapi.Cookie{Cookie: 2},
+	}
+
+	// The target still runs the previous pipeline
+	assert.True(t, errors.IsConflict(check(ctx, target.ID, pipelineConfig)))
+
+	client.cookie = 2
+	assert.NoError(t, check(ctx, target.ID, pipelineConfig))
+}
@@ -0,0 +1,402 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upgrade
+
+import (
+	"context"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"github.com/onosproject/wcmp-app/pkg/app/pipeliner"
+	pipelineConfigController "github.com/onosproject/wcmp-app/pkg/controller/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"sync"
+	"time"
+)
+
+var log = logging.GetLogger()
+
+const (
+	defaultBatchSize     = 1
+	defaultTargetTimeout = 5 * time.Minute
+)
+
+// State is the state of a rolling upgrade
+type State int
+
+const (
+	// Completed indicates all the targets are upgraded
+	Completed State = iota
+	// Paused indicates the upgrade stopped after a failure; the remaining targets are left untouched
+	Paused
+	// RolledBack indicates the upgrade failed and the upgraded targets are moved back to their previous pipeline
+	RolledBack
+)
+
+func (s State) String() string {
+	switch s {
+	case Completed:
+		return "COMPLETED"
+	case Paused:
+		return "PAUSED"
+	case RolledBack:
+		return "ROLLED_BACK"
+	}
+	return "UNKNOWN"
+}
+
+// Plan describes a rolling pipeline upgrade
+type Plan struct {
+	// PluginID is the ID of the P4 plugin of the new pipeline
+	PluginID p4rtapi.P4PluginID
+	// Canaries are the targets upgraded first, one at a time
+	Canaries []topoapi.ID
+	// Targets are the targets upgraded in batches once all the canaries are healthy
+	Targets []topoapi.ID
+	// BatchSize is the number of targets upgraded concurrently
+	BatchSize int
+	// TargetTimeout is the maximum time to wait for a target to run the new pipeline
+	TargetTimeout time.Duration
+	// Rollback indicates whether upgraded targets are rolled back on failure instead of pausing the upgrade
+	Rollback bool
+}
+
+// Status is the outcome of a rolling upgrade
+type Status struct {
+	State State
+	// Upgraded are the targets running the new pipeline
+	Upgraded []topoapi.ID
+	// Failed are the targets that failed the upgrade
+	Failed []topoapi.ID
+	// Remaining are the targets not upgraded yet; they can be upgraded by running a new plan
+	Remaining []topoapi.ID
+	// RolledBack are the targets running their previous pipeline again after a rollback
+	RolledBack []topoapi.ID
+	// Error is the first error that stopped the upgrade
+	Error error
+}
+
+// HealthCheck checks whether a target runs the new pipeline properly
+type HealthCheck func(ctx context.Context, targetID topoapi.ID, pipelineConfig *p4rtapi.PipelineConfig) error
+
+// Upgrader orchestrates rolling pipeline upgrades across targets
+type Upgrader struct {
+	topo             topo.Store
+	pipelineConfigs  pipelineconfig.Store
+	p4PluginRegistry pluginregistry.P4PluginRegistry
+	healthChecks     []HealthCheck
+}
+
+// NewUpgrader creates a new rolling pipeline upgrader
+func NewUpgrader(topo topo.Store, pipelineConfigs pipelineconfig.Store, p4PluginRegistry pluginregistry.P4PluginRegistry, healthChecks ...HealthCheck) *Upgrader {
+	return &Upgrader{
+		topo:             topo,
+		pipelineConfigs:  pipelineConfigs,
+		p4PluginRegistry: p4PluginRegistry,
+		healthChecks:     healthChecks,
+	}
+}
+
+// Run upgrades the targets of the given plan to the new pipeline. Canaries are upgraded first; the remaining
+// targets are upgraded in batches. The upgrade is paused or rolled back as soon as a target fails. Plans
+// switching targets to a pipeline whose WCMP mapping is not compatible with the one they run are rejected.
+func (u *Upgrader) Run(ctx context.Context, plan Plan) (*Status, error) {
+	p4Plugin, err := u.p4PluginRegistry.GetPlugin(plan.PluginID)
+	if err != nil {
+		return nil, err
+	}
+	pkgInfo, err := p4Plugin.GetPkgInfo()
+	if err != nil {
+		return nil, err
+	}
+	if plan.BatchSize <= 0 {
+		plan.BatchSize = defaultBatchSize
+	}
+	if plan.TargetTimeout == 0 {
+		plan.TargetTimeout = defaultTargetTimeout
+	}
+
+	run := &upgrade{
+		Upgrader: u,
+		plan:     plan,
+		pkgInfo:  pkgInfo,
+		previous: make(map[topoapi.ID]previousPipeline),
+		status:   &Status{},
+	}
+
+	if err := run.checkWCMPCompatibility(ctx); err != nil {
+		return nil, err
+	}
+
+	batches := make([][]topoapi.ID, 0, len(plan.Canaries)+len(plan.Targets)/plan.BatchSize+1)
+	for _, canary := range plan.Canaries {
+		batches = append(batches, []topoapi.ID{canary})
+	}
+	for i := 0; i < len(plan.Targets); i += plan.BatchSize {
+		end := i + plan.BatchSize
+		if end > len(plan.Targets) {
+			end = len(plan.Targets)
+		}
+		batches = append(batches, plan.Targets[i:end])
+	}
+
+	for i, batch := range batches {
+		log.Infow("Upgrading pipeline of targets", "plugin ID", plan.PluginID, "targets", batch)
+		if err := run.upgradeBatch(ctx, batch); err != nil {
+			log.Warnw("Pipeline upgrade failed", "plugin ID", plan.PluginID, "targets", batch, "error", err)
+			run.status.Error = err
+			for _, remaining := range batches[i+1:] {
+				run.status.Remaining = append(run.status.Remaining, remaining...)
+			}
+			if plan.Rollback {
+				run.rollback(ctx)
+				run.status.State = RolledBack
+			} else {
+				run.status.State = Paused
+			}
+			return run.status, nil
+		}
+	}
+	run.status.State = Completed
+	log.Infow("Pipeline upgrade is completed", "plugin ID", plan.PluginID, "targets", run.status.Upgraded)
+	return run.status, nil
+}
+
+type upgrade struct {
+	*Upgrader
+	plan    Plan
+	pkgInfo *p4configapi.PkgInfo
+	// touched are the targets whose pipeline override was changed, in upgrade order
+	touched  []topoapi.ID
+	previous map[topoapi.ID]previousPipeline
+	status   *Status
+}
+
+// checkWCMPCompatibility checks that the WCMP mapping of the new pipeline is compatible with the WCMP mappings
+// of the pipelines the targets of the plan are running
+func (u *upgrade) checkWCMPCompatibility(ctx context.Context) error {
+	checked := make(map[p4rtapi.P4PluginID]bool)
+	for _, targetID := range append(append([]topoapi.ID{}, u.plan.Canaries...), u.plan.Targets...) {
+		runningID, err := u.getRunningPipelineConfig(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if runningID == "" {
+			continue
+		}
+		pluginDetails, err := u.pipelineConfigs.GetPluginDetails(ctx, runningID)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if pluginDetails.PluginID == u.plan.PluginID || checked[pluginDetails.PluginID] {
+			continue
+		}
+		checked[pluginDetails.PluginID] = true
+		diff, err := pluginregistry.CompareWCMPPlugins(u.p4PluginRegistry, pluginDetails.PluginID, u.plan.PluginID)
+		if err != nil {
+			return errors.NewInvalid("cannot check the WCMP mapping of P4 plugin %s against P4 plugin %s run by target %s: %v",
+				u.plan.PluginID, pluginDetails.PluginID, targetID, err)
+		}
+		if !diff.IsCompatible() {
+			return errors.NewInvalid("WCMP mapping of P4 plugin %s is not compatible with P4 plugin %s run by target %s: %v",
+				u.plan.PluginID, pluginDetails.PluginID, targetID, diff.BreakingChanges())
+		}
+	}
+	return nil
+}
+
+// previousPipeline is the pipeline a target was running before the upgrade
+type previousPipeline struct {
+	// override is the previous value of the pipeline override label of the target
+	override string
+	// pipelineConfigID is the ID of the pipeline config the target was running, if any
+	pipelineConfigID p4rtapi.PipelineConfigID
+}
+
+func (u *upgrade) upgradeBatch(ctx context.Context, targetIDs []topoapi.ID) error {
+	var batchErr error
+	relabelled := make([]topoapi.ID, 0, len(targetIDs))
+	for i, targetID := range targetIDs {
+		if err := u.switchPipeline(ctx, targetID); err != nil {
+			log.Warnw("Failed switching pipeline of target", "targetID", targetID, "plugin ID", u.plan.PluginID, "error", err)
+			u.status.Failed = append(u.status.Failed, targetID)
+			u.status.Remaining = append(u.status.Remaining, targetIDs[i+1:]...)
+			batchErr = err
+			break
+		}
+		relabelled = append(relabelled, targetID)
+	}
+
+	// The targets relabelled before a failure are already switching pipelines, so their outcome is still awaited
+	pipelineConfigID := func(targetID topoapi.ID) p4rtapi.PipelineConfigID {
+		return pipelineconfig.NewPipelineConfigID(p4rtapi.TargetID(targetID), u.pkgInfo.Name, u.pkgInfo.Version, u.pkgInfo.Arch)
+	}
+	errs := u.waitForTargets(ctx, relabelled, pipelineConfigID, u.healthChecks)
+	for i, targetID := range relabelled {
+		if errs[i] != nil {
+			log.Warnw("Target failed the pipeline upgrade", "targetID", targetID, "plugin ID", u.plan.PluginID, "error", errs[i])
+			u.status.Failed = append(u.status.Failed, targetID)
+			if batchErr == nil {
+				batchErr = errs[i]
+			}
+		} else {
+			log.Infow("Target is upgraded", "targetID", targetID, "plugin ID", u.plan.PluginID)
+			u.status.Upgraded = append(u.status.Upgraded, targetID)
+		}
+	}
+	return batchErr
+}
+
+// switchPipeline records the pipeline a target is running and overrides it with the new pipeline
+func (u *upgrade) switchPipeline(ctx context.Context, targetID topoapi.ID) error {
+	runningID, err := u.getRunningPipelineConfig(ctx, targetID)
+	if err != nil {
+		return err
+	}
+	override, err := u.setPipelineOverride(ctx, targetID, string(u.plan.PluginID))
+	if err != nil {
+		return err
+	}
+	u.touched = append(u.touched, targetID)
+	u.previous[targetID] = previousPipeline{
+		override:         override,
+		pipelineConfigID: runningID,
+	}
+	return nil
+}
+
+// getRunningPipelineConfig returns the ID of the pipeline config running on a target, if any
+func (u *upgrade) getRunningPipelineConfig(ctx context.Context, targetID topoapi.ID) (p4rtapi.PipelineConfigID, error) {
+	pipelineConfigs, err := u.pipelineConfigs.List(ctx, pipelineconfig.WithTargetID(p4rtapi.TargetID(targetID)))
+	if err != nil {
+		return "", err
+	}
+	for _, pipelineConfig := range pipelineConfigs {
+		if pipelineConfigController.IsRunning(pipelineConfig) {
+			return pipelineConfig.ID, nil
+		}
+	}
+	return "", nil
+}
+
+// waitForTargets waits concurrently for each target to run its pipeline config and returns the error of each target
+func (u *upgrade) waitForTargets(ctx context.Context, targetIDs []topoapi.ID, pipelineConfigID func(topoapi.ID) p4rtapi.PipelineConfigID, healthChecks []HealthCheck) []error {
+	errs := make([]error, len(targetIDs))
+	var wg sync.WaitGroup
+	for i, targetID := range targetIDs {
+		wg.Add(1)
+		go func(i int, targetID topoapi.ID) {
+			defer wg.Done()
+			errs[i] = u.waitForTarget(ctx, targetID, pipelineConfigID(targetID), healthChecks)
+		}(i, targetID)
+	}
+	wg.Wait()
+	return errs
+}
+
+// waitForTarget waits for a target to run the given pipeline config and then runs the health checks
+func (u *upgrade) waitForTarget(ctx context.Context, targetID topoapi.ID, pipelineConfigID p4rtapi.PipelineConfigID, healthChecks []HealthCheck) error {
+	ctx, cancel := context.WithTimeout(ctx, u.plan.TargetTimeout)
+	defer cancel()
+
+	ch := make(chan p4rtapi.ConfigurationEvent)
+	if err := u.pipelineConfigs.Watch(ctx, ch, pipelineconfig.WithPipelineConfigID(pipelineConfigID), pipelineconfig.WithReplay()); err != nil {
+		return err
+	}
+	defer func() {
+		go func() {
+			for range ch {
+			}
+		}()
+	}()
+
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return errors.NewTimeout("pipeline config %s is not complete", pipelineConfigID)
+			}
+			pipelineConfig := event.PipelineConfig
+			if !pipelineConfigController.IsRunning(&pipelineConfig) {
+				continue
+			}
+			for _, healthCheck := range healthChecks {
+				if err := healthCheck(ctx, targetID, &pipelineConfig); err != nil {
+					return err
+				}
+			}
+			return nil
+		case <-ctx.Done():
+			return errors.NewTimeout("pipeline config %s is not complete", pipelineConfigID)
+		}
+	}
+}
+
+// rollback moves the targets touched by the upgrade back to the pipeline they were running before and
+// waits for them to run it again
+func (u *upgrade) rollback(ctx context.Context) {
+	var restored []topoapi.ID
+	for _, targetID := range u.touched {
+		previous := u.previous[targetID]
+		log.Infow("Rolling back pipeline of target", "targetID", targetID, "plugin ID", previous.override)
+		if _, err := u.setPipelineOverride(ctx, targetID, previous.override); err != nil {
+			log.Warnw("Failed rolling back pipeline of target", "targetID", targetID, "error", err)
+			continue
+		}
+		if previous.pipelineConfigID == "" {
+			u.status.RolledBack = append(u.status.RolledBack, targetID)
+			continue
+		}
+		restored = append(restored, targetID)
+	}
+
+	pipelineConfigID := func(targetID topoapi.ID) p4rtapi.PipelineConfigID {
+		return u.previous[targetID].pipelineConfigID
+	}
+	errs := u.waitForTargets(ctx, restored, pipelineConfigID, nil)
+	for i, targetID := range restored {
+		if errs[i] != nil {
+			log.Warnw("Target does not run its previous pipeline after rollback", "targetID", targetID, "pipelineConfigID", pipelineConfigID(targetID), "error", errs[i])
+			continue
+		}
+		log.Infow("Target is rolled back", "targetID", targetID, "pipelineConfigID", pipelineConfigID(targetID))
+		u.status.RolledBack = append(u.status.RolledBack, targetID)
+	}
+}
+
+// setPipelineOverride sets the pipeline override label of a target, removing it if the plugin ID is empty,
+// and returns the previous value of the label
+func (u *upgrade) setPipelineOverride(ctx context.Context, targetID topoapi.ID, pluginID string) (string, error) {
+	for {
+		target, err := u.topo.Get(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		if target.Labels == nil {
+			target.Labels = make(map[string]string)
+		}
+		previous := target.Labels[pipeliner.PipelineOverrideLabel]
+		if pluginID == "" {
+			delete(target.Labels, pipeliner.PipelineOverrideLabel)
+		} else {
+			target.Labels[pipeliner.PipelineOverrideLabel] = pluginID
+		}
+		err = u.topo.Update(ctx, target)
+		if err == nil {
+			return previous, nil
+		}
+		if !errors.IsConflict(err) {
+			return "", err
+		}
+		log.Debugw("Write conflict updating target pipeline override", "targetID", targetID, "error", err)
+	}
+}
@@ -0,0 +1,336 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package upgrade
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/app/pipeliner"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testPluginV1 = "wcmp-1.0.0-v1model"
+	testPluginV2 = "wcmp-2.0.0-v1model"
+)
+
+// testTopo is a topo store simulating the pipeliner: overriding the pipeline of a target switches the
+// target to the pipeline config of the overriding plugin
+// testTopo is a topology store simulating the pipeliner, which switches the pipeline of the targets
+// relabelled with a pipeline override
+type testTopo struct {
+	*topotest.Store
+	pipelineConfigs pipelineconfig.Store
+	// updateErrs are the errors returned when relabelling targets
+	updateErrs map[topoapi.ID]error
+	// failed are the targets failing to run the new pipeline
+	failed map[topoapi.ID]bool
+	mu     sync.Mutex
+}
+
+func (t *testTopo) Update(ctx context.Context, object *topoapi.Object) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.updateErrs[object.ID]; err != nil {
+		return err
+	}
+	if err := t.Store.Update(ctx, object); err != nil {
+		return err
+	}
+	pluginID := object.Labels[pipeliner.PipelineOverrideLabel]
+	if pluginID == "" {
+		pluginID = testPluginV1
+	}
+	return t.switchPipeline(ctx, object.ID, pluginID)
+}
+
+func (t *testTopo) switchPipeline(ctx context.Context, targetID topoapi.ID, pluginID string) error {
+	selectedID := newTestPipelineConfigID(targetID, pluginID)
+	selected, err := t.pipelineConfigs.Get(ctx, selectedID)
+	if errors.IsNotFound(err) {
+		selected = &p4rtapi.PipelineConfig{
+			ID:       selectedID,
+			TargetID: p4rtapi.TargetID(targetID),
+			Action:   p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT,
+			Spec:     &p4rtapi.PipelineConfigSpec{},
+		}
+		err = t.pipelineConfigs.Create(ctx, selected)
+	} else if err == nil {
+		selected.Action = p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT
+		err = t.pipelineConfigs.Update(ctx, selected)
+	}
+	if err != nil {
+		return err
+	}
+	if pluginID != testPluginV1 && t.failed[targetID] {
+		selected.Status.State = p4rtapi.PipelineConfigStatus_FAILED
+		return t.pipelineConfigs.UpdateStatus(ctx, selected)
+	}
+	selected.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	if err := t.pipelineConfigs.UpdateStatus(ctx, selected); err != nil {
+		return err
+	}
+	pipelineConfigs, err := t.pipelineConfigs.List(ctx, pipelineconfig.WithTargetID(p4rtapi.TargetID(targetID)))
+	if err != nil {
+		return err
+	}
+	for _, pipelineConfig := range pipelineConfigs {
+		if pipelineConfig.ID != selectedID {
+			pipelineConfig.Action = p4rtapi.ConfigurationAction_UNSPECIFIED
+			if err := t.pipelineConfigs.Update(ctx, pipelineConfig); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type testPlugin struct {
+	pluginregistry.P4Plugin
+}
+
+func (p *testPlugin) GetPkgInfo() (*p4configapi.PkgInfo, error) {
+	return &p4configapi.PkgInfo{Name: "wcmp", Version: "2.0.0", Arch: "v1model"}, nil
+}
+
+type testRegistry struct {
+	pluginregistry.P4PluginRegistry
+}
+
+func (r *testRegistry) GetPlugin(id p4rtapi.P4PluginID) (pluginregistry.P4Plugin, error) {
+	if id != testPluginV2 {
+		return nil, errors.NewNotFound("P4 plugin with ID '%s' not found", id)
+	}
+	return &testPlugin{}, nil
+}
+
+func newTestPipelineConfigID(targetID topoapi.ID, pluginID string) p4rtapi.PipelineConfigID {
+	if pluginID == testPluginV1 {
+		return pipelineconfig.NewPipelineConfigID(p4rtapi.TargetID(targetID), "wcmp", "1.0.0", "v1model")
+	}
+	return pipelineconfig.NewPipelineConfigID(p4rtapi.TargetID(targetID), "wcmp", "2.0.0", "v1model")
+}
+
+func TestUpgrade(t *testing.T) {
+	tests := []struct {
+		name       string
+		plan       Plan
+		failed     []topoapi.ID
+		unhealthy  []topoapi.ID
+		updateErrs []topoapi.ID
+		state      State
+		upgraded   []topoapi.ID
+		failedIDs  []topoapi.ID
+		remaining  []topoapi.ID
+		rolledBack []topoapi.ID
+		// running are the targets expected to run the new pipeline at the end of the upgrade
+		running []topoapi.ID
+	}{
+		{
+			name:     "completed",
+			plan:     Plan{Canaries: []topoapi.ID{"c1"}, Targets: []topoapi.ID{"t1", "t2", "t3"}, BatchSize: 2},
+			state:    Completed,
+			upgraded: []topoapi.ID{"c1", "t1", "t2", "t3"},
+			running:  []topoapi.ID{"c1", "t1", "t2", "t3"},
+		},
+		{
+			name:      "canary failure",
+			plan:      Plan{Canaries: []topoapi.ID{"c1", "c2"}, Targets: []topoapi.ID{"t1", "t2"}, BatchSize: 2},
+			failed:    []topoapi.ID{"c1"},
+			state:     Paused,
+			failedIDs: []topoapi.ID{"c1"},
+			remaining: []topoapi.ID{"c2", "t1", "t2"},
+		},
+		{
+			name:      "unhealthy canary",
+			plan:      Plan{Canaries: []topoapi.ID{"c1"}, Targets: []topoapi.ID{"t1"}},
+			unhealthy: []topoapi.ID{"c1"},
+			state:     Paused,
+			failedIDs: []topoapi.ID{"c1"},
+			remaining: []topoapi.ID{"t1"},
+			running:   []topoapi.ID{"c1"},
+		},
+		{
+			name:      "batch failure",
+			plan:      Plan{Canaries: []topoapi.ID{"c1"}, Targets: []topoapi.ID{"t1", "t2", "t3"}, BatchSize: 2},
+			failed:    []topoapi.ID{"t2"},
+			state:     Paused,
+			upgraded:  []topoapi.ID{"c1", "t1"},
+			failedIDs: []topoapi.ID{"t2"},
+			remaining: []topoapi.ID{"t3"},
+			running:   []topoapi.ID{"c1", "t1"},
+		},
+		{
+			name:       "relabel failure in batch",
+			plan:       Plan{Targets: []topoapi.ID{"t1", "t2", "t3"}, BatchSize: 3},
+			updateErrs: []topoapi.ID{"t2"},
+			state:      Paused,
+			upgraded:   []topoapi.ID{"t1"},
+			failedIDs:  []topoapi.ID{"t2"},
+			remaining:  []topoapi.ID{"t3"},
+			running:    []topoapi.ID{"t1"},
+		},
+		{
+			name:       "rollback",
+			plan:       Plan{Canaries: []topoapi.ID{"c1"}, Targets: []topoapi.ID{"t1", "t2", "t3"}, BatchSize: 2, Rollback: true},
+			failed:     []topoapi.ID{"t2"},
+			state:      RolledBack,
+			upgraded:   []topoapi.ID{"c1", "t1"},
+			failedIDs:  []topoapi.ID{"t2"},
+			remaining:  []topoapi.ID{"t3"},
+			rolledBack: []topoapi.ID{"c1", "t1", "t2"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			pipelineConfigs := pipelineconfig.NewMemoryStore()
+			topo := &testTopo{
+				Store:           topotest.NewStore(),
+				pipelineConfigs: pipelineConfigs,
+				updateErrs:      make(map[topoapi.ID]error),
+				failed:          make(map[topoapi.ID]bool),
+			}
+			targetIDs := append(append([]topoapi.ID{}, test.plan.Canaries...), test.plan.Targets...)
+			for _, targetID := range targetIDs {
+				assert.NoError(t, topo.Create(ctx, &topoapi.Object{ID: targetID}))
+				assert.NoError(t, topo.switchPipeline(ctx, targetID, testPluginV1))
+			}
+			for _, targetID := range test.failed {
+				topo.failed[targetID] = true
+			}
+			for _, targetID := range test.updateErrs {
+				topo.updateErrs[targetID] = errors.NewUnavailable("topo is unavailable")
+			}
+			unhealthy := make(map[topoapi.ID]bool)
+			for _, targetID := range test.unhealthy {
+				unhealthy[targetID] = true
+			}
+			healthCheck := func(ctx context.Context, targetID topoapi.ID, pipelineConfig *p4rtapi.PipelineConfig) error {
+				if unhealthy[targetID] {
+					return errors.NewConflict("target %s is unhealthy", targetID)
+				}
+				return nil
+			}
+
+			upgrader := NewUpgrader(topo, pipelineConfigs, &testRegistry{}, healthCheck)
+			plan := test.plan
+			plan.PluginID = testPluginV2
+			plan.TargetTimeout = 200 * time.Millisecond
+			status, err := upgrader.Run(ctx, plan)
+			assert.NoError(t, err)
+			assert.Equal(t, test.state, status.State)
+			assert.Equal(t, test.upgraded, status.Upgraded)
+			assert.Equal(t, test.failedIDs, status.Failed)
+			assert.Equal(t, test.remaining, status.Remaining)
+			assert.ElementsMatch(t, test.rolledBack, status.RolledBack)
+			if test.state == Completed {
+				assert.NoError(t, status.Error)
+			} else {
+				assert.Error(t, status.Error)
+			}
+
+			running := make(map[topoapi.ID]bool)
+			for _, targetID := range test.running {
+				running[targetID] = true
+			}
+			for _, targetID := range targetIDs {
+				runningID, err := (&upgrade{Upgrader: upgrader}).getRunningPipelineConfig(ctx, targetID)
+				assert.NoError(t, err)
+				if running[targetID] {
+					assert.Equal(t, newTestPipelineConfigID(targetID, testPluginV2), runningID, "target %s", targetID)
+				} else {
+					assert.Equal(t, newTestPipelineConfigID(targetID, testPluginV1), runningID, "target %s", targetID)
+				}
+			}
+		})
+	}
+}
+
+type testWCMPPlugin struct {
+	testPlugin
+	p4Info  *p4configapi.P4Info
+	mapping *pluginregistry.WCMPMapping
+}
+
+func (p *testWCMPPlugin) GetP4Info() (*p4configapi.P4Info, error) {
+	return p.p4Info, nil
+}
+
+func (p *testWCMPPlugin) GetWCMPMapping() (*pluginregistry.WCMPMapping, error) {
+	return p.mapping, nil
+}
+
+type testWCMPRegistry struct {
+	pluginregistry.P4PluginRegistry
+	plugins map[p4rtapi.P4PluginID]pluginregistry.P4Plugin
+}
+
+func (r *testWCMPRegistry) GetPlugin(id p4rtapi.P4PluginID) (pluginregistry.P4Plugin, error) {
+	p4Plugin, ok := r.plugins[id]
+	if !ok {
+		return nil, errors.NewNotFound("P4 plugin with ID '%s' not found", id)
+	}
+	return p4Plugin, nil
+}
+
+// newTestWCMPPlugin returns a plugin whose next hop action has the given port param
+func newTestWCMPPlugin(portParam string) *testWCMPPlugin {
+	return &testWCMPPlugin{
+		p4Info: &p4configapi.P4Info{
+			Actions: []*p4configapi.Action{
+				{
+					Preamble: &p4configapi.Preamble{Id: 1, Name: "ingress.route_hashed"},
+					Params:   []*p4configapi.Action_Param{{Id: 1, Name: portParam, Bitwidth: 9}},
+				},
+			},
+		},
+		mapping: &pluginregistry.WCMPMapping{
+			NextHop: pluginregistry.NextHopMapping{Action: "ingress.route_hashed", PortParam: portParam},
+		},
+	}
+}
+
+func TestUpgradeWCMPCompatibility(t *testing.T) {
+	ctx := context.Background()
+	pipelineConfigs := pipelineconfig.NewMemoryStore()
+	topo := &testTopo{
+		Store:           topotest.NewStore(&topoapi.Object{ID: "t1"}),
+		pipelineConfigs: pipelineConfigs,
+	}
+	assert.NoError(t, topo.switchPipeline(ctx, "t1", testPluginV1))
+	assert.NoError(t, pipelineConfigs.UpdatePluginDetails(ctx, newTestPipelineConfigID("t1", testPluginV1),
+		&pipelineconfig.PluginDetails{PluginID: testPluginV1}))
+	registry := &testWCMPRegistry{plugins: map[p4rtapi.P4PluginID]pluginregistry.P4Plugin{
+		testPluginV1: newTestWCMPPlugin("port_num"),
+		testPluginV2: newTestWCMPPlugin("port"),
+	}}
+	upgrader := NewUpgrader(topo, pipelineConfigs, registry)
+	plan := Plan{PluginID: testPluginV2, Targets: []topoapi.ID{"t1"}, TargetTimeout: 200 * time.Millisecond}
+
+	// The port param of the next hop action is renamed, which breaks the WCMP mapping
+	_, err := upgrader.Run(ctx, plan)
+	assert.True(t, errors.IsInvalid(err))
+	runningID, err := (&upgrade{Upgrader: upgrader}).getRunningPipelineConfig(ctx, "t1")
+	assert.NoError(t, err)
+	assert.Equal(t, newTestPipelineConfigID("t1", testPluginV1), runningID)
+
+	registry.plugins[testPluginV2] = newTestWCMPPlugin("port_num")
+	status, err := upgrader.Run(ctx, plan)
+	assert.NoError(t, err)
+	assert.Equal(t, Completed, status.State)
+	assert.Equal(t, []topoapi.ID{"t1"}, status.Upgraded)
+}
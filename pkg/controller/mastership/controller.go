@@ -14,7 +14,6 @@ import (
 	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
 	"github.com/onosproject/wcmp-app/pkg/store/topo"
 	"google.golang.org/genproto/googleapis/rpc/code"
-	"io"
 
 	"time"
 )
@@ -26,21 +25,29 @@ const defaultTimeout = 30 * time.Second
 // NewController returns a new mastership controller
 func NewController(topo topo.Store, conns p4rt.ConnManager) *controller.Controller {
 	c := controller.NewController("mastership")
+	arbitrations := newArbitrations()
 	c.Watch(&TopoWatcher{
 		topo: topo,
 	})
 
+	c.Watch(&ArbitrationWatcher{
+		conns:        conns,
+		arbitrations: arbitrations,
+	})
+
 	c.Reconcile(&Reconciler{
-		topo:  topo,
-		conns: conns,
+		topo:         topo,
+		conns:        conns,
+		arbitrations: arbitrations,
 	})
 	return c
 }
 
 // Reconciler is mastership reconciler
 type Reconciler struct {
-	topo  topo.Store
-	conns p4rt.ConnManager
+	topo         topo.Store
+	conns        p4rt.ConnManager
+	arbitrations *arbitrations
 }
 
 // Reconcile reconciles the mastership state for a gnmi target
@@ -115,41 +122,31 @@ func (r *Reconciler) Reconcile(id controller.ID) (controller.Result, error) {
 			log.Infow("Master in term resigned for the P4RT target", "targetID", targetEntity.ID, "mastership term", mastership.Term)
 			mastership.NodeId = ""
 		} else {
-			conn, err := r.conns.GetByTarget(ctx, targetID)
-			if err != nil {
-				if errors.IsNotFound(err) {
-					return controller.Result{}, nil
-				}
-				log.Warnw("Failed to reconcile mastership election for the P4RT target", "targetID", targetEntity.ID, "error", err)
-				return controller.Result{}, err
-			}
-
+			// Master arbitration updates are received asynchronously; the target is reconciled again
+			// once the update responding to the arbitration request is received
 			electionID := mastership.Term + 1
-
-			log.Infow("Sending MasterArbitrationUpdate message", "target ID", targetEntity.ID, "election ID", electionID)
-			err = conn.SendArbitrationRequest(p4targetInfo.DeviceID, electionID, controllerInfo.Role.Name)
-			if err != nil {
-				if errors.IsNotFound(err) || errors.IsInvalid(err) {
+			response := r.arbitrations.pop(targetID, electionID)
+			if response == nil {
+				conn, err := r.conns.GetByTarget(ctx, targetID)
+				if err != nil {
+					if errors.IsNotFound(err) {
+						return controller.Result{}, nil
+					}
 					log.Warnw("Failed to reconcile mastership election for the P4RT target", "targetID", targetEntity.ID, "error", err)
-					return controller.Result{}, nil
-				}
-				log.Warnw("Failed to reconcile mastership election for the P4RT target", "targetID", targetEntity.ID, "error", err.Error())
-				return controller.Result{}, err
-			}
-			response, err := conn.RecvArbitrationResponse()
-			if err != nil {
-				log.Warnw("Failed to reconcile mastership election for the P4RT target", "targetID", targetEntity.ID, "error", err)
-				// If the election_id is set and is already used by another controller
-				// for the same (device_id, role), the P4Runtime server shall terminate the stream by returning an INVALID_ARGUMENT error.
-				if errors.IsInvalid(err) {
-					log.Warnw("Invalid argument, failed to reconcile mastership election for the P4RT target", "error", err)
 					return controller.Result{}, err
 				}
-				if err == io.EOF {
-					log.Warnw("End of file")
-					return controller.Result{}, nil
-				}
 
+				log.Infow("Sending MasterArbitrationUpdate message", "target ID", targetEntity.ID, "election ID", electionID)
+				err = conn.SendArbitrationRequest(p4targetInfo.DeviceID, electionID, controllerInfo.Role.Name)
+				if err != nil {
+					if errors.IsNotFound(err) || errors.IsInvalid(err) {
+						log.Warnw("Failed to reconcile mastership election for the P4RT target", "targetID", targetEntity.ID, "error", err)
+						return controller.Result{}, nil
+					}
+					log.Warnw("Failed to reconcile mastership election for the P4RT target", "targetID", targetEntity.ID, "error", err.Error())
+					return controller.Result{}, err
+				}
+				return controller.Result{}, nil
 			}
 
 			/*status is set differently based on whether the notification is sent to the primary or a backup controller:
@@ -157,11 +154,11 @@ func (r *Reconciler) Reconcile(id controller.ID) (controller.Result, error) {
 			   * For the primary, status is OK (with status.code set to google.rpc.OK).
 			   * For all backup controllers, status is set to non-OK (with status.code set to google.rpc.ALREADY_EXISTS).
 			Otherwise, if there is no primary currently, for all backup controllers, status is set to non-OK (with status.code set to google.rpc.NOT_FOUND).*/
-			statusCode := response.Arbitration.Status.Code
+			statusCode := response.GetStatus().GetCode()
 			if statusCode == int32(code.Code_OK) {
 				for _, targetRelation := range targetRelations {
 					if targetRelation.GetRelation().SrcEntityID == utils.GetControllerID() {
-						responseElectionID := response.Arbitration.ElectionId.Low
+						responseElectionID := response.GetElectionId().GetLow()
 						log.Infow("Current node is selected as master, updating mastership status", "targetID", targetEntity.ID, "election ID", responseElectionID)
 						mastership.NodeId = string(targetRelation.ID)
 						mastership.Term = responseElectionID
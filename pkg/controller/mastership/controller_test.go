@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package mastership
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/controller"
+	"github.com/onosproject/wcmp-app/pkg/controller/utils"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/status"
+)
+
+const (
+	testTargetID   = topoapi.ID("target-1")
+	testRelationID = topoapi.ID("relation-1")
+)
+
+type testConn struct {
+	p4rt.Conn
+	streamCh    chan<- *p4api.StreamMessageResponse
+	electionIDs chan uint64
+}
+
+func (c *testConn) ID() p4rt.ConnID {
+	return "conn-1"
+}
+
+func (c *testConn) TargetID() topoapi.ID {
+	return testTargetID
+}
+
+func (c *testConn) Subscribe(ctx context.Context, ch chan<- *p4api.StreamMessageResponse, types ...p4rt.StreamMessageType) error {
+	c.streamCh = ch
+	return nil
+}
+
+func (c *testConn) SendArbitrationRequest(deviceID uint64, electionID uint64, role string) error {
+	c.electionIDs <- electionID
+	return nil
+}
+
+type testConnManager struct {
+	p4rt.ConnManager
+	conn *testConn
+}
+
+func (m *testConnManager) Get(ctx context.Context, connID p4rt.ConnID) (p4rt.Conn, bool) {
+	return m.conn, true
+}
+
+func (m *testConnManager) GetByTarget(ctx context.Context, targetID topoapi.ID) (p4rt.Client, error) {
+	return m.conn, nil
+}
+
+func (m *testConnManager) Watch(ctx context.Context, ch chan<- p4rt.Conn) error {
+	ch <- m.conn
+	return nil
+}
+
+// newTestReconciler returns a reconciler of a target controlled by the local node whose master arbitration
+// updates are delivered by a started arbitration watcher
+func newTestReconciler(t *testing.T) (*Reconciler, *testConn, <-chan controller.ID) {
+	controllerID := utils.GetControllerID()
+	target := &topoapi.Object{
+		ID:   testTargetID,
+		Type: topoapi.Object_ENTITY,
+	}
+	assert.NoError(t, target.SetAspect(&topoapi.P4RTServerInfo{DeviceID: 1}))
+	controllerEntity := &topoapi.Object{
+		ID:   controllerID,
+		Type: topoapi.Object_ENTITY,
+	}
+	assert.NoError(t, controllerEntity.SetAspect(&topoapi.ControllerInfo{Role: &topoapi.ControllerRole{Name: "wcmp-app"}}))
+	relation := &topoapi.Object{
+		ID:   testRelationID,
+		Type: topoapi.Object_RELATION,
+		Obj: &topoapi.Object_Relation{
+			Relation: &topoapi.Relation{
+				KindID:      topoapi.CONTROLS,
+				SrcEntityID: controllerID,
+				TgtEntityID: testTargetID,
+			},
+		},
+	}
+	topo := topotest.NewStore(target, controllerEntity, relation)
+
+	conn := &testConn{electionIDs: make(chan uint64, 1)}
+	conns := &testConnManager{conn: conn}
+	arbitrations := newArbitrations()
+	watcher := &ArbitrationWatcher{
+		conns:        conns,
+		arbitrations: arbitrations,
+	}
+	ch := make(chan controller.ID, queueSize)
+	assert.NoError(t, watcher.Start(ch))
+	t.Cleanup(watcher.Stop)
+
+	// The arbitration runs once the stream channel of the connection is open
+	assert.Equal(t, controller.NewID(testTargetID), nextID(t, ch))
+	reconciler := &Reconciler{
+		topo:         topo,
+		conns:        conns,
+		arbitrations: arbitrations,
+	}
+	result, err := reconciler.Reconcile(controller.NewID(testTargetID))
+	assert.NoError(t, err)
+	assert.Equal(t, controller.Result{}, result)
+	assert.Equal(t, uint64(1), <-conn.electionIDs)
+	return reconciler, conn, ch
+}
+
+func nextID(t *testing.T, ch <-chan controller.ID) controller.ID {
+	select {
+	case id := <-ch:
+		return id
+	case <-time.After(time.Second):
+		t.Fatal("no target enqueued")
+	}
+	return controller.ID{}
+}
+
+// sendArbitrationUpdate delivers a master arbitration update with a given election ID and status on the stream channel
+func sendArbitrationUpdate(t *testing.T, conn *testConn, ch <-chan controller.ID, electionID uint64, statusCode code.Code) {
+	conn.streamCh <- &p4api.StreamMessageResponse{
+		Update: &p4api.StreamMessageResponse_Arbitration{
+			Arbitration: &p4api.MasterArbitrationUpdate{
+				DeviceId:   1,
+				ElectionId: &p4api.Uint128{Low: electionID},
+				Status:     &status.Status{Code: int32(statusCode)},
+			},
+		},
+	}
+	assert.Equal(t, controller.NewID(testTargetID), nextID(t, ch))
+}
+
+func getMastership(t *testing.T, reconciler *Reconciler) *topoapi.P4RTMastershipState {
+	target, err := reconciler.topo.Get(context.Background(), testTargetID)
+	assert.NoError(t, err)
+	mastership := &topoapi.P4RTMastershipState{}
+	_ = target.GetAspect(mastership)
+	return mastership
+}
+
+func TestReconcileArbitrationOK(t *testing.T) {
+	reconciler, conn, ch := newTestReconciler(t)
+	sendArbitrationUpdate(t, conn, ch, 1, code.Code_OK)
+
+	// The local node becomes master in the term of the election ID
+	result, err := reconciler.Reconcile(controller.NewID(testTargetID))
+	assert.NoError(t, err)
+	assert.Equal(t, controller.Result{}, result)
+	mastership := getMastership(t, reconciler)
+	assert.Equal(t, string(testRelationID), mastership.NodeId)
+	assert.Equal(t, uint64(1), mastership.Term)
+
+	// The elected master does not run the arbitration again
+	result, err = reconciler.Reconcile(controller.NewID(testTargetID))
+	assert.NoError(t, err)
+	assert.Equal(t, controller.Result{}, result)
+	assert.Empty(t, conn.electionIDs)
+}
+
+func TestReconcileArbitrationAlreadyExists(t *testing.T) {
+	reconciler, conn, ch := newTestReconciler(t)
+	sendArbitrationUpdate(t, conn, ch, 1, code.Code_ALREADY_EXISTS)
+
+	// Another node is master, so the local node stays a backup
+	result, err := reconciler.Reconcile(controller.NewID(testTargetID))
+	assert.NoError(t, err)
+	assert.Equal(t, controller.Result{}, result)
+	mastership := getMastership(t, reconciler)
+	assert.Empty(t, mastership.NodeId)
+	assert.Empty(t, conn.electionIDs)
+}
+
+func TestReconcileArbitrationNotFound(t *testing.T) {
+	reconciler, conn, ch := newTestReconciler(t)
+	sendArbitrationUpdate(t, conn, ch, 1, code.Code_NOT_FOUND)
+
+	// Without a master, the arbitration is retried
+	id := controller.NewID(testTargetID)
+	result, err := reconciler.Reconcile(id)
+	assert.NoError(t, err)
+	assert.Equal(t, controller.Result{Requeue: id}, result)
+	mastership := getMastership(t, reconciler)
+	assert.Empty(t, mastership.NodeId)
+
+	result, err = reconciler.Reconcile(id)
+	assert.NoError(t, err)
+	assert.Equal(t, controller.Result{}, result)
+	assert.Equal(t, uint64(1), <-conn.electionIDs)
+}
+
+func TestReconcileStaleArbitration(t *testing.T) {
+	reconciler, conn, ch := newTestReconciler(t)
+	sendArbitrationUpdate(t, conn, ch, 0, code.Code_OK)
+
+	// An update older than the requested election ID does not elect the local node, and the arbitration is requested again
+	result, err := reconciler.Reconcile(controller.NewID(testTargetID))
+	assert.NoError(t, err)
+	assert.Equal(t, controller.Result{}, result)
+	mastership := getMastership(t, reconciler)
+	assert.Empty(t, mastership.NodeId)
+	assert.Equal(t, uint64(1), <-conn.electionIDs)
+}
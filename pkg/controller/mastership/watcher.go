@@ -8,7 +8,9 @@ import (
 	"context"
 	"sync"
 
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
 	"github.com/onosproject/wcmp-app/pkg/store/topo"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
 
 	topoapi "github.com/onosproject/onos-api/go/onos/topo"
 	"github.com/onosproject/onos-lib-go/pkg/controller"
@@ -72,3 +74,110 @@ func (w *TopoWatcher) Stop() {
 	}
 	w.mu.Unlock()
 }
+
+// arbitrations holds the last master arbitration update received from each target until it is reconciled
+type arbitrations struct {
+	updates map[topoapi.ID]*p4api.MasterArbitrationUpdate
+	mu      sync.Mutex
+}
+
+func newArbitrations() *arbitrations {
+	return &arbitrations{
+		updates: make(map[topoapi.ID]*p4api.MasterArbitrationUpdate),
+	}
+}
+
+func (a *arbitrations) put(targetID topoapi.ID, update *p4api.MasterArbitrationUpdate) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.updates[targetID] = update
+}
+
+// pop returns and removes the last master arbitration update received from a target in response to the
+// arbitration request for the given election ID, if any. Updates with a lower election ID were sent for
+// previous requests and are discarded: the server always reports the highest election ID it has seen.
+func (a *arbitrations) pop(targetID topoapi.ID, electionID uint64) *p4api.MasterArbitrationUpdate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	update, ok := a.updates[targetID]
+	if !ok {
+		return nil
+	}
+	delete(a.updates, targetID)
+	if update.GetElectionId().GetHigh() == 0 && update.GetElectionId().GetLow() < electionID {
+		log.Infow("Discarding stale master arbitration update", "targetID", targetID, "election ID", update.GetElectionId().GetLow(), "requested election ID", electionID)
+		return nil
+	}
+	return update
+}
+
+// ArbitrationWatcher is a watcher of the master arbitration updates received on the P4RT connections
+type ArbitrationWatcher struct {
+	conns        p4rt.ConnManager
+	arbitrations *arbitrations
+	cancel       context.CancelFunc
+	mu           sync.Mutex
+}
+
+// Start starts the arbitration watcher
+func (w *ArbitrationWatcher) Start(ch chan<- controller.ID) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return nil
+	}
+
+	connCh := make(chan p4rt.Conn, queueSize)
+	ctx, cancel := context.WithCancel(context.Background())
+	err := w.conns.Watch(ctx, connCh)
+	if err != nil {
+		cancel()
+		return err
+	}
+	w.cancel = cancel
+
+	go func() {
+		// Subscribe to the arbitration updates of each connection while it is open
+		subscriptions := make(map[p4rt.ConnID]context.CancelFunc)
+		for conn := range connCh {
+			if _, ok := w.conns.Get(ctx, conn.ID()); !ok {
+				if cancelSubscription, ok := subscriptions[conn.ID()]; ok {
+					cancelSubscription()
+					delete(subscriptions, conn.ID())
+				}
+				continue
+			}
+			if _, ok := subscriptions[conn.ID()]; ok {
+				continue
+			}
+			subscriptionCtx, cancelSubscription := context.WithCancel(ctx)
+			streamCh := make(chan *p4api.StreamMessageResponse, queueSize)
+			if err := conn.Subscribe(subscriptionCtx, streamCh, p4rt.ArbitrationMessage); err != nil {
+				log.Warnw("Failed subscribing to master arbitration updates", "targetID", conn.TargetID(), "error", err)
+				cancelSubscription()
+				continue
+			}
+			subscriptions[conn.ID()] = cancelSubscription
+			go func(targetID topoapi.ID) {
+				for response := range streamCh {
+					log.Infow("Received master arbitration update", "targetID", targetID, "update", response.GetArbitration())
+					w.arbitrations.put(targetID, response.GetArbitration())
+					ch <- controller.NewID(targetID)
+				}
+			}(conn.TargetID())
+			// Run the arbitration once the stream channel of the connection is open
+			ch <- controller.NewID(conn.TargetID())
+		}
+	}()
+	return nil
+}
+
+// Stop stops the arbitration watcher
+func (w *ArbitrationWatcher) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+	w.mu.Unlock()
+}
@@ -0,0 +1,201 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/controller/utils"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// adoptPipelineConfig checks whether the target already runs the intended pipeline and, if so,
+// takes ownership of the forwarding state installed on it instead of pushing the pipeline again
+func (r *Reconciler) adoptPipelineConfig(ctx context.Context, conn p4rt.Conn, deviceID uint64, electionID uint64, pipelineConfig *p4rtapi.PipelineConfig, p4Info *p4configapi.P4Info) (bool, error) {
+	response, err := conn.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{
+		DeviceId:     deviceID,
+		ResponseType: p4api.GetForwardingPipelineConfigRequest_P4INFO_AND_COOKIE,
+	})
+	if err != nil {
+		// Targets without a pipeline report FAILED_PRECONDITION; any other error must not lead to a push
+		// wiping the forwarding state of a target which is only temporarily unreachable.
+		if !errors.IsConflict(err) && !errors.IsNotFound(err) {
+			log.Warnw("Cannot retrieve installed pipeline config from target", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+			return false, err
+		}
+		log.Infow("No pipeline config is installed on target", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+		return false, nil
+	}
+
+	installedConfig := response.GetConfig()
+	if installedConfig.GetP4Info() == nil {
+		log.Infow("No pipeline config is installed on target", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+		return false, nil
+	}
+	if pipelineConfig.Cookie != nil && installedConfig.GetCookie() != nil &&
+		pipelineConfig.Cookie.Cookie != installedConfig.GetCookie().GetCookie() {
+		log.Infow("Installed pipeline config cookie does not match the intended one", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+		return false, nil
+	}
+	if !proto.Equal(installedConfig.GetP4Info(), p4Info) {
+		log.Infow("Installed P4Info does not match the intended one", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+		return false, nil
+	}
+
+	if err := r.adoptForwardingState(ctx, conn, deviceID, electionID, pipelineConfig, p4Info); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// adoption identifies the pipeline and mastership term under which the forwarding state of a target is adopted
+type adoption struct {
+	cookie     uint64
+	electionID uint64
+}
+
+// adoptForwardingState adopts the forwarding state installed on the target once per pipeline and mastership term:
+// entries written since then are written by wcmp-app and already owned
+func (r *Reconciler) adoptForwardingState(ctx context.Context, conn p4rt.Conn, deviceID uint64, electionID uint64, pipelineConfig *p4rtapi.PipelineConfig, p4Info *p4configapi.P4Info) error {
+	current := adoption{cookie: pipelineConfig.Cookie.GetCookie(), electionID: electionID}
+	r.adoptionsMu.Lock()
+	adopted, ok := r.adoptions[pipelineConfig.ID]
+	r.adoptionsMu.Unlock()
+	if ok && adopted == current {
+		log.Debugw("Forwarding state is already adopted", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+		return nil
+	}
+
+	if err := adoptForwardingState(ctx, conn, deviceID, electionID, p4Info); err != nil {
+		return err
+	}
+
+	r.adoptionsMu.Lock()
+	if r.adoptions == nil {
+		r.adoptions = make(map[p4rtapi.PipelineConfigID]adoption)
+	}
+	r.adoptions[pipelineConfig.ID] = current
+	r.adoptionsMu.Unlock()
+	return nil
+}
+
+// adoptForwardingState tags the table entries installed on the target with the wcmp-app ownership cookie.
+// Action profile members and groups carry no controller metadata in P4Runtime: they are adopted with the
+// entries referencing them, either directly or through a group, and the others are left unowned.
+func adoptForwardingState(ctx context.Context, conn p4rt.Conn, deviceID uint64, electionID uint64, p4Info *p4configapi.P4Info) error {
+	constTables := make(map[uint32]bool)
+	for _, table := range p4Info.Tables {
+		if table.IsConstTable {
+			constTables[table.Preamble.Id] = true
+		}
+	}
+
+	entities, err := conn.ReadEntities(ctx, &p4api.ReadRequest{
+		DeviceId: deviceID,
+		Entities: []*p4api.Entity{
+			{
+				Entity: &p4api.Entity_TableEntry{
+					TableEntry: &p4api.TableEntry{},
+				},
+			},
+			{
+				Entity: &p4api.Entity_ActionProfileMember{
+					ActionProfileMember: &p4api.ActionProfileMember{},
+				},
+			},
+			{
+				Entity: &p4api.Entity_ActionProfileGroup{
+					ActionProfileGroup: &p4api.ActionProfileGroup{},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	var updates []*p4api.Update
+	var members []*p4api.ActionProfileMember
+	var groups []*p4api.ActionProfileGroup
+	ownedMembers := make(map[uint32]bool)
+	ownedGroups := make(map[uint32]bool)
+	for _, entity := range entities {
+		if member := entity.GetActionProfileMember(); member != nil {
+			members = append(members, member)
+			continue
+		}
+		if group := entity.GetActionProfileGroup(); group != nil {
+			groups = append(groups, group)
+			continue
+		}
+		tableEntry := entity.GetTableEntry()
+		if tableEntry == nil || constTables[tableEntry.TableId] {
+			continue
+		}
+		switch action := tableEntry.GetAction().GetType().(type) {
+		case *p4api.TableAction_ActionProfileMemberId:
+			ownedMembers[action.ActionProfileMemberId] = true
+		case *p4api.TableAction_ActionProfileGroupId:
+			ownedGroups[action.ActionProfileGroupId] = true
+		}
+		if tableEntry.ControllerMetadata == utils.OwnershipCookie {
+			continue
+		}
+		tableEntry.ControllerMetadata = utils.OwnershipCookie
+		// Counter and meter data are not part of the entry identity and must not be rewritten
+		tableEntry.CounterData = nil
+		tableEntry.MeterCounterData = nil
+		tableEntry.TimeSinceLastHit = nil
+		updates = append(updates, &p4api.Update{
+			Type: p4api.Update_MODIFY,
+			Entity: &p4api.Entity{
+				Entity: &p4api.Entity_TableEntry{
+					TableEntry: tableEntry,
+				},
+			},
+		})
+	}
+
+	adoptedGroups := 0
+	for _, group := range groups {
+		if !ownedGroups[group.GroupId] {
+			continue
+		}
+		adoptedGroups++
+		for _, member := range group.Members {
+			ownedMembers[member.MemberId] = true
+		}
+	}
+	adoptedMembers := 0
+	for _, member := range members {
+		if ownedMembers[member.MemberId] {
+			adoptedMembers++
+		}
+	}
+	if adoptedGroups < len(groups) || adoptedMembers < len(members) {
+		log.Infow("Leaving action profile members and groups unreferenced by table entries unowned", "targetID", conn.TargetID(),
+			"members", len(members)-adoptedMembers, "groups", len(groups)-adoptedGroups)
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	log.Infow("Adopting forwarding state installed on target", "targetID", conn.TargetID(), "entries", len(updates),
+		"members", adoptedMembers, "groups", adoptedGroups)
+	_, err = conn.Write(ctx, &p4api.WriteRequest{
+		DeviceId: deviceID,
+		ElectionId: &p4api.Uint128{
+			Low:  electionID,
+			High: 0,
+		},
+		Updates:   updates,
+		Atomicity: p4api.WriteRequest_CONTINUE_ON_ERROR,
+	})
+	return err
+}
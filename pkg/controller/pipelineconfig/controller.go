@@ -19,6 +19,7 @@ import (
 	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
 	p4api "github.com/p4lang/p4runtime/go/p4/v1"
 	"google.golang.org/protobuf/proto"
+	"sync"
 	"time"
 )
 
@@ -28,8 +29,45 @@ const (
 	defaultTimeout = 30 * time.Second
 )
 
+// configurationActions maps pipeline config actions to P4Runtime SetForwardingPipelineConfig actions
+var configurationActions = map[p4rtapi.ConfigurationAction]p4api.SetForwardingPipelineConfigRequest_Action{
+	p4rtapi.ConfigurationAction_VERIFY:               p4api.SetForwardingPipelineConfigRequest_VERIFY,
+	p4rtapi.ConfigurationAction_VERIFY_AND_SAVE:      p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_SAVE,
+	p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT:    p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_COMMIT,
+	p4rtapi.ConfigurationAction_COMMIT:               p4api.SetForwardingPipelineConfigRequest_COMMIT,
+	p4rtapi.ConfigurationAction_RECONCILE_AND_COMMIT: p4api.SetForwardingPipelineConfigRequest_RECONCILE_AND_COMMIT,
+}
+
+const (
+	pipelineConfigRunningMessage = "pipeline config is running on the target"
+	pipelineConfigAdoptedMessage = "pipeline config installed on the target is adopted and running"
+)
+
+// actionMessages describes the outcome of a successful action. A COMPLETE pipeline config only runs on
+// the target once it is committed.
+var actionMessages = map[p4rtapi.ConfigurationAction]string{
+	p4rtapi.ConfigurationAction_VERIFY:               "pipeline config is verified by the target; it is not running",
+	p4rtapi.ConfigurationAction_VERIFY_AND_SAVE:      "pipeline config is saved on the target; it is not running until committed",
+	p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT:    pipelineConfigRunningMessage,
+	p4rtapi.ConfigurationAction_COMMIT:               pipelineConfigRunningMessage,
+	p4rtapi.ConfigurationAction_RECONCILE_AND_COMMIT: pipelineConfigRunningMessage,
+}
+
+// isCommitAction returns whether the given action realizes the pipeline config on the target
+func isCommitAction(action p4rtapi.ConfigurationAction) bool {
+	return action == p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT ||
+		action == p4rtapi.ConfigurationAction_COMMIT ||
+		action == p4rtapi.ConfigurationAction_RECONCILE_AND_COMMIT
+}
+
+// IsRunning returns whether the given pipeline config runs on its target. Verified and saved
+// pipeline configs are COMPLETE without running.
+func IsRunning(pipelineConfig *p4rtapi.PipelineConfig) bool {
+	return isCommitAction(pipelineConfig.Action) && pipelineConfig.Status.State == p4rtapi.PipelineConfigStatus_COMPLETE
+}
+
 // NewController returns a new device pipeline pipelineconfig controller
-func NewController(topo topo.Store, conns p4rt.ConnManager, p4PluginRegistry pluginregistry.P4PluginRegistry, pipelineConfigStore pipelineConfigStore.Store) *controller.Controller {
+func NewController(topo topo.Store, conns p4rt.ConnManager, p4PluginRegistry pluginregistry.P4PluginRegistry, pipelineConfigStore pipelineConfigStore.Store, warmRestart bool, retryPolicy RetryPolicy) *controller.Controller {
 	c := controller.NewController("pipelineconfig")
 	c.Watch(&TopoWatcher{
 		topo: topo,
@@ -42,6 +80,8 @@ func NewController(topo topo.Store, conns p4rt.ConnManager, p4PluginRegistry plu
 		topo:                topo,
 		p4PluginRegistry:    p4PluginRegistry,
 		pipelineConfigStore: pipelineConfigStore,
+		warmRestart:         warmRestart,
+		retryPolicy:         retryPolicy,
 	})
 	return c
 }
@@ -52,6 +92,12 @@ type Reconciler struct {
 	topo                topo.Store
 	p4PluginRegistry    pluginregistry.P4PluginRegistry
 	pipelineConfigStore pipelineConfigStore.Store
+	// warmRestart enables adopting the pipeline and forwarding state already installed on targets
+	warmRestart bool
+	retryPolicy RetryPolicy
+	// adoptions records the forwarding state adopted for each pipeline config
+	adoptions   map[p4rtapi.PipelineConfigID]adoption
+	adoptionsMu sync.Mutex
 }
 
 // Reconcile reconciles pipeline configuration
@@ -78,16 +124,20 @@ func (r *Reconciler) Reconcile(id controller.ID) (controller.Result, error) {
 
 	log.Infow("Reconciling Device Pipeline Config", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
 
-	switch pipelineConfig.Action {
-	case p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT:
-		log.Infow("Reconciling device pipeline config for VERIFY_AND_COMMIT action", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
-		return r.reconcileVerifyAndCommitAction(ctx, pipelineConfig)
+	if pipelineConfig.Action == p4rtapi.ConfigurationAction_UNSPECIFIED {
+		log.Debugw("Device pipeline config is inactive", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+		return controller.Result{}, nil
 	}
-
-	return controller.Result{}, nil
-
+	action, ok := configurationActions[pipelineConfig.Action]
+	if !ok {
+		log.Warnw("Unsupported device pipeline config action", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "action", pipelineConfig.Action)
+		return controller.Result{}, nil
+	}
+	log.Infow("Reconciling device pipeline config for action", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "action", pipelineConfig.Action)
+	return r.reconcileAction(ctx, pipelineConfig, action)
 }
-func (r *Reconciler) reconcileVerifyAndCommitAction(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) (controller.Result, error) {
+
+func (r *Reconciler) reconcileAction(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig, action p4api.SetForwardingPipelineConfigRequest_Action) (controller.Result, error) {
 	targetID := topoapi.ID(pipelineConfig.TargetID)
 	target, err := r.topo.Get(ctx, targetID)
 	if err != nil {
@@ -95,6 +145,10 @@ func (r *Reconciler) reconcileVerifyAndCommitAction(ctx context.Context, pipelin
 			log.Errorw("Failed Reconciling device pipeline config", "pipelineConfig ID", pipelineConfig.ID, "targetID", targetID, "error", err)
 			return controller.Result{}, err
 		}
+		// The garbage collector relies on the time the config became UNKNOWN, so it is not updated again.
+		if pipelineConfig.Status.State == p4rtapi.PipelineConfigStatus_UNKNOWN {
+			return controller.Result{}, nil
+		}
 		pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_UNKNOWN
 		pipelineConfig.Status.Mastership.Master = ""
 		pipelineConfig.Status.Mastership.Term = 0
@@ -123,14 +177,24 @@ func (r *Reconciler) reconcileVerifyAndCommitAction(ctx context.Context, pipelin
 		if err := r.updateConfigurationStatus(ctx, pipelineConfig); err != nil {
 			return controller.Result{}, err
 		}
+		r.updateStatusDetails(ctx, pipelineConfig, &pipelineConfigStore.StatusDetails{})
 		return controller.Result{}, nil
 	}
 
-	if pipelineConfig.Status.State != p4rtapi.PipelineConfigStatus_PENDING {
+	if pipelineConfig.Status.State != p4rtapi.PipelineConfigStatus_PENDING &&
+		pipelineConfig.Status.State != p4rtapi.PipelineConfigStatus_FAILED {
 		log.Warnw("Failed reconciling device pipeline config", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "state", pipelineConfig.Status.State)
 		return controller.Result{}, nil
 	}
 
+	// Only an operator moves a pipeline config whose retries are exhausted back to PENDING,
+	// so the retries start over.
+	details := r.getStatusDetails(ctx, pipelineConfig)
+	if pipelineConfig.Status.State == p4rtapi.PipelineConfigStatus_PENDING && details.Attempts > 0 && r.retryPolicy.exhausted(details) {
+		details = &pipelineConfigStore.StatusDetails{}
+		r.updateStatusDetails(ctx, pipelineConfig, details)
+	}
+
 	// If the master node ID is not set, skip reconciliation.
 	if mastership.NodeId == "" {
 		log.Infow("No master for target", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
@@ -165,6 +229,10 @@ func (r *Reconciler) reconcileVerifyAndCommitAction(ctx context.Context, pipelin
 		return controller.Result{}, nil
 	}
 
+	if pipelineConfig.Status.State == p4rtapi.PipelineConfigStatus_FAILED {
+		return r.retryPipelineConfig(ctx, pipelineConfig, details)
+	}
+
 	p4InfoBytes := pipelineConfig.Spec.P4Info
 	p4DeviceConfig := pipelineConfig.Spec.P4DeviceConfig
 
@@ -175,39 +243,169 @@ func (r *Reconciler) reconcileVerifyAndCommitAction(ctx context.Context, pipelin
 		return controller.Result{}, err
 	}
 
+	cookie := pipelineConfig.Cookie
+	if cookie == nil {
+		cookie = pipelineConfigStore.NewPipelineConfigCookie(pipelineConfig.Spec)
+	}
+
+	// Verifying or saving a config does not modify the forwarding state, so the installed
+	// pipeline is only checked for actions that realize the config on the target.
+	commit := isCommitAction(pipelineConfig.Action)
+	installed := false
+	if commit {
+		installed, err = r.isPipelineConfigInstalled(ctx, conn, p4rtServerInfo.DeviceID, cookie)
+		if err != nil {
+			log.Warnw("Failed retrieving pipeline config cookie from target", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+		}
+	}
+	if installed {
+		if r.warmRestart {
+			if err := r.adoptForwardingState(ctx, conn, p4rtServerInfo.DeviceID, mastership.Term, pipelineConfig, p4Info); err != nil {
+				log.Warnw("Failed adopting forwarding state", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+				return controller.Result{}, err
+			}
+		}
+		log.Infow("Target already runs the device pipeline config; skipping push", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+		return r.completePipelineConfig(ctx, pipelineConfig, mastership, pipelineConfigRunningMessage)
+	}
+
+	if commit && r.warmRestart {
+		adopted, err := r.adoptPipelineConfig(ctx, conn, p4rtServerInfo.DeviceID, mastership.Term, pipelineConfig, p4Info)
+		if err != nil {
+			log.Warnw("Failed adopting device pipeline config", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+			return controller.Result{}, err
+		}
+		if adopted {
+			log.Infow("Device pipelineConfig is adopted from target", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+			return r.completePipelineConfig(ctx, pipelineConfig, mastership, pipelineConfigAdoptedMessage)
+		}
+	}
+
 	config := &p4api.ForwardingPipelineConfig{
 		P4Info:         p4Info,
 		P4DeviceConfig: p4DeviceConfig,
+		Cookie: &p4api.ForwardingPipelineConfig_Cookie{
+			Cookie: cookie.Cookie,
+		},
 	}
-
-	_, err = conn.SetForwardingPipelineConfig(ctx, &p4api.SetForwardingPipelineConfigRequest{
+	request := &p4api.SetForwardingPipelineConfigRequest{
 		DeviceId: p4rtServerInfo.DeviceID,
 		ElectionId: &p4api.Uint128{
 			Low:  mastership.Term,
 			High: 0,
 		},
 		Config: config,
-		Action: p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_COMMIT,
-	})
+		Action: action,
+	}
+	// COMMIT realizes the last saved config, so the request must not carry a config.
+	if action == p4api.SetForwardingPipelineConfigRequest_COMMIT {
+		request.Config = nil
+	}
+
+	_, err = conn.SetForwardingPipelineConfig(ctx, request)
+	// The saved config does not survive a target restart and is not known to a target reached through
+	// a new master, in which case the config is verified and committed again from the store.
+	if err != nil && action == p4api.SetForwardingPipelineConfigRequest_COMMIT && errors.IsConflict(err) {
+		log.Infow("No saved pipeline config on target; committing the stored one", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+		_, err = conn.SetForwardingPipelineConfig(ctx, &p4api.SetForwardingPipelineConfigRequest{
+			DeviceId:   request.DeviceId,
+			ElectionId: request.ElectionId,
+			Config:     config,
+			Action:     p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_COMMIT,
+		})
+	}
 
 	if err != nil {
 		log.Errorw("Failed Reconciling device pipeline config", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+		details.Attempts++
+		details.LastError = err.Error()
+		details.LastAttempt = time.Now()
+		details.Message = "pipeline config failed: " + err.Error()
 		pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_FAILED
 		if err := r.updateConfigurationStatus(ctx, pipelineConfig); err != nil {
 			return controller.Result{}, err
 		}
-		return controller.Result{}, nil
+		r.updateStatusDetails(ctx, pipelineConfig, details)
+		if r.retryPolicy.exhausted(details) {
+			log.Warnw("No attempts left for device pipeline config", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "attempts", details.Attempts)
+			return controller.Result{}, nil
+		}
+		return controller.Result{
+			RequeueAfter: r.retryPolicy.retryAfter(details),
+		}, nil
 	}
+	log.Infow("Device pipelineConfig is Set Successfully", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "action", action)
+	return r.completePipelineConfig(ctx, pipelineConfig, mastership, actionMessages[pipelineConfig.Action])
+}
+
+// isPipelineConfigInstalled checks whether the pipeline config cookie installed on the target matches the given one
+func (r *Reconciler) isPipelineConfigInstalled(ctx context.Context, conn p4rt.Conn, deviceID uint64, cookie *p4rtapi.Cookie) (bool, error) {
+	response, err := conn.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{
+		DeviceId:     deviceID,
+		ResponseType: p4api.GetForwardingPipelineConfigRequest_COOKIE_ONLY,
+	})
+	if err != nil {
+		return false, err
+	}
+	installedCookie := response.GetConfig().GetCookie()
+	if installedCookie == nil {
+		return false, nil
+	}
+	return installedCookie.Cookie == cookie.Cookie, nil
+}
+
+// retryPipelineConfig moves a failed pipeline config back to PENDING once its backoff delay has elapsed
+func (r *Reconciler) retryPipelineConfig(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig, details *pipelineConfigStore.StatusDetails) (controller.Result, error) {
+	if details.Attempts > 0 {
+		if r.retryPolicy.exhausted(details) {
+			log.Debugw("No attempts left for device pipeline config", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "attempts", details.Attempts, "error", details.LastError)
+			return controller.Result{}, nil
+		}
+		if retryAfter := r.retryPolicy.retryAfter(details); retryAfter > 0 {
+			return controller.Result{
+				RequeueAfter: retryAfter,
+			}, nil
+		}
+		log.Infow("Retrying device pipeline config", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "attempts", details.Attempts, "error", details.LastError)
+	}
+	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	if err := r.updateConfigurationStatus(ctx, pipelineConfig); err != nil {
+		return controller.Result{}, err
+	}
+	return controller.Result{}, nil
+}
+
+func (r *Reconciler) completePipelineConfig(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig, mastership topoapi.P4RTMastershipState, message string) (controller.Result, error) {
 	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
 	pipelineConfig.Status.Mastership.Master = mastership.NodeId
-	pipelineConfig.Status.Mastership.Term = mastershipTerm
+	pipelineConfig.Status.Mastership.Term = p4rtapi.MastershipTerm(mastership.Term)
 	if err := r.updateConfigurationStatus(ctx, pipelineConfig); err != nil {
 		return controller.Result{}, err
 	}
-	log.Infow("Device pipelineConfig is Set Successfully", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+	r.updateStatusDetails(ctx, pipelineConfig, &pipelineConfigStore.StatusDetails{
+		Message: message,
+	})
 	return controller.Result{}, nil
 }
 
+// getStatusDetails returns the stored status details of a pipeline config, or empty details if they cannot be read
+func (r *Reconciler) getStatusDetails(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) *pipelineConfigStore.StatusDetails {
+	details, err := r.pipelineConfigStore.GetStatusDetails(ctx, pipelineConfig.ID)
+	if err != nil {
+		log.Warnw("Failed retrieving pipeline configuration status details", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+		return &pipelineConfigStore.StatusDetails{}
+	}
+	return details
+}
+
+// updateStatusDetails records the outcome and the retry state of the last action. Failing to store them
+// does not fail the reconciliation; a config whose attempts are lost is retried from the first attempt.
+func (r *Reconciler) updateStatusDetails(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig, details *pipelineConfigStore.StatusDetails) {
+	if err := r.pipelineConfigStore.UpdateStatusDetails(ctx, pipelineConfig.ID, details); err != nil {
+		log.Warnw("Failed updating pipeline configuration status details", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+	}
+}
+
 func (r *Reconciler) updateConfigurationStatus(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error {
 	log.Debug(pipelineConfig.Status)
 	err := r.pipelineConfigStore.UpdateStatus(ctx, pipelineConfig)
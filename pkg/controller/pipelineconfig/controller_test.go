@@ -0,0 +1,459 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/controller"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/controller/utils"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	pipelineConfigStore "github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	testTargetID   = "target-1"
+	testRelationID = "relation-1"
+	testDeviceID   = 1
+)
+
+// testConn is a P4Runtime connection to a target which installs the pipeline configs it is given
+type testConn struct {
+	p4rt.Conn
+	installed *p4api.ForwardingPipelineConfig
+	saved     *p4api.ForwardingPipelineConfig
+	getErr    error
+	setErr    error
+	entries   []*p4api.TableEntry
+	members   []*p4api.ActionProfileMember
+	groups    []*p4api.ActionProfileGroup
+	reads     int
+	requests  []*p4api.SetForwardingPipelineConfigRequest
+	writes    []*p4api.WriteRequest
+}
+
+func (c *testConn) ID() p4rt.ConnID {
+	return testRelationID
+}
+
+func (c *testConn) TargetID() topoapi.ID {
+	return testTargetID
+}
+
+func (c *testConn) GetForwardingPipelineConfig(ctx context.Context, request *p4api.GetForwardingPipelineConfigRequest, opts ...grpc.CallOption) (*p4api.GetForwardingPipelineConfigResponse, error) {
+	if c.getErr != nil {
+		return nil, c.getErr
+	}
+	if c.installed == nil {
+		return nil, errors.NewConflict("no forwarding pipeline config is set")
+	}
+	config := &p4api.ForwardingPipelineConfig{
+		Cookie: c.installed.Cookie,
+	}
+	if request.ResponseType != p4api.GetForwardingPipelineConfigRequest_COOKIE_ONLY {
+		config.P4Info = c.installed.P4Info
+	}
+	return &p4api.GetForwardingPipelineConfigResponse{Config: config}, nil
+}
+
+func (c *testConn) SetForwardingPipelineConfig(ctx context.Context, request *p4api.SetForwardingPipelineConfigRequest, opts ...grpc.CallOption) (*p4api.SetForwardingPipelineConfigResponse, error) {
+	c.requests = append(c.requests, request)
+	if c.setErr != nil {
+		return nil, c.setErr
+	}
+	switch request.Action {
+	case p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_SAVE:
+		c.saved = request.Config
+	case p4api.SetForwardingPipelineConfigRequest_COMMIT:
+		if c.saved == nil {
+			return nil, errors.NewConflict("no forwarding pipeline config is saved")
+		}
+		c.installed = c.saved
+		c.saved = nil
+	case p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_COMMIT, p4api.SetForwardingPipelineConfigRequest_RECONCILE_AND_COMMIT:
+		c.installed = request.Config
+		c.saved = nil
+	}
+	return &p4api.SetForwardingPipelineConfigResponse{}, nil
+}
+
+func (c *testConn) ReadEntities(ctx context.Context, request *p4api.ReadRequest, opts ...grpc.CallOption) ([]*p4api.Entity, error) {
+	c.reads++
+	entities := make([]*p4api.Entity, 0, len(c.entries)+len(c.members)+len(c.groups))
+	for _, entry := range c.entries {
+		entities = append(entities, &p4api.Entity{
+			Entity: &p4api.Entity_TableEntry{TableEntry: proto.Clone(entry).(*p4api.TableEntry)},
+		})
+	}
+	for _, member := range c.members {
+		entities = append(entities, &p4api.Entity{
+			Entity: &p4api.Entity_ActionProfileMember{ActionProfileMember: member},
+		})
+	}
+	for _, group := range c.groups {
+		entities = append(entities, &p4api.Entity{
+			Entity: &p4api.Entity_ActionProfileGroup{ActionProfileGroup: group},
+		})
+	}
+	return entities, nil
+}
+
+func (c *testConn) Write(ctx context.Context, request *p4api.WriteRequest, opts ...grpc.CallOption) (*p4api.WriteResponse, error) {
+	c.writes = append(c.writes, request)
+	return &p4api.WriteResponse{}, nil
+}
+
+type testConnManager struct {
+	p4rt.ConnManager
+	conn *testConn
+}
+
+func (m *testConnManager) Get(ctx context.Context, connID p4rt.ConnID) (p4rt.Conn, bool) {
+	if m.conn == nil || connID != m.conn.ID() {
+		return nil, false
+	}
+	return m.conn, true
+}
+
+func newTestP4Info() *p4configapi.P4Info {
+	return &p4configapi.P4Info{
+		PkgInfo: &p4configapi.PkgInfo{Name: "wcmp", Version: "1.0.0", Arch: "v1model"},
+		Tables: []*p4configapi.Table{
+			{Preamble: &p4configapi.Preamble{Id: 1, Name: "ingress.routing"}},
+			{Preamble: &p4configapi.Preamble{Id: 2, Name: "ingress.ports"}, IsConstTable: true},
+		},
+	}
+}
+
+// newTestTopo returns a topo with a target mastered by this controller for the given term
+func newTestTopo(t *testing.T, term uint64) *topotest.Store {
+	target := &topoapi.Object{
+		ID:   testTargetID,
+		Type: topoapi.Object_ENTITY,
+		Obj:  &topoapi.Object_Entity{Entity: &topoapi.Entity{}},
+	}
+	assert.NoError(t, target.SetAspect(&topoapi.P4RTServerInfo{
+		DeviceID:  testDeviceID,
+		Pipelines: []*topoapi.P4PipelineInfo{{Name: "wcmp", Version: "1.0.0", Architecture: "v1model"}},
+	}))
+	assert.NoError(t, target.SetAspect(&topoapi.P4RTMastershipState{NodeId: testRelationID, Term: term}))
+	relation := &topoapi.Object{
+		ID:   testRelationID,
+		Type: topoapi.Object_RELATION,
+		Obj: &topoapi.Object_Relation{Relation: &topoapi.Relation{
+			KindID:      topoapi.CONTROLS,
+			SrcEntityID: utils.GetControllerID(),
+			TgtEntityID: testTargetID,
+		}},
+	}
+	return topotest.NewStore(target, relation)
+}
+
+// newTestPipelineConfig creates a pending pipeline config of the test target with the given action
+func newTestPipelineConfig(t *testing.T, pipelineConfigs pipelineConfigStore.Store, action p4rtapi.ConfigurationAction) *p4rtapi.PipelineConfig {
+	p4Info, err := proto.Marshal(newTestP4Info())
+	assert.NoError(t, err)
+	spec := &p4rtapi.PipelineConfigSpec{P4Info: p4Info, P4DeviceConfig: []byte("{}")}
+	pipelineConfig := &p4rtapi.PipelineConfig{
+		ID:       pipelineConfigStore.NewPipelineConfigID(testTargetID, "wcmp", "1.0.0", "v1model"),
+		TargetID: testTargetID,
+		Spec:     spec,
+		Cookie:   pipelineConfigStore.NewPipelineConfigCookie(spec),
+		Action:   action,
+		Status: p4rtapi.PipelineConfigStatus{
+			State: p4rtapi.PipelineConfigStatus_PENDING,
+		},
+	}
+	assert.NoError(t, pipelineConfigs.Create(context.Background(), pipelineConfig))
+	return pipelineConfig
+}
+
+func newTestReconciler(topo *topotest.Store, conn *testConn, pipelineConfigs pipelineConfigStore.Store, warmRestart bool) *Reconciler {
+	return &Reconciler{
+		conns:               &testConnManager{conn: conn},
+		topo:                topo,
+		pipelineConfigStore: pipelineConfigs,
+		warmRestart:         warmRestart,
+		retryPolicy:         DefaultRetryPolicy,
+	}
+}
+
+// reconcile reconciles a pipeline config and returns its updated state
+func reconcile(t *testing.T, reconciler *Reconciler, id p4rtapi.PipelineConfigID) (*p4rtapi.PipelineConfig, controller.Result, error) {
+	result, err := reconciler.Reconcile(controller.NewID(id))
+	pipelineConfig, getErr := reconciler.pipelineConfigStore.Get(context.Background(), id)
+	assert.NoError(t, getErr)
+	return pipelineConfig, result, err
+}
+
+func TestReconcileWarmRestart(t *testing.T) {
+	// The mastership term is set on the pending config so that it is pushed right away
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT)
+	pipelineConfig.Status.Mastership.Term = 1
+	assert.NoError(t, pipelineConfigs.UpdateStatus(context.Background(), pipelineConfig))
+
+	// The target already runs the intended pipeline, with entries installed by a previous instance
+	conn := &testConn{
+		installed: &p4api.ForwardingPipelineConfig{
+			P4Info: newTestP4Info(),
+			Cookie: &p4api.ForwardingPipelineConfig_Cookie{Cookie: pipelineConfig.Cookie.Cookie},
+		},
+		entries: []*p4api.TableEntry{
+			{TableId: 1, Priority: 10, CounterData: &p4api.CounterData{PacketCount: 5}},
+			{TableId: 2, Priority: 10},
+			{TableId: 1, Priority: 20, ControllerMetadata: utils.OwnershipCookie},
+		},
+	}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, true)
+
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, pipelineConfig.Status.State)
+	assert.Empty(t, conn.requests)
+
+	// Only the entries of non-const tables which are not owned yet are adopted
+	assert.Len(t, conn.writes, 1)
+	updates := conn.writes[0].Updates
+	assert.Len(t, updates, 1)
+	assert.Equal(t, p4api.Update_MODIFY, updates[0].Type)
+	entry := updates[0].Entity.GetTableEntry()
+	assert.Equal(t, int32(10), entry.Priority)
+	assert.Equal(t, utils.OwnershipCookie, entry.ControllerMetadata)
+	assert.Nil(t, entry.CounterData)
+}
+
+func TestAdoptForwardingState(t *testing.T) {
+	ctx := context.Background()
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT)
+	conn := &testConn{
+		entries: []*p4api.TableEntry{
+			{TableId: 1, Priority: 10, Action: &p4api.TableAction{Type: &p4api.TableAction_ActionProfileGroupId{ActionProfileGroupId: 1}}},
+			{TableId: 1, Priority: 20, Action: &p4api.TableAction{Type: &p4api.TableAction_ActionProfileMemberId{ActionProfileMemberId: 3}}},
+		},
+		members: []*p4api.ActionProfileMember{{MemberId: 1}, {MemberId: 2}, {MemberId: 3}, {MemberId: 4}},
+		groups: []*p4api.ActionProfileGroup{
+			{GroupId: 1, Members: []*p4api.ActionProfileGroup_Member{{MemberId: 1}, {MemberId: 2}}},
+			{GroupId: 2, Members: []*p4api.ActionProfileGroup_Member{{MemberId: 4}}},
+		},
+	}
+	reconciler := newTestReconciler(newTestTopo(t, 1), conn, pipelineConfigs, true)
+
+	// Members and groups are read with the table entries, and only the entries are tagged
+	assert.NoError(t, reconciler.adoptForwardingState(ctx, conn, 1, 1, pipelineConfig, newTestP4Info()))
+	assert.Equal(t, 1, conn.reads)
+	assert.Len(t, conn.writes, 1)
+	assert.Len(t, conn.writes[0].Updates, 2)
+
+	// The forwarding state is adopted once per mastership term
+	assert.NoError(t, reconciler.adoptForwardingState(ctx, conn, 1, 1, pipelineConfig, newTestP4Info()))
+	assert.Equal(t, 1, conn.reads)
+	assert.NoError(t, reconciler.adoptForwardingState(ctx, conn, 1, 2, pipelineConfig, newTestP4Info()))
+	assert.Equal(t, 2, conn.reads)
+	assert.Len(t, conn.writes, 2)
+}
+
+func TestReconcileWarmRestartAdoptsUnknownCookie(t *testing.T) {
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT)
+	pipelineConfig.Status.Mastership.Term = 1
+	assert.NoError(t, pipelineConfigs.UpdateStatus(context.Background(), pipelineConfig))
+
+	// Pipelines installed without a cookie are adopted when their P4Info is the intended one
+	conn := &testConn{
+		installed: &p4api.ForwardingPipelineConfig{P4Info: newTestP4Info()},
+		entries:   []*p4api.TableEntry{{TableId: 1}},
+	}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, true)
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, pipelineConfig.Status.State)
+	assert.Empty(t, conn.requests)
+	assert.Len(t, conn.writes, 1)
+
+	// Other pipelines are replaced
+	otherP4Info := newTestP4Info()
+	otherP4Info.PkgInfo.Version = "0.9.0"
+	conn = &testConn{
+		installed: &p4api.ForwardingPipelineConfig{P4Info: otherP4Info},
+	}
+	reconciler = newTestReconciler(topo, conn, pipelineConfigs, true)
+	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	assert.NoError(t, pipelineConfigs.UpdateStatus(context.Background(), pipelineConfig))
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, pipelineConfig.Status.State)
+	assert.Len(t, conn.requests, 1)
+	assert.Equal(t, p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_COMMIT, conn.requests[0].Action)
+	assert.Empty(t, conn.writes)
+}
+
+func TestReconcileWarmRestartNoPipeline(t *testing.T) {
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT)
+	pipelineConfig.Status.Mastership.Term = 1
+	assert.NoError(t, pipelineConfigs.UpdateStatus(context.Background(), pipelineConfig))
+
+	// Targets reporting that no pipeline is installed get the pipeline pushed
+	conn := &testConn{}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, true)
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, pipelineConfig.Status.State)
+	assert.Len(t, conn.requests, 1)
+	assert.Equal(t, pipelineConfig.Cookie.Cookie, conn.installed.Cookie.Cookie)
+}
+
+func TestReconcileWarmRestartUnreachableTarget(t *testing.T) {
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT)
+	pipelineConfig.Status.Mastership.Term = 1
+	assert.NoError(t, pipelineConfigs.UpdateStatus(context.Background(), pipelineConfig))
+
+	// Failing to read the installed pipeline must not wipe the forwarding state with a push
+	conn := &testConn{getErr: errors.NewUnavailable("target is unreachable")}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, true)
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.True(t, errors.IsUnavailable(err))
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_PENDING, pipelineConfig.Status.State)
+	assert.Empty(t, conn.requests)
+
+	// Without warm restart the pipeline is pushed as before
+	reconciler = newTestReconciler(topo, conn, pipelineConfigs, false)
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, pipelineConfig.Status.State)
+	assert.Len(t, conn.requests, 1)
+}
+
+// newTestMasteredPipelineConfig creates a pending pipeline config already bound to the mastership term of the test topo
+func newTestMasteredPipelineConfig(t *testing.T, pipelineConfigs pipelineConfigStore.Store, action p4rtapi.ConfigurationAction) *p4rtapi.PipelineConfig {
+	pipelineConfig := newTestPipelineConfig(t, pipelineConfigs, action)
+	pipelineConfig.Status.Mastership.Term = 1
+	assert.NoError(t, pipelineConfigs.UpdateStatus(context.Background(), pipelineConfig))
+	return pipelineConfig
+}
+
+func getStatusMessage(t *testing.T, pipelineConfigs pipelineConfigStore.Store, id p4rtapi.PipelineConfigID) string {
+	details, err := pipelineConfigs.GetStatusDetails(context.Background(), id)
+	assert.NoError(t, err)
+	return details.Message
+}
+
+func TestReconcileVerify(t *testing.T) {
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestMasteredPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY)
+
+	conn := &testConn{}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, false)
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, pipelineConfig.Status.State)
+	assert.False(t, IsRunning(pipelineConfig))
+	assert.Equal(t, actionMessages[p4rtapi.ConfigurationAction_VERIFY], getStatusMessage(t, pipelineConfigs, pipelineConfig.ID))
+	assert.Len(t, conn.requests, 1)
+	assert.Equal(t, p4api.SetForwardingPipelineConfigRequest_VERIFY, conn.requests[0].Action)
+	assert.Nil(t, conn.installed)
+}
+
+func TestReconcileSaveAndCommit(t *testing.T) {
+	ctx := context.Background()
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestMasteredPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY)
+
+	conn := &testConn{}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, false)
+
+	// Configs which are not saved yet cannot be committed
+	assert.True(t, errors.IsConflict(CommitPipelineConfigs(ctx, pipelineConfigs, pipelineConfig.ID)))
+
+	assert.NoError(t, SavePipelineConfigs(ctx, pipelineConfigs, pipelineConfig.ID))
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, pipelineConfig.Status.State)
+	assert.False(t, IsRunning(pipelineConfig))
+	assert.Equal(t, actionMessages[p4rtapi.ConfigurationAction_VERIFY_AND_SAVE], getStatusMessage(t, pipelineConfigs, pipelineConfig.ID))
+	assert.NotNil(t, conn.saved)
+	assert.Nil(t, conn.installed)
+
+	assert.NoError(t, CommitPipelineConfigs(ctx, pipelineConfigs, pipelineConfig.ID))
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.True(t, IsRunning(pipelineConfig))
+	assert.Equal(t, pipelineConfigRunningMessage, getStatusMessage(t, pipelineConfigs, pipelineConfig.ID))
+	assert.Len(t, conn.requests, 2)
+	assert.Equal(t, p4api.SetForwardingPipelineConfigRequest_COMMIT, conn.requests[1].Action)
+	assert.Nil(t, conn.requests[1].Config)
+	assert.Equal(t, pipelineConfig.Cookie.Cookie, conn.installed.Cookie.Cookie)
+}
+
+func TestReconcileCommitAfterMastershipChange(t *testing.T) {
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestMasteredPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_COMMIT)
+
+	// The new master moves the config back to PENDING and the target has no saved config
+	topo := newTestTopo(t, 2)
+	conn := &testConn{}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, false)
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_PENDING, pipelineConfig.Status.State)
+	assert.Equal(t, p4rtapi.MastershipTerm(2), pipelineConfig.Status.Mastership.Term)
+
+	// The stored config is committed instead of the missing saved one
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.True(t, IsRunning(pipelineConfig))
+	assert.Len(t, conn.requests, 2)
+	assert.Equal(t, p4api.SetForwardingPipelineConfigRequest_COMMIT, conn.requests[0].Action)
+	assert.Equal(t, p4api.SetForwardingPipelineConfigRequest_VERIFY_AND_COMMIT, conn.requests[1].Action)
+	assert.Equal(t, uint64(2), conn.requests[1].ElectionId.Low)
+	assert.Equal(t, pipelineConfig.Cookie.Cookie, conn.installed.Cookie.Cookie)
+
+	// A config which is already running is not committed again
+	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	assert.NoError(t, pipelineConfigs.UpdateStatus(context.Background(), pipelineConfig))
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.True(t, IsRunning(pipelineConfig))
+	assert.Len(t, conn.requests, 2)
+}
+
+func TestReconcileReconcileAndCommit(t *testing.T) {
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestMasteredPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_RECONCILE_AND_COMMIT)
+
+	otherP4Info := newTestP4Info()
+	otherP4Info.PkgInfo.Version = "0.9.0"
+	conn := &testConn{
+		installed: &p4api.ForwardingPipelineConfig{P4Info: otherP4Info},
+	}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, false)
+	pipelineConfig, _, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.True(t, IsRunning(pipelineConfig))
+	assert.Len(t, conn.requests, 1)
+	assert.Equal(t, p4api.SetForwardingPipelineConfigRequest_RECONCILE_AND_COMMIT, conn.requests[0].Action)
+	assert.NotNil(t, conn.requests[0].Config)
+	assert.Equal(t, pipelineConfig.Cookie.Cookie, conn.installed.Cookie.Cookie)
+}
@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/controller"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineConfigStore "github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo"
+	"sync"
+	"time"
+)
+
+// NewGarbageCollector returns a new controller deleting the pipeline configs of targets removed from topo
+func NewGarbageCollector(topo topo.Store, pipelineConfigStore pipelineConfigStore.Store, gracePeriod time.Duration) *controller.Controller {
+	removed := newRemovedTargets()
+	c := controller.NewController("pipelineconfig-gc")
+	c.Watch(&RemovedTargetWatcher{
+		topo:            topo,
+		pipelineConfigs: pipelineConfigStore,
+		removed:         removed,
+	})
+	c.Watch(&Watcher{
+		pipelineConfigs: pipelineConfigStore,
+	})
+	c.Reconcile(&GarbageCollector{
+		topo:                topo,
+		pipelineConfigStore: pipelineConfigStore,
+		gracePeriod:         gracePeriod,
+		removed:             removed,
+	})
+	return c
+}
+
+// removedTargets records when targets were found missing from topo
+type removedTargets struct {
+	targets map[p4rtapi.TargetID]time.Time
+	mu      sync.Mutex
+}
+
+func newRemovedTargets() *removedTargets {
+	return &removedTargets{
+		targets: make(map[p4rtapi.TargetID]time.Time),
+	}
+}
+
+// set records the removal time of the given target
+func (r *removedTargets) set(targetID p4rtapi.TargetID, removed time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.targets[targetID] = removed
+}
+
+// add records the removal time of the given target unless it is already known and returns the recorded time
+func (r *removedTargets) add(targetID p4rtapi.TargetID, removed time.Time) time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if t, ok := r.targets[targetID]; ok {
+		return t
+	}
+	r.targets[targetID] = removed
+	return removed
+}
+
+// remove forgets the removal time of the given target
+func (r *removedTargets) remove(targetID p4rtapi.TargetID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.targets, targetID)
+}
+
+// GarbageCollector deletes the pipeline configs whose target no longer exists in topo once the grace period has elapsed
+type GarbageCollector struct {
+	topo                topo.Store
+	pipelineConfigStore pipelineConfigStore.Store
+	gracePeriod         time.Duration
+	removed             *removedTargets
+}
+
+// Reconcile deletes a pipeline config if its target has been missing for longer than the grace period
+func (r *GarbageCollector) Reconcile(id controller.ID) (controller.Result, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultTimeout)
+	defer cancel()
+
+	pipelineConfigID := id.Value.(p4rtapi.PipelineConfigID)
+	pipelineConfig, err := r.pipelineConfigStore.Get(ctx, pipelineConfigID)
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Warnw("Failed collecting pipeline configuration", "pipelineConfig ID", pipelineConfigID, "error", err)
+			return controller.Result{}, err
+		}
+		return controller.Result{}, nil
+	}
+
+	_, err = r.topo.Get(ctx, topoapi.ID(pipelineConfig.TargetID))
+	if err == nil {
+		r.removed.remove(pipelineConfig.TargetID)
+		return controller.Result{}, nil
+	}
+	if !errors.IsNotFound(err) {
+		log.Warnw("Failed collecting pipeline configuration", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+		return controller.Result{}, err
+	}
+
+	// The grace period starts when the target was removed from topo. If the removal event was missed
+	// (e.g. it happened before a restart), it starts when the target is first found missing.
+	removed := r.removed.add(pipelineConfig.TargetID, time.Now())
+
+	// The pipeline config reconciler only handles active configs, so inactive ones are moved to UNKNOWN here.
+	if pipelineConfig.Status.State != p4rtapi.PipelineConfigStatus_UNKNOWN {
+		log.Infow("Target not found; Pipeline Configuration state is changing to UNKNOWN", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+		pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_UNKNOWN
+		pipelineConfig.Status.Mastership.Master = ""
+		pipelineConfig.Status.Mastership.Term = 0
+		if err := r.pipelineConfigStore.UpdateStatus(ctx, pipelineConfig); err != nil {
+			if !errors.IsNotFound(err) && !errors.IsConflict(err) {
+				log.Errorw("Failed updating pipeline configuration status", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+				return controller.Result{}, err
+			}
+			log.Warnw("Write conflict updating pipeline configuration status", "pipelineConfig ID", pipelineConfig.ID, "error", err)
+			return controller.Result{}, nil
+		}
+	}
+
+	if remaining := time.Until(removed.Add(r.gracePeriod)); remaining > 0 {
+		return controller.Result{
+			RequeueAfter: remaining,
+		}, nil
+	}
+
+	log.Infow("Deleting pipeline configuration of removed target", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID)
+	if err := r.pipelineConfigStore.Delete(ctx, pipelineConfig); err != nil {
+		if !errors.IsNotFound(err) && !errors.IsConflict(err) {
+			log.Errorw("Failed deleting pipeline configuration", "pipelineConfig ID", pipelineConfig.ID, "targetID", pipelineConfig.TargetID, "error", err)
+			return controller.Result{}, err
+		}
+		log.Warnw("Write conflict deleting pipeline configuration", "pipelineConfig ID", pipelineConfig.ID, "error", err)
+		return controller.Result{}, nil
+	}
+	return controller.Result{}, nil
+}
+
+// RemovedTargetWatcher watches topo for removed targets and enqueues all their pipeline configs
+type RemovedTargetWatcher struct {
+	topo            topo.Store
+	pipelineConfigs pipelineConfigStore.Store
+	removed         *removedTargets
+	cancel          context.CancelFunc
+	mu              sync.Mutex
+}
+
+// Start starts the watcher
+func (w *RemovedTargetWatcher) Start(ch chan<- controller.ID) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cancel != nil {
+		return nil
+	}
+
+	eventCh := make(chan topoapi.Event, queueSize)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	err := w.topo.Watch(ctx, eventCh, nil)
+	if err != nil {
+		cancel()
+		return err
+	}
+	w.cancel = cancel
+	go func() {
+		for event := range eventCh {
+			if _, ok := event.Object.Obj.(*topoapi.Object_Entity); !ok {
+				continue
+			}
+			if event.Type == topoapi.EventType_ADDED {
+				w.removed.remove(p4rtapi.TargetID(event.Object.ID))
+				continue
+			}
+			if event.Type != topoapi.EventType_REMOVED {
+				continue
+			}
+			log.Debugw("Target removed from topo", "targetID", event.Object.ID)
+			w.removed.set(p4rtapi.TargetID(event.Object.ID), time.Now())
+			pipelineConfigs, err := w.pipelineConfigs.List(ctx, pipelineConfigStore.WithTargetID(p4rtapi.TargetID(event.Object.ID)))
+			if err != nil {
+				log.Warnw("Failed listing pipeline configurations", "targetID", event.Object.ID, "error", err)
+				continue
+			}
+			for _, pipelineConfig := range pipelineConfigs {
+				if pipelineConfig.TargetID == p4rtapi.TargetID(event.Object.ID) {
+					ch <- controller.NewID(pipelineConfig.ID)
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// Stop stops the watcher
+func (w *RemovedTargetWatcher) Stop() {
+	w.mu.Lock()
+	if w.cancel != nil {
+		w.cancel()
+		w.cancel = nil
+	}
+	w.mu.Unlock()
+}
@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/controller"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineConfigStore "github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGarbageCollector(t *testing.T) {
+	target := &topoapi.Object{
+		ID:   "target-1",
+		Type: topoapi.Object_ENTITY,
+	}
+	topo := topotest.NewStore(target)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	gc := &GarbageCollector{
+		topo:                topo,
+		pipelineConfigStore: pipelineConfigs,
+		gracePeriod:         time.Hour,
+		removed:             newRemovedTargets(),
+	}
+	ctx := context.Background()
+
+	pipelineConfigID := pipelineConfigStore.NewPipelineConfigID("target-1", "wcmp", "1.0.0", "v1model")
+	assert.NoError(t, pipelineConfigs.Create(ctx, &p4rtapi.PipelineConfig{
+		ID:       pipelineConfigID,
+		TargetID: "target-1",
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+		Status: p4rtapi.PipelineConfigStatus{
+			State: p4rtapi.PipelineConfigStatus_COMPLETE,
+		},
+	}))
+
+	// Configs of existing targets are not collected
+	result, err := gc.Reconcile(controller.NewID(pipelineConfigID))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), result.RequeueAfter)
+
+	// Configs of removed targets are moved to UNKNOWN and kept for the grace period
+	assert.NoError(t, topo.Delete(ctx, target))
+	result, err = gc.Reconcile(controller.NewID(pipelineConfigID))
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= time.Hour)
+	pipelineConfig, err := pipelineConfigs.Get(ctx, pipelineConfigID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_UNKNOWN, pipelineConfig.Status.State)
+
+	result, err = gc.Reconcile(controller.NewID(pipelineConfigID))
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 0 && result.RequeueAfter <= time.Hour)
+
+	// The grace period is restarted if the target is added back and removed again
+	assert.NoError(t, topo.Create(ctx, target))
+	_, err = gc.Reconcile(controller.NewID(pipelineConfigID))
+	assert.NoError(t, err)
+	assert.NoError(t, topo.Delete(ctx, target))
+	result, err = gc.Reconcile(controller.NewID(pipelineConfigID))
+	assert.NoError(t, err)
+	assert.True(t, result.RequeueAfter > 59*time.Minute)
+
+	// The grace period is measured from the removal of the target, not from the last config update
+	gc.removed.set("target-1", time.Now().Add(-2*time.Hour))
+	pipelineConfig, err = pipelineConfigs.Get(ctx, pipelineConfigID)
+	assert.NoError(t, err)
+	pipelineConfig.Status.Mastership.Term = 1
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, pipelineConfig))
+	_, err = gc.Reconcile(controller.NewID(pipelineConfigID))
+	assert.NoError(t, err)
+	_, err = pipelineConfigs.Get(ctx, pipelineConfigID)
+	assert.True(t, errors.IsNotFound(err))
+}
@@ -0,0 +1,140 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineConfigStore "github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+)
+
+// ResolvePipelineConfigID returns the given pipeline config ID if such a config exists, or otherwise treats it
+// as a target ID and returns the ID of the active pipeline config of that target
+func ResolvePipelineConfigID(ctx context.Context, pipelineConfigs pipelineConfigStore.Store, id string) (p4rtapi.PipelineConfigID, error) {
+	_, err := pipelineConfigs.Get(ctx, p4rtapi.PipelineConfigID(id))
+	if err == nil {
+		return p4rtapi.PipelineConfigID(id), nil
+	}
+	if !errors.IsNotFound(err) {
+		return "", err
+	}
+
+	targetConfigs, err := pipelineConfigs.List(ctx, pipelineConfigStore.WithTargetID(p4rtapi.TargetID(id)))
+	if err != nil {
+		return "", err
+	}
+	var active []*p4rtapi.PipelineConfig
+	for _, pipelineConfig := range targetConfigs {
+		if pipelineConfig.Action == p4rtapi.ConfigurationAction_UNSPECIFIED {
+			continue
+		}
+		// While switching pipelines, the running config is the one the target is known by
+		if IsRunning(pipelineConfig) {
+			return pipelineConfig.ID, nil
+		}
+		active = append(active, pipelineConfig)
+	}
+	switch len(active) {
+	case 0:
+		return "", errors.NewNotFound("no pipeline config or active pipeline config of target %s found", id)
+	case 1:
+		return active[0].ID, nil
+	}
+	return "", errors.NewConflict("target %s has %d active pipeline configs", id, len(active))
+}
+
+// SetPipelineConfigAction changes the action of a pipeline config and moves it back to PENDING
+// so that the reconciler applies the new action to the target
+func SetPipelineConfigAction(ctx context.Context, pipelineConfigs pipelineConfigStore.Store, id p4rtapi.PipelineConfigID, action p4rtapi.ConfigurationAction) error {
+	if _, ok := configurationActions[action]; !ok {
+		return errors.NewInvalid("unsupported pipeline config action %s", action)
+	}
+	pipelineConfig, err := pipelineConfigs.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	pipelineConfig.Action = action
+	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	return pipelineConfigs.Update(ctx, pipelineConfig)
+}
+
+// SavePipelineConfigs verifies and saves the given pipeline configs on their targets without
+// modifying the forwarding state
+func SavePipelineConfigs(ctx context.Context, pipelineConfigs pipelineConfigStore.Store, ids ...p4rtapi.PipelineConfigID) error {
+	for _, id := range ids {
+		if err := SetPipelineConfigAction(ctx, pipelineConfigs, id, p4rtapi.ConfigurationAction_VERIFY_AND_SAVE); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CommitPipelineConfigs realizes the given pipeline configs on their targets. The configs must have
+// been saved successfully on every target before any of them is committed, which allows a new
+// pipeline to be activated across the whole fabric in a coordinated way.
+func CommitPipelineConfigs(ctx context.Context, pipelineConfigs pipelineConfigStore.Store, ids ...p4rtapi.PipelineConfigID) error {
+	for _, id := range ids {
+		pipelineConfig, err := pipelineConfigs.Get(ctx, id)
+		if err != nil {
+			return err
+		}
+		if pipelineConfig.Action != p4rtapi.ConfigurationAction_VERIFY_AND_SAVE ||
+			pipelineConfig.Status.State != p4rtapi.PipelineConfigStatus_COMPLETE {
+			return errors.NewConflict("pipeline config %s is not saved on target %s", id, pipelineConfig.TargetID)
+		}
+	}
+	for _, id := range ids {
+		if err := SetPipelineConfigAction(ctx, pipelineConfigs, id, p4rtapi.ConfigurationAction_COMMIT); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ResetPipelineConfig moves a pipeline config back to PENDING with its retries starting over, e.g. after
+// its retries have been exhausted
+func ResetPipelineConfig(ctx context.Context, pipelineConfigs pipelineConfigStore.Store, id p4rtapi.PipelineConfigID) error {
+	pipelineConfig, err := pipelineConfigs.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := pipelineConfigs.UpdateStatusDetails(ctx, id, &pipelineConfigStore.StatusDetails{}); err != nil {
+		return err
+	}
+	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	return pipelineConfigs.UpdateStatus(ctx, pipelineConfig)
+}
+
+// RollbackPipelineConfig restores the spec of a retained revision of a pipeline config and moves it back
+// to PENDING so that the reconciler pushes it to the target
+func RollbackPipelineConfig(ctx context.Context, pipelineConfigs pipelineConfigStore.Store, id p4rtapi.PipelineConfigID, revision p4rtapi.Revision) error {
+	history, err := pipelineConfigs.History(ctx, id)
+	if err != nil {
+		return err
+	}
+	var previous *p4rtapi.PipelineConfig
+	for _, revisionConfig := range history {
+		if revisionConfig.Revision == revision {
+			previous = revisionConfig
+		}
+	}
+	if previous == nil {
+		return errors.NewNotFound("revision %d of pipeline config %s not found", revision, id)
+	}
+
+	pipelineConfig, err := pipelineConfigs.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	pipelineConfig.Spec = previous.Spec
+	pipelineConfig.Cookie = previous.Cookie
+	// COMMIT realizes the config saved on the target rather than the restored one
+	if pipelineConfig.Action == p4rtapi.ConfigurationAction_COMMIT {
+		pipelineConfig.Action = p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT
+	}
+	pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	return pipelineConfigs.Update(ctx, pipelineConfig)
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineConfigStore "github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePipelineConfigID(t *testing.T) {
+	ctx := context.Background()
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+
+	_, err := ResolvePipelineConfigID(ctx, pipelineConfigs, testTargetID)
+	assert.True(t, errors.IsNotFound(err))
+
+	// Pipeline config IDs resolve to themselves and target IDs to the active config of the target
+	v1 := newTestPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT)
+	id, err := ResolvePipelineConfigID(ctx, pipelineConfigs, string(v1.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, v1.ID, id)
+	id, err = ResolvePipelineConfigID(ctx, pipelineConfigs, testTargetID)
+	assert.NoError(t, err)
+	assert.Equal(t, v1.ID, id)
+
+	// While switching pipelines, the running config is preferred
+	v2 := &p4rtapi.PipelineConfig{
+		ID:       pipelineConfigStore.NewPipelineConfigID(testTargetID, "wcmp", "2.0.0", "v1model"),
+		TargetID: testTargetID,
+		Spec:     v1.Spec,
+		Action:   p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT,
+		Status: p4rtapi.PipelineConfigStatus{
+			State: p4rtapi.PipelineConfigStatus_PENDING,
+		},
+	}
+	assert.NoError(t, pipelineConfigs.Create(ctx, v2))
+	_, err = ResolvePipelineConfigID(ctx, pipelineConfigs, testTargetID)
+	assert.True(t, errors.IsConflict(err))
+
+	v1, err = pipelineConfigs.Get(ctx, v1.ID)
+	assert.NoError(t, err)
+	v1.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	assert.NoError(t, pipelineConfigs.UpdateStatus(ctx, v1))
+	id, err = ResolvePipelineConfigID(ctx, pipelineConfigs, testTargetID)
+	assert.NoError(t, err)
+	assert.Equal(t, v1.ID, id)
+
+	// Inactive pipeline configs are ignored
+	v1.Action = p4rtapi.ConfigurationAction_UNSPECIFIED
+	assert.NoError(t, pipelineConfigs.Update(ctx, v1))
+	id, err = ResolvePipelineConfigID(ctx, pipelineConfigs, testTargetID)
+	assert.NoError(t, err)
+	assert.Equal(t, v2.ID, id)
+}
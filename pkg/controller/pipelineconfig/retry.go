@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	pipelineConfigStore "github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"time"
+)
+
+// RetryPolicy defines how failed pipeline configs are retried
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts to push a pipeline config; zero disables retries
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the exponential backoff delay
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used when none is configured
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+}
+
+// backoff returns the delay before the retry following the given attempt
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// exhausted returns whether no attempts are left for a pipeline config with the given status details
+func (p RetryPolicy) exhausted(details *pipelineConfigStore.StatusDetails) bool {
+	return details.Attempts >= p.MaxAttempts
+}
+
+// retryAfter returns the remaining delay before a pipeline config with the given status details can be retried
+func (p RetryPolicy) retryAfter(details *pipelineConfigStore.StatusDetails) time.Duration {
+	return time.Until(details.LastAttempt.Add(p.backoff(details.Attempts)))
+}
@@ -0,0 +1,100 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineConfigStore "github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+	}
+	tests := []struct {
+		attempt int
+		backoff time.Duration
+	}{
+		{attempt: 0, backoff: time.Second},
+		{attempt: 1, backoff: time.Second},
+		{attempt: 2, backoff: 2 * time.Second},
+		{attempt: 3, backoff: 4 * time.Second},
+		{attempt: 4, backoff: 8 * time.Second},
+		{attempt: 5, backoff: 10 * time.Second},
+		{attempt: 100, backoff: 10 * time.Second},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.backoff, policy.backoff(test.attempt), "attempt %d", test.attempt)
+	}
+
+	assert.False(t, policy.exhausted(&pipelineConfigStore.StatusDetails{Attempts: 4}))
+	assert.True(t, policy.exhausted(&pipelineConfigStore.StatusDetails{Attempts: 5}))
+
+	details := &pipelineConfigStore.StatusDetails{Attempts: 3, LastAttempt: time.Now()}
+	retryAfter := policy.retryAfter(details)
+	assert.True(t, retryAfter > 3*time.Second && retryAfter <= 4*time.Second)
+	details.LastAttempt = time.Now().Add(-time.Minute)
+	assert.True(t, policy.retryAfter(details) < 0)
+}
+
+func TestReconcileMaxAttempts(t *testing.T) {
+	ctx := context.Background()
+	topo := newTestTopo(t, 1)
+	pipelineConfigs := pipelineConfigStore.NewMemoryStore()
+	pipelineConfig := newTestMasteredPipelineConfig(t, pipelineConfigs, p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT)
+
+	conn := &testConn{setErr: errors.NewUnavailable("target is unreachable")}
+	reconciler := newTestReconciler(topo, conn, pipelineConfigs, false)
+	reconciler.retryPolicy = RetryPolicy{MaxAttempts: 2}
+
+	pipelineConfig, result, err := reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_FAILED, pipelineConfig.Status.State)
+	details, err := pipelineConfigs.GetStatusDetails(ctx, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, details.Attempts)
+	assert.Equal(t, "target is unreachable", details.LastError)
+	assert.False(t, details.LastAttempt.IsZero())
+	assert.True(t, result.RequeueAfter <= 0)
+
+	// The attempts are stored with the pipeline config, so they survive a restart of the reconciler
+	reconciler = newTestReconciler(topo, conn, pipelineConfigs, false)
+	reconciler.retryPolicy = RetryPolicy{MaxAttempts: 2}
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_PENDING, pipelineConfig.Status.State)
+	pipelineConfig, result, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_FAILED, pipelineConfig.Status.State)
+	assert.Equal(t, time.Duration(0), result.RequeueAfter)
+	details, err = pipelineConfigs.GetStatusDetails(ctx, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, details.Attempts)
+
+	// No attempts are left, so the config stays FAILED
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_FAILED, pipelineConfig.Status.State)
+	assert.Len(t, conn.requests, 2)
+
+	// Resetting the config starts the retries over
+	conn.setErr = nil
+	assert.NoError(t, ResetPipelineConfig(ctx, pipelineConfigs, pipelineConfig.ID))
+	details, err = pipelineConfigs.GetStatusDetails(ctx, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, details.Attempts)
+	pipelineConfig, _, err = reconcile(t, reconciler, pipelineConfig.ID)
+	assert.NoError(t, err)
+	assert.True(t, IsRunning(pipelineConfig))
+	assert.Len(t, conn.requests, 3)
+}
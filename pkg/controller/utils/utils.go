@@ -16,3 +16,6 @@ func GetControllerID() topoapi.ID {
 		uri.WithScheme("p4rt"),
 		uri.WithOpaque(env.GetPodID())).String())
 }
+
+// OwnershipCookie is the controller metadata used to tag forwarding entries owned by wcmp-app
+const OwnershipCookie uint64 = 0x77636d70
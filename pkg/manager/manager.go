@@ -5,26 +5,40 @@
 package manager
 
 import (
+	"context"
 	"github.com/atomix/atomix-go-client/pkg/atomix"
 	"github.com/onosproject/onos-lib-go/pkg/certs"
 	"github.com/onosproject/onos-lib-go/pkg/env"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
 	"github.com/onosproject/onos-lib-go/pkg/logging"
 	"github.com/onosproject/onos-lib-go/pkg/northbound"
+	"github.com/onosproject/wcmp-app/pkg/app/hosttracker"
+	"github.com/onosproject/wcmp-app/pkg/app/linkdiscovery"
 	appController "github.com/onosproject/wcmp-app/pkg/app/pipeliner"
+	"github.com/onosproject/wcmp-app/pkg/app/upgrade"
 	"github.com/onosproject/wcmp-app/pkg/controller/connection"
 	"github.com/onosproject/wcmp-app/pkg/controller/mastership"
 	"github.com/onosproject/wcmp-app/pkg/controller/node"
 	pipelineconfigctrl "github.com/onosproject/wcmp-app/pkg/controller/pipelineconfig"
 	"github.com/onosproject/wcmp-app/pkg/controller/target"
+	adminnorthbound "github.com/onosproject/wcmp-app/pkg/northbound/admin/v1"
 	p4rtnorthbound "github.com/onosproject/wcmp-app/pkg/northbound/p4rt/v1"
 	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
 	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
 	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
 	"github.com/onosproject/wcmp-app/pkg/store/topo"
+	"time"
 )
 
 var log = logging.GetLogger()
 
+const (
+	// AtomixPipelineConfigStore is the pipeline config store type persisting configs in Atomix
+	AtomixPipelineConfigStore = "atomix"
+	// MemoryPipelineConfigStore is the pipeline config store type keeping configs in memory for single-node deployments
+	MemoryPipelineConfigStore = "memory"
+)
+
 // Config is a manager pipelineconfig
 type Config struct {
 	CAPath      string
@@ -33,6 +47,21 @@ type Config struct {
 	TopoAddress string
 	GRPCPort    int
 	P4Plugins   []string
+	// P4PluginDir is a directory with a subdirectory of P4 artifacts for each P4 plugin
+	P4PluginDir string
+	WarmRestart bool
+	// PipelineConfigStore is the type of pipeline config store; either atomix or memory
+	PipelineConfigStore string
+	// PipelineConfigMaxAttempts is the maximum number of attempts to push a pipeline config
+	PipelineConfigMaxAttempts int
+	// PipelineConfigGCGracePeriod is the time a target must be missing from topo before its pipeline configs are deleted
+	PipelineConfigGCGracePeriod time.Duration
+	// LinkDiscoveryInterval is the interval between two rounds of link discovery probes
+	LinkDiscoveryInterval time.Duration
+	// LinkDiscoverySecret is the secret discovery probes are signed with; it must be the same for all the replicas
+	LinkDiscoverySecret []byte
+	// HostTimeout is the time after which a host which sent no ARP or NDP packet is removed
+	HostTimeout time.Duration
 }
 
 // Manager single point of entry for the wcmp-app
@@ -50,6 +79,11 @@ func NewManager(cfg Config) *Manager {
 			log.Fatal(err)
 		}
 	}
+	if cfg.P4PluginDir != "" {
+		if err := p4PluginRegistry.WatchPluginDir(context.Background(), cfg.P4PluginDir); err != nil {
+			log.Fatal(err)
+		}
+	}
 	mgr := Manager{
 		Config:           cfg,
 		p4PluginRegistry: p4PluginRegistry,
@@ -73,7 +107,6 @@ func (m *Manager) Start() error {
 		return err
 	}
 
-	atomixClient := atomix.NewClient(atomix.WithClientID(env.GetPodName()))
 	// Create new topo store
 	topoStore, err := topo.NewStore(m.Config.TopoAddress, opts...)
 	if err != nil {
@@ -81,14 +114,14 @@ func (m *Manager) Start() error {
 	}
 
 	// Create a new pipeline config data store
-	pipelineConfigStore, err := pipelineconfig.NewAtomixStore(atomixClient)
+	pipelineConfigStore, err := m.newPipelineConfigStore()
 	if err != nil {
 		return err
 	}
 
 	conns := p4rt.NewConnManager()
 	// Starts NB server
-	err = m.startNorthboundServer()
+	err = m.startNorthboundServer(topoStore, pipelineConfigStore, conns)
 	if err != nil {
 		return err
 	}
@@ -119,20 +152,76 @@ func (m *Manager) Start() error {
 		return err
 	}
 
+	// Starts pipelineconfig garbage collector
+	err = m.startPipelineConfigGarbageCollector(topoStore, pipelineConfigStore)
+	if err != nil {
+		return err
+	}
+
 	err = m.startAppController(topoStore, pipelineConfigStore, m.p4PluginRegistry)
 	if err != nil {
 		return err
 	}
 
+	// Starts link discovery
+	err = m.startLinkDiscovery(topoStore, conns)
+	if err != nil {
+		return err
+	}
+
+	// Starts host tracker
+	err = m.startHostTracker(topoStore, conns)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// newUpgrader creates the rolling upgrader served by the admin service, verifying that each upgraded target
+// has a master and runs the new pipeline config
+func (m *Manager) newUpgrader(topo topo.Store, pipelineConfigStore pipelineconfig.Store, conns p4rt.ConnManager) *upgrade.Upgrader {
+	return upgrade.NewUpgrader(topo, pipelineConfigStore, m.p4PluginRegistry,
+		upgrade.NewMastershipCheck(topo),
+		upgrade.NewPipelineCookieCheck(topo, conns))
+}
+
+// newPipelineConfigStore creates the pipeline config store of the configured type
+func (m *Manager) newPipelineConfigStore() (pipelineconfig.Store, error) {
+	return NewPipelineConfigStore(m.Config.PipelineConfigStore)
+}
+
+// NewPipelineConfigStore creates a pipeline config store of the given type
+func NewPipelineConfigStore(storeType string) (pipelineconfig.Store, error) {
+	switch storeType {
+	case "", AtomixPipelineConfigStore:
+		atomixClient := atomix.NewClient(atomix.WithClientID(env.GetPodName()))
+		return pipelineconfig.NewAtomixStore(atomixClient)
+	case MemoryPipelineConfigStore:
+		log.Infow("Using in-memory pipeline config store")
+		return pipelineconfig.NewMemoryStore(), nil
+	}
+	return nil, errors.NewInvalid("unknown pipeline config store type %s", storeType)
+}
+
 func (m *Manager) startAppController(topo topo.Store, pipelineConfigStore pipelineconfig.Store, p4PluginRegistry pluginregistry.P4PluginRegistry) error {
 	appCtrl := appController.NewController(topo, pipelineConfigStore, p4PluginRegistry)
 	return appCtrl.Start()
 
 }
 
+// startLinkDiscovery starts discovering the links between the mastered switches
+func (m *Manager) startLinkDiscovery(topo topo.Store, conns p4rt.ConnManager) error {
+	linkDiscovery := linkdiscovery.NewService(topo, conns, m.Config.LinkDiscoveryInterval, m.Config.LinkDiscoverySecret)
+	return linkDiscovery.Start()
+}
+
+// startHostTracker starts learning the hosts attached to the edge ports of the mastered switches
+func (m *Manager) startHostTracker(topo topo.Store, conns p4rt.ConnManager) error {
+	hostTracker := hosttracker.NewService(topo, conns, m.Config.HostTimeout)
+	return hostTracker.Start()
+}
+
 // startNodeController starts node controller
 func (m *Manager) startNodeController(topo topo.Store) error {
 	nodeController := node.NewController(topo)
@@ -159,13 +248,21 @@ func (m *Manager) startMastershipController(topo topo.Store, conns p4rt.ConnMana
 
 // startConfigurationController starts pipelineconfig controller
 func (m *Manager) startPipelineConfigController(topo topo.Store, conns p4rt.ConnManager, p4PluginRegistry pluginregistry.P4PluginRegistry, pipelineConfigStore pipelineconfig.Store) error {
-	configurationController := pipelineconfigctrl.NewController(topo, conns, p4PluginRegistry, pipelineConfigStore)
+	retryPolicy := pipelineconfigctrl.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = m.Config.PipelineConfigMaxAttempts
+	configurationController := pipelineconfigctrl.NewController(topo, conns, p4PluginRegistry, pipelineConfigStore, m.Config.WarmRestart, retryPolicy)
 	return configurationController.Start()
 
 }
 
+// startPipelineConfigGarbageCollector starts the garbage collector of pipeline configs of removed targets
+func (m *Manager) startPipelineConfigGarbageCollector(topo topo.Store, pipelineConfigStore pipelineconfig.Store) error {
+	garbageCollector := pipelineconfigctrl.NewGarbageCollector(topo, pipelineConfigStore, m.Config.PipelineConfigGCGracePeriod)
+	return garbageCollector.Start()
+}
+
 // startSouthboundServer starts the northbound gRPC server
-func (m *Manager) startNorthboundServer() error {
+func (m *Manager) startNorthboundServer(topo topo.Store, pipelineConfigStore pipelineconfig.Store, conns p4rt.ConnManager) error {
 	s := northbound.NewServer(northbound.NewServerCfg(
 		m.Config.CAPath,
 		m.Config.KeyPath,
@@ -174,7 +271,8 @@ func (m *Manager) startNorthboundServer() error {
 		true,
 		northbound.SecurityConfig{}))
 	s.AddService(logging.Service{})
-	s.AddService(p4rtnorthbound.Service{})
+	s.AddService(p4rtnorthbound.NewService(m.p4PluginRegistry, pipelineConfigStore, topo, conns))
+	s.AddService(adminnorthbound.NewService(m.newUpgrader(topo, pipelineConfigStore, conns), m.p4PluginRegistry))
 
 	doneCh := make(chan error)
 	go func() {
@@ -0,0 +1,151 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"github.com/onosproject/onos-lib-go/pkg/northbound"
+	adminapi "github.com/onosproject/wcmp-app/api/admin/v1"
+	"github.com/onosproject/wcmp-app/pkg/app/upgrade"
+	"github.com/onosproject/wcmp-app/pkg/p4info"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+	"google.golang.org/grpc"
+)
+
+var log = logging.GetLogger()
+
+// Service implements the wcmp-app admin service
+type Service struct {
+	northbound.Service
+	upgrader         *upgrade.Upgrader
+	p4PluginRegistry pluginregistry.P4PluginRegistry
+}
+
+// NewService creates a new instance of the admin service
+func NewService(upgrader *upgrade.Upgrader, p4PluginRegistry pluginregistry.P4PluginRegistry) Service {
+	return Service{
+		upgrader:         upgrader,
+		p4PluginRegistry: p4PluginRegistry,
+	}
+}
+
+// Register registers the admin server
+func (s Service) Register(r *grpc.Server) {
+	adminapi.RegisterAdminServer(r, &Server{
+		upgrader:         s.upgrader,
+		p4PluginRegistry: s.p4PluginRegistry,
+	})
+}
+
+// Server implements the admin gRPC service
+type Server struct {
+	adminapi.UnimplementedAdminServer
+	upgrader         *upgrade.Upgrader
+	p4PluginRegistry pluginregistry.P4PluginRegistry
+}
+
+// Upgrade upgrades the pipeline of targets to the pipeline of a P4 plugin, canaries first and then in batches
+func (s *Server) Upgrade(ctx context.Context, request *adminapi.UpgradeRequest) (*adminapi.UpgradeResponse, error) {
+	log.Infow("Received UpgradeRequest", "request", request)
+	if request.PluginId == "" {
+		return nil, errors.Status(errors.NewInvalid("no P4 plugin ID specified")).Err()
+	}
+	plan := upgrade.Plan{
+		PluginID:  p4rtapi.P4PluginID(request.PluginId),
+		Canaries:  getTargetIDs(request.Canaries),
+		Targets:   getTargetIDs(request.Targets),
+		BatchSize: int(request.BatchSize),
+		Rollback:  request.Rollback,
+	}
+	if request.TargetTimeout != nil {
+		plan.TargetTimeout = request.TargetTimeout.AsDuration()
+	}
+	status, err := s.upgrader.Run(ctx, plan)
+	if err != nil {
+		log.Warnw("Failed upgrading pipeline of targets", "plugin ID", request.PluginId, "error", err)
+		return nil, errors.Status(err).Err()
+	}
+	response := &adminapi.UpgradeResponse{
+		State:      getUpgradeState(status.State),
+		Upgraded:   getTargetNames(status.Upgraded),
+		Failed:     getTargetNames(status.Failed),
+		Remaining:  getTargetNames(status.Remaining),
+		RolledBack: getTargetNames(status.RolledBack),
+	}
+	if status.Error != nil {
+		response.Error = status.Error.Error()
+	}
+	return response, nil
+}
+
+// ComparePlugins compares the P4Info of two P4 plugins and classifies the changes as compatible or breaking
+func (s *Server) ComparePlugins(ctx context.Context, request *adminapi.ComparePluginsRequest) (*adminapi.ComparePluginsResponse, error) {
+	log.Infow("Received ComparePluginsRequest", "request", request)
+	compare := pluginregistry.ComparePlugins
+	if request.WcmpOnly {
+		compare = pluginregistry.CompareWCMPPlugins
+	}
+	diff, err := compare(s.p4PluginRegistry, p4rtapi.P4PluginID(request.FromPluginId), p4rtapi.P4PluginID(request.ToPluginId))
+	if err != nil {
+		log.Warnw("Failed comparing P4 plugins", "from", request.FromPluginId, "to", request.ToPluginId, "error", err)
+		return nil, errors.Status(err).Err()
+	}
+	response := &adminapi.ComparePluginsResponse{
+		Changes:    make([]*adminapi.Change, 0, len(diff.Changes)),
+		Compatible: diff.IsCompatible(),
+	}
+	for _, change := range diff.Changes {
+		response.Changes = append(response.Changes, &adminapi.Change{
+			Kind:     string(change.Kind),
+			Name:     change.Name,
+			Type:     getChangeType(change.Type),
+			Breaking: change.Breaking,
+			Details:  change.Details,
+		})
+	}
+	return response, nil
+}
+
+func getChangeType(changeType p4info.ChangeType) adminapi.ChangeType {
+	switch changeType {
+	case p4info.Removed:
+		return adminapi.ChangeType_REMOVED
+	case p4info.Renamed:
+		return adminapi.ChangeType_RENAMED
+	case p4info.Modified:
+		return adminapi.ChangeType_MODIFIED
+	}
+	return adminapi.ChangeType_ADDED
+}
+
+func getUpgradeState(state upgrade.State) adminapi.UpgradeState {
+	switch state {
+	case upgrade.Paused:
+		return adminapi.UpgradeState_PAUSED
+	case upgrade.RolledBack:
+		return adminapi.UpgradeState_ROLLED_BACK
+	}
+	return adminapi.UpgradeState_COMPLETED
+}
+
+func getTargetIDs(names []string) []topoapi.ID {
+	ids := make([]topoapi.ID, 0, len(names))
+	for _, name := range names {
+		ids = append(ids, topoapi.ID(name))
+	}
+	return ids
+}
+
+func getTargetNames(ids []topoapi.ID) []string {
+	names := make([]string, 0, len(ids))
+	for _, id := range ids {
+		names = append(names, string(id))
+	}
+	return names
+}
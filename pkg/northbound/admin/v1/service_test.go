@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	adminapi "github.com/onosproject/wcmp-app/api/admin/v1"
+	"github.com/onosproject/wcmp-app/pkg/app/upgrade"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type testPlugin struct {
+	pluginregistry.P4Plugin
+	pkgInfo *p4configapi.PkgInfo
+	p4Info  *p4configapi.P4Info
+}
+
+func (p *testPlugin) GetPkgInfo() (*p4configapi.PkgInfo, error) {
+	return p.pkgInfo, nil
+}
+
+func (p *testPlugin) GetP4Info() (*p4configapi.P4Info, error) {
+	return p.p4Info, nil
+}
+
+type testRegistry struct {
+	pluginregistry.P4PluginRegistry
+	plugins map[p4rtapi.P4PluginID]pluginregistry.P4Plugin
+}
+
+func (r *testRegistry) GetPlugin(id p4rtapi.P4PluginID) (pluginregistry.P4Plugin, error) {
+	p4Plugin, ok := r.plugins[id]
+	if !ok {
+		return nil, errors.NewNotFound("P4 plugin %s not found", id)
+	}
+	return p4Plugin, nil
+}
+
+func TestUpgrade(t *testing.T) {
+	ctx := context.Background()
+	pluginID := p4rtapi.NewP4PluginID("wcmp", "2.0.0", "v1model")
+	registry := &testRegistry{plugins: map[p4rtapi.P4PluginID]pluginregistry.P4Plugin{
+		pluginID: &testPlugin{pkgInfo: &p4configapi.PkgInfo{Name: "wcmp", Version: "2.0.0", Arch: "v1model"}},
+	}}
+	server := &Server{
+		upgrader: upgrade.NewUpgrader(topotest.NewStore(), pipelineconfig.NewMemoryStore(), registry),
+	}
+
+	_, err := server.Upgrade(ctx, &adminapi.UpgradeRequest{})
+	assert.True(t, errors.IsInvalid(errors.FromGRPC(err)))
+	_, err = server.Upgrade(ctx, &adminapi.UpgradeRequest{PluginId: "basic-1.0.0-v1model"})
+	assert.True(t, errors.IsNotFound(errors.FromGRPC(err)))
+
+	response, err := server.Upgrade(ctx, &adminapi.UpgradeRequest{PluginId: string(pluginID)})
+	assert.NoError(t, err)
+	assert.Equal(t, adminapi.UpgradeState_COMPLETED, response.State)
+	assert.Empty(t, response.Error)
+
+	// Targets missing from topo fail the upgrade, which is paused
+	response, err = server.Upgrade(ctx, &adminapi.UpgradeRequest{
+		PluginId: string(pluginID),
+		Canaries: []string{"target-1"},
+		Targets:  []string{"target-2", "target-3"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, adminapi.UpgradeState_PAUSED, response.State)
+	assert.Equal(t, []string{"target-1"}, response.Failed)
+	assert.Equal(t, []string{"target-2", "target-3"}, response.Remaining)
+	assert.NotEmpty(t, response.Error)
+}
+
+func TestComparePlugins(t *testing.T) {
+	ctx := context.Background()
+	newP4Info := func(portParam string) *p4configapi.P4Info {
+		return &p4configapi.P4Info{
+			Actions: []*p4configapi.Action{
+				{
+					Preamble: &p4configapi.Preamble{Id: 1, Name: "ingress.route_hashed"},
+					Params:   []*p4configapi.Action_Param{{Id: 1, Name: portParam, Bitwidth: 9}},
+				},
+			},
+		}
+	}
+	v1ID := p4rtapi.NewP4PluginID("wcmp", "1.0.0", "v1model")
+	v2ID := p4rtapi.NewP4PluginID("wcmp", "2.0.0", "v1model")
+	server := &Server{
+		p4PluginRegistry: &testRegistry{plugins: map[p4rtapi.P4PluginID]pluginregistry.P4Plugin{
+			v1ID: &testPlugin{p4Info: newP4Info("port_num")},
+			v2ID: &testPlugin{p4Info: newP4Info("port")},
+		}},
+	}
+
+	response, err := server.ComparePlugins(ctx, &adminapi.ComparePluginsRequest{FromPluginId: string(v1ID), ToPluginId: string(v2ID)})
+	assert.NoError(t, err)
+	assert.False(t, response.Compatible)
+	assert.Len(t, response.Changes, 1)
+	assert.Equal(t, "action param", response.Changes[0].Kind)
+	assert.Equal(t, "ingress.route_hashed/port_num", response.Changes[0].Name)
+	assert.Equal(t, adminapi.ChangeType_RENAMED, response.Changes[0].Type)
+	assert.True(t, response.Changes[0].Breaking)
+
+	// Plugins without WCMP mapping cannot be compared on their WCMP entities
+	_, err = server.ComparePlugins(ctx, &adminapi.ComparePluginsRequest{FromPluginId: string(v1ID), ToPluginId: string(v2ID), WcmpOnly: true})
+	assert.True(t, errors.IsNotSupported(errors.FromGRPC(err)))
+	_, err = server.ComparePlugins(ctx, &adminapi.ComparePluginsRequest{FromPluginId: string(v1ID), ToPluginId: "basic-1.0.0-v1model"})
+	assert.True(t, errors.IsNotFound(errors.FromGRPC(err)))
+}
@@ -6,7 +6,14 @@ package v1
 
 import (
 	"context"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	pipelineconfigctrl "github.com/onosproject/wcmp-app/pkg/controller/pipelineconfig"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
 	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"google.golang.org/protobuf/proto"
 )
 
 // SetForwardingPipelineConfig Sets the P4 forwarding-pipeline config.
@@ -17,6 +24,74 @@ func (s *Server) SetForwardingPipelineConfig(ctx context.Context, request *p4api
 
 // GetForwardingPipelineConfig the current P4 forwarding-pipeline config
 func (s *Server) GetForwardingPipelineConfig(ctx context.Context, request *p4api.GetForwardingPipelineConfigRequest) (*p4api.GetForwardingPipelineConfigResponse, error) {
-	//TODO implement me
-	panic("implement me")
+	config, err := s.getForwardingPipelineConfig(ctx, request)
+	if err != nil {
+		log.Warnw("Failed getting forwarding pipeline config", "device ID", request.DeviceId, "error", err)
+		return nil, errors.Status(err).Err()
+	}
+	return &p4api.GetForwardingPipelineConfigResponse{
+		Config: config,
+	}, nil
+}
+
+func (s *Server) getForwardingPipelineConfig(ctx context.Context, request *p4api.GetForwardingPipelineConfigRequest) (*p4api.ForwardingPipelineConfig, error) {
+	targetID, err := s.getTargetID(ctx, request.DeviceId)
+	if err != nil {
+		return nil, err
+	}
+	pipelineConfigID, err := pipelineconfigctrl.ResolvePipelineConfigID(ctx, s.pipelineConfigStore, string(targetID))
+	if err != nil {
+		return nil, err
+	}
+	pipelineConfig, err := s.pipelineConfigStore.Get(ctx, pipelineConfigID)
+	if err != nil {
+		return nil, err
+	}
+	p4Info := &p4configapi.P4Info{}
+	if err := proto.Unmarshal(pipelineConfig.Spec.P4Info, p4Info); err != nil {
+		return nil, errors.NewInvalid("invalid P4Info in pipeline config %s: %v", pipelineConfig.ID, err)
+	}
+	// The pipeline of a P4 plugin that is no longer loaded is not served
+	pkgInfo := p4Info.GetPkgInfo()
+	pluginID := p4rtapi.NewP4PluginID(pkgInfo.GetName(), pkgInfo.GetVersion(), pkgInfo.GetArch())
+	if !s.plugins.contains(pluginID) {
+		return nil, errors.NewUnavailable("P4 plugin '%s' of device %d is not loaded", pluginID, request.DeviceId)
+	}
+
+	config := &p4api.ForwardingPipelineConfig{}
+	if pipelineConfig.Cookie != nil {
+		config.Cookie = &p4api.ForwardingPipelineConfig_Cookie{
+			Cookie: pipelineConfig.Cookie.Cookie,
+		}
+	}
+	switch request.ResponseType {
+	case p4api.GetForwardingPipelineConfigRequest_ALL:
+		config.P4Info = p4Info
+		config.P4DeviceConfig = pipelineConfig.Spec.P4DeviceConfig
+	case p4api.GetForwardingPipelineConfigRequest_P4INFO_AND_COOKIE:
+		config.P4Info = p4Info
+	case p4api.GetForwardingPipelineConfigRequest_DEVICE_CONFIG_AND_COOKIE:
+		config.P4DeviceConfig = pipelineConfig.Spec.P4DeviceConfig
+	}
+	return config, nil
+}
+
+// getTargetID returns the ID of the target with a given P4Runtime device ID
+func (s *Server) getTargetID(ctx context.Context, deviceID uint64) (topoapi.ID, error) {
+	objects, err := s.topo.List(ctx, &topoapi.Filters{
+		ObjectTypes: []topoapi.Object_Type{topoapi.Object_ENTITY},
+	})
+	if err != nil {
+		return "", err
+	}
+	for _, object := range objects {
+		p4rtServerInfo := &topoapi.P4RTServerInfo{}
+		if err := object.GetAspect(p4rtServerInfo); err != nil {
+			continue
+		}
+		if p4rtServerInfo.DeviceID == deviceID {
+			return object.ID, nil
+		}
+	}
+	return "", errors.NewNotFound("no target with device ID %d found", deviceID)
 }
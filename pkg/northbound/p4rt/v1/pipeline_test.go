@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+	"github.com/onosproject/wcmp-app/pkg/store/pipelineconfig"
+	"github.com/onosproject/wcmp-app/pkg/store/topo/topotest"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestGetForwardingPipelineConfig(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target := &topoapi.Object{
+		ID:   "target-1",
+		Type: topoapi.Object_ENTITY,
+	}
+	assert.NoError(t, target.SetAspect(&topoapi.P4RTServerInfo{DeviceID: 1}))
+	registry := pluginregistry.NewP4PluginRegistry()
+	pluginID := p4rtapi.NewP4PluginID("wcmp", "1.0.0", "v1model")
+	p4Plugin, err := registry.GetPlugin(pluginID)
+	assert.NoError(t, err)
+	p4Info, err := p4Plugin.GetP4Info()
+	assert.NoError(t, err)
+	p4InfoBytes, err := proto.Marshal(p4Info)
+	assert.NoError(t, err)
+	pipelineConfigs := pipelineconfig.NewMemoryStore()
+	assert.NoError(t, pipelineConfigs.Create(ctx, &p4rtapi.PipelineConfig{
+		ID:       pipelineconfig.NewPipelineConfigID(p4rtapi.TargetID(target.ID), "wcmp", "1.0.0", "v1model"),
+		TargetID: p4rtapi.TargetID(target.ID),
+		Spec: &p4rtapi.PipelineConfigSpec{
+			P4Info:         p4InfoBytes,
+			P4DeviceConfig: []byte("device config"),
+		},
+		Cookie: &p4rtapi.Cookie{Cookie: 1},
+		Action: p4rtapi.ConfigurationAction_VERIFY_AND_COMMIT,
+	}, pipelineconfig.WithPluginID(pluginID)))
+
+	plugins, err := newAvailablePlugins(ctx, registry)
+	assert.NoError(t, err)
+	server := &Server{
+		p4PluginRegistry:    registry,
+		pipelineConfigStore: pipelineConfigs,
+		topo:                topotest.NewStore(target),
+		plugins:             plugins,
+	}
+
+	response, err := server.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{
+		DeviceId:     1,
+		ResponseType: p4api.GetForwardingPipelineConfigRequest_COOKIE_ONLY,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), response.Config.Cookie.Cookie)
+	assert.Nil(t, response.Config.P4Info)
+	assert.Nil(t, response.Config.P4DeviceConfig)
+
+	response, err = server.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{
+		DeviceId:     1,
+		ResponseType: p4api.GetForwardingPipelineConfigRequest_ALL,
+	})
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(p4Info, response.Config.P4Info))
+	assert.Equal(t, []byte("device config"), response.Config.P4DeviceConfig)
+
+	_, err = server.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{DeviceId: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	// The pipeline of an unregistered plugin is no longer served
+	assert.NoError(t, registry.UnregisterPlugin(pluginID))
+	assert.Eventually(t, func() bool {
+		return !plugins.contains(pluginID)
+	}, time.Second, 10*time.Millisecond)
+	_, err = server.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{DeviceId: 1})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
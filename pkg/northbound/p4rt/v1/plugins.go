@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package v1
+
+import (
+	"context"
+	"sync"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"github.com/onosproject/wcmp-app/pkg/pluginregistry"
+)
+
+var log = logging.GetLogger()
+
+const pluginEventsSize = 10
+
+// availablePlugins tracks the P4 plugins loaded in the registry, which are the only ones served to clients
+type availablePlugins struct {
+	plugins map[p4rtapi.P4PluginID]struct{}
+	mu      sync.RWMutex
+}
+
+// newAvailablePlugins returns the P4 plugins loaded in a given registry, kept in sync with the registry
+// events until the context is done
+func newAvailablePlugins(ctx context.Context, p4PluginRegistry pluginregistry.P4PluginRegistry) (*availablePlugins, error) {
+	eventCh := make(chan pluginregistry.PluginEvent, pluginEventsSize)
+	// Watch before listing the loaded plugins so that no registry change is missed in between
+	if err := p4PluginRegistry.Watch(ctx, eventCh); err != nil {
+		return nil, err
+	}
+	a := &availablePlugins{
+		plugins: make(map[p4rtapi.P4PluginID]struct{}),
+	}
+	for pluginID := range p4PluginRegistry.GetPlugins() {
+		a.plugins[pluginID] = struct{}{}
+	}
+	go func() {
+		for event := range eventCh {
+			a.update(event)
+		}
+	}()
+	return a, nil
+}
+
+func (a *availablePlugins) update(event pluginregistry.PluginEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	switch event.Type {
+	case pluginregistry.PluginRegistered, pluginregistry.PluginUpdated:
+		a.plugins[event.PluginID] = struct{}{}
+	case pluginregistry.PluginUnregistered:
+		log.Infow("P4 plugin is no longer available", "plugin ID", event.PluginID)
+		delete(a.plugins, event.PluginID)
+	}
+}
+
+// contains returns whether a P4 plugin is loaded
+func (a *availablePlugins) contains(pluginID p4rtapi.P4PluginID) bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	_, ok := a.plugins[pluginID]
+	return ok
+}
@@ -46,6 +46,7 @@ type Server struct {
 	pipelineConfigStore pipelineconfig.Store
 	topo                topo.Store
 	conns               p4rt.ConnManager
+	plugins             *availablePlugins
 }
 
 // Capabilities discover the capabilities of the P4Runtime server implementation
@@ -56,10 +57,15 @@ func (s *Server) Capabilities(ctx context.Context, request *p4api.CapabilitiesRe
 
 // Register registers P4runtime server
 func (s Service) Register(r *grpc.Server) {
+	plugins, err := newAvailablePlugins(context.Background(), s.p4PluginRegistry)
+	if err != nil {
+		log.Fatalw("Unable to watch P4 plugins", "error", err)
+	}
 	p4api.RegisterP4RuntimeServer(r, &Server{
 		p4PluginRegistry:    s.p4PluginRegistry,
 		topo:                s.topo,
 		conns:               s.conns,
 		pipelineConfigStore: s.pipelineConfigStore,
+		plugins:             plugins,
 	})
 }
@@ -0,0 +1,436 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4info
+
+import (
+	"fmt"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"strings"
+)
+
+// ChangeType is the type of a P4Info change
+type ChangeType int
+
+const (
+	// Added indicates an entity is added
+	Added ChangeType = iota
+	// Removed indicates an entity is removed
+	Removed
+	// Renamed indicates an entity keeps its ID but changes its name
+	Renamed
+	// Modified indicates a property of an entity is changed
+	Modified
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "ADDED"
+	case Removed:
+		return "REMOVED"
+	case Renamed:
+		return "RENAMED"
+	case Modified:
+		return "MODIFIED"
+	}
+	return "UNKNOWN"
+}
+
+// EntityKind is the kind of P4Info entity affected by a change
+type EntityKind string
+
+const (
+	// TableKind is a table
+	TableKind EntityKind = "table"
+	// MatchFieldKind is a table match field
+	MatchFieldKind EntityKind = "match field"
+	// ActionKind is an action
+	ActionKind EntityKind = "action"
+	// ActionParamKind is an action parameter
+	ActionParamKind EntityKind = "action param"
+	// ActionProfileKind is an action profile
+	ActionProfileKind EntityKind = "action profile"
+)
+
+// Change is a change between two P4Info messages
+type Change struct {
+	Kind EntityKind
+	// Name is the fully qualified name of the entity, e.g. "table/match field"
+	Name     string
+	Type     ChangeType
+	Breaking bool
+	Details  string
+}
+
+func (c Change) String() string {
+	compatibility := "compatible"
+	if c.Breaking {
+		compatibility = "breaking"
+	}
+	if c.Details == "" {
+		return fmt.Sprintf("%s %s %s (%s)", c.Kind, c.Name, c.Type, compatibility)
+	}
+	return fmt.Sprintf("%s %s %s: %s (%s)", c.Kind, c.Name, c.Type, c.Details, compatibility)
+}
+
+// Diff is the set of changes between two P4Info messages
+type Diff struct {
+	Changes []Change
+}
+
+// IsCompatible returns whether none of the changes is breaking
+func (d *Diff) IsCompatible() bool {
+	return len(d.BreakingChanges()) == 0
+}
+
+// BreakingChanges returns the breaking changes
+func (d *Diff) BreakingChanges() []Change {
+	var changes []Change
+	for _, change := range d.Changes {
+		if change.Breaking {
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// Filter returns the changes affecting the given entities, which are named as in changes. The changes to
+// the match fields of a table and to the params of an action affect the table and the action respectively.
+func (d *Diff) Filter(names ...string) *Diff {
+	entities := make(map[string]bool)
+	for _, name := range names {
+		if name != "" {
+			entities[name] = true
+		}
+	}
+	filtered := &Diff{}
+	for _, change := range d.Changes {
+		name := change.Name
+		if i := strings.Index(name, "/"); i >= 0 && !entities[name] {
+			name = name[:i]
+		}
+		if entities[name] {
+			filtered.Changes = append(filtered.Changes, change)
+		}
+	}
+	return filtered
+}
+
+func (d *Diff) add(kind EntityKind, name string, changeType ChangeType, breaking bool, details string) {
+	d.Changes = append(d.Changes, Change{
+		Kind:     kind,
+		Name:     name,
+		Type:     changeType,
+		Breaking: breaking,
+		Details:  details,
+	})
+}
+
+// Compare compares two P4Info messages and classifies the changes to the tables, actions, match fields
+// and action profiles as compatible or breaking. Entities are matched by name; an entity whose name
+// disappears while its ID is kept is reported as renamed. P4Runtime entities refer to each other by ID,
+// so an ID change breaks the entities written with the previous ID.
+func Compare(from, to *p4configapi.P4Info) *Diff {
+	diff := &Diff{}
+	fromActions := actionNames(from)
+	toActions := actionNames(to)
+	fromTables := tableNames(from)
+	toTables := tableNames(to)
+	fromProfiles := actionProfileNames(from)
+	toProfiles := actionProfileNames(to)
+
+	compareActions(diff, from, to)
+	compareTables(diff, from, to, fromActions, toActions, fromProfiles, toProfiles)
+	compareActionProfiles(diff, from, to, fromTables, toTables)
+	return diff
+}
+
+func compareActions(diff *Diff, from, to *p4configapi.P4Info) {
+	toByName := make(map[string]*p4configapi.Action)
+	toByID := make(map[uint32]*p4configapi.Action)
+	for _, action := range to.GetActions() {
+		toByName[action.GetPreamble().GetName()] = action
+		toByID[action.GetPreamble().GetId()] = action
+	}
+	fromByName := make(map[string]*p4configapi.Action)
+	for _, fromAction := range from.GetActions() {
+		name := fromAction.GetPreamble().GetName()
+		fromByName[name] = fromAction
+		toAction, ok := toByName[name]
+		if !ok {
+			if renamed, ok := toByID[fromAction.GetPreamble().GetId()]; ok {
+				diff.add(ActionKind, name, Renamed, true, fmt.Sprintf("renamed to %s", renamed.GetPreamble().GetName()))
+			} else {
+				diff.add(ActionKind, name, Removed, true, "")
+			}
+			continue
+		}
+		if fromAction.GetPreamble().GetId() != toAction.GetPreamble().GetId() {
+			diff.add(ActionKind, name, Modified, true, fmt.Sprintf("ID changed from %d to %d", fromAction.GetPreamble().GetId(), toAction.GetPreamble().GetId()))
+		}
+		compareActionParams(diff, name, fromAction, toAction)
+	}
+	for _, toAction := range to.GetActions() {
+		if _, ok := fromByName[toAction.GetPreamble().GetName()]; !ok && !hasID(from.GetActions(), toAction.GetPreamble().GetId()) {
+			diff.add(ActionKind, toAction.GetPreamble().GetName(), Added, false, "")
+		}
+	}
+}
+
+func compareActionParams(diff *Diff, actionName string, from, to *p4configapi.Action) {
+	toByName := make(map[string]*p4configapi.Action_Param)
+	toByID := make(map[uint32]*p4configapi.Action_Param)
+	for _, param := range to.GetParams() {
+		toByName[param.GetName()] = param
+		toByID[param.GetId()] = param
+	}
+	fromByName := make(map[string]bool)
+	for _, fromParam := range from.GetParams() {
+		fromByName[fromParam.GetName()] = true
+	}
+	// matched are the params of the new action matching a param of the previous one by name or by ID
+	matched := make(map[string]bool)
+	for _, fromParam := range from.GetParams() {
+		name := actionName + "/" + fromParam.GetName()
+		toParam, ok := toByName[fromParam.GetName()]
+		if !ok {
+			if renamed, ok := toByID[fromParam.GetId()]; ok && !fromByName[renamed.GetName()] {
+				matched[renamed.GetName()] = true
+				diff.add(ActionParamKind, name, Renamed, true, fmt.Sprintf("renamed to %s", renamed.GetName()))
+				toParam = renamed
+			} else {
+				diff.add(ActionParamKind, name, Removed, true, "")
+				continue
+			}
+		}
+		matched[toParam.GetName()] = true
+		if fromParam.GetId() != toParam.GetId() {
+			diff.add(ActionParamKind, name, Modified, true, fmt.Sprintf("ID changed from %d to %d", fromParam.GetId(), toParam.GetId()))
+		}
+		if fromParam.GetBitwidth() != toParam.GetBitwidth() {
+			diff.add(ActionParamKind, name, Modified, true, fmt.Sprintf("bitwidth changed from %d to %d", fromParam.GetBitwidth(), toParam.GetBitwidth()))
+		}
+	}
+	for _, toParam := range to.GetParams() {
+		if !matched[toParam.GetName()] {
+			// Every action parameter must be provided, so a new one breaks existing action specs
+			diff.add(ActionParamKind, actionName+"/"+toParam.GetName(), Added, true, "")
+		}
+	}
+}
+
+func compareTables(diff *Diff, from, to *p4configapi.P4Info, fromActions, toActions, fromProfiles, toProfiles map[uint32]string) {
+	toByName := make(map[string]*p4configapi.Table)
+	toByID := make(map[uint32]*p4configapi.Table)
+	for _, table := range to.GetTables() {
+		toByName[table.GetPreamble().GetName()] = table
+		toByID[table.GetPreamble().GetId()] = table
+	}
+	fromByName := make(map[string]bool)
+	for _, fromTable := range from.GetTables() {
+		name := fromTable.GetPreamble().GetName()
+		fromByName[name] = true
+		toTable, ok := toByName[name]
+		if !ok {
+			if renamed, ok := toByID[fromTable.GetPreamble().GetId()]; ok {
+				diff.add(TableKind, name, Renamed, true, fmt.Sprintf("renamed to %s", renamed.GetPreamble().GetName()))
+			} else {
+				diff.add(TableKind, name, Removed, true, "")
+			}
+			continue
+		}
+		if fromTable.GetPreamble().GetId() != toTable.GetPreamble().GetId() {
+			diff.add(TableKind, name, Modified, true, fmt.Sprintf("ID changed from %d to %d", fromTable.GetPreamble().GetId(), toTable.GetPreamble().GetId()))
+		}
+		compareMatchFields(diff, name, fromTable, toTable)
+
+		toActionRefs := make(map[string]bool)
+		for _, actionRef := range toTable.GetActionRefs() {
+			toActionRefs[toActions[actionRef.GetId()]] = true
+		}
+		fromActionRefs := make(map[string]bool)
+		for _, actionRef := range fromTable.GetActionRefs() {
+			actionName := fromActions[actionRef.GetId()]
+			fromActionRefs[actionName] = true
+			if !toActionRefs[actionName] {
+				diff.add(TableKind, name, Modified, true, fmt.Sprintf("action %s is no longer supported", actionName))
+			}
+		}
+		for _, actionRef := range toTable.GetActionRefs() {
+			actionName := toActions[actionRef.GetId()]
+			if !fromActionRefs[actionName] {
+				diff.add(TableKind, name, Modified, false, fmt.Sprintf("action %s is supported", actionName))
+			}
+		}
+
+		fromProfile := fromProfiles[fromTable.GetImplementationId()]
+		toProfile := toProfiles[toTable.GetImplementationId()]
+		if fromProfile != toProfile {
+			diff.add(TableKind, name, Modified, true, fmt.Sprintf("implementation changed from %q to %q", fromProfile, toProfile))
+		}
+		if toTable.GetSize() < fromTable.GetSize() {
+			diff.add(TableKind, name, Modified, false, fmt.Sprintf("size decreased from %d to %d", fromTable.GetSize(), toTable.GetSize()))
+		}
+		if toTable.GetIsConstTable() && !fromTable.GetIsConstTable() {
+			diff.add(TableKind, name, Modified, true, "table is const")
+		}
+	}
+	for _, toTable := range to.GetTables() {
+		if !fromByName[toTable.GetPreamble().GetName()] && !hasTableID(from.GetTables(), toTable.GetPreamble().GetId()) {
+			diff.add(TableKind, toTable.GetPreamble().GetName(), Added, false, "")
+		}
+	}
+}
+
+func compareMatchFields(diff *Diff, tableName string, from, to *p4configapi.Table) {
+	toByName := make(map[string]*p4configapi.MatchField)
+	toByID := make(map[uint32]*p4configapi.MatchField)
+	for _, matchField := range to.GetMatchFields() {
+		toByName[matchField.GetName()] = matchField
+		toByID[matchField.GetId()] = matchField
+	}
+	fromByName := make(map[string]bool)
+	for _, fromField := range from.GetMatchFields() {
+		fromByName[fromField.GetName()] = true
+	}
+	// matched are the match fields of the new table matching a field of the previous one by name or by ID
+	matched := make(map[string]bool)
+	for _, fromField := range from.GetMatchFields() {
+		name := tableName + "/" + fromField.GetName()
+		toField, ok := toByName[fromField.GetName()]
+		if !ok {
+			if renamed, ok := toByID[fromField.GetId()]; ok && !fromByName[renamed.GetName()] {
+				diff.add(MatchFieldKind, name, Renamed, true, fmt.Sprintf("renamed to %s", renamed.GetName()))
+				toField = renamed
+			} else {
+				diff.add(MatchFieldKind, name, Removed, true, "")
+				continue
+			}
+		}
+		matched[toField.GetName()] = true
+		if fromField.GetId() != toField.GetId() {
+			diff.add(MatchFieldKind, name, Modified, true, fmt.Sprintf("ID changed from %d to %d", fromField.GetId(), toField.GetId()))
+		}
+		if fromField.GetBitwidth() != toField.GetBitwidth() {
+			diff.add(MatchFieldKind, name, Modified, true, fmt.Sprintf("bitwidth changed from %d to %d", fromField.GetBitwidth(), toField.GetBitwidth()))
+		}
+		if fromField.GetMatchType() != toField.GetMatchType() || fromField.GetOtherMatchType() != toField.GetOtherMatchType() {
+			diff.add(MatchFieldKind, name, Modified, true, fmt.Sprintf("match type changed from %s to %s", fromField.GetMatchType(), toField.GetMatchType()))
+		}
+	}
+	for _, toField := range to.GetMatchFields() {
+		if matched[toField.GetName()] {
+			continue
+		}
+		// Exact match fields must be set in every entry, whereas the other match kinds can be omitted
+		breaking := toField.GetMatchType() == p4configapi.MatchField_EXACT
+		diff.add(MatchFieldKind, tableName+"/"+toField.GetName(), Added, breaking, "")
+	}
+}
+
+func compareActionProfiles(diff *Diff, from, to *p4configapi.P4Info, fromTables, toTables map[uint32]string) {
+	toByName := make(map[string]*p4configapi.ActionProfile)
+	toByID := make(map[uint32]*p4configapi.ActionProfile)
+	for _, actionProfile := range to.GetActionProfiles() {
+		toByName[actionProfile.GetPreamble().GetName()] = actionProfile
+		toByID[actionProfile.GetPreamble().GetId()] = actionProfile
+	}
+	toTableNames := make(map[string]bool)
+	for _, tableName := range toTables {
+		toTableNames[tableName] = true
+	}
+	fromByName := make(map[string]bool)
+	for _, fromProfile := range from.GetActionProfiles() {
+		name := fromProfile.GetPreamble().GetName()
+		fromByName[name] = true
+		toProfile, ok := toByName[name]
+		if !ok {
+			if renamed, ok := toByID[fromProfile.GetPreamble().GetId()]; ok {
+				diff.add(ActionProfileKind, name, Renamed, true, fmt.Sprintf("renamed to %s", renamed.GetPreamble().GetName()))
+			} else {
+				diff.add(ActionProfileKind, name, Removed, true, "")
+			}
+			continue
+		}
+		if fromProfile.GetPreamble().GetId() != toProfile.GetPreamble().GetId() {
+			diff.add(ActionProfileKind, name, Modified, true, fmt.Sprintf("ID changed from %d to %d", fromProfile.GetPreamble().GetId(), toProfile.GetPreamble().GetId()))
+		}
+		if fromProfile.GetWithSelector() != toProfile.GetWithSelector() {
+			diff.add(ActionProfileKind, name, Modified, true, fmt.Sprintf("with_selector changed from %t to %t", fromProfile.GetWithSelector(), toProfile.GetWithSelector()))
+		}
+		if toProfile.GetMaxGroupSize() != 0 && (fromProfile.GetMaxGroupSize() == 0 || toProfile.GetMaxGroupSize() < fromProfile.GetMaxGroupSize()) {
+			diff.add(ActionProfileKind, name, Modified, true, fmt.Sprintf("max group size decreased from %d to %d", fromProfile.GetMaxGroupSize(), toProfile.GetMaxGroupSize()))
+		}
+		if toProfile.GetSize() < fromProfile.GetSize() {
+			diff.add(ActionProfileKind, name, Modified, false, fmt.Sprintf("size decreased from %d to %d", fromProfile.GetSize(), toProfile.GetSize()))
+		}
+		toProfileTables := make(map[string]bool)
+		for _, tableID := range toProfile.GetTableIds() {
+			toProfileTables[toTables[tableID]] = true
+		}
+		for _, tableID := range fromProfile.GetTableIds() {
+			// Removed and renamed tables are already reported as breaking changes
+			if tableName := fromTables[tableID]; toTableNames[tableName] && !toProfileTables[tableName] {
+				diff.add(ActionProfileKind, name, Modified, true, fmt.Sprintf("table %s no longer uses the action profile", tableName))
+			}
+		}
+	}
+	for _, toProfile := range to.GetActionProfiles() {
+		if !fromByName[toProfile.GetPreamble().GetName()] && !hasActionProfileID(from.GetActionProfiles(), toProfile.GetPreamble().GetId()) {
+			diff.add(ActionProfileKind, toProfile.GetPreamble().GetName(), Added, false, "")
+		}
+	}
+}
+
+func actionNames(p4Info *p4configapi.P4Info) map[uint32]string {
+	names := make(map[uint32]string)
+	for _, action := range p4Info.GetActions() {
+		names[action.GetPreamble().GetId()] = action.GetPreamble().GetName()
+	}
+	return names
+}
+
+func tableNames(p4Info *p4configapi.P4Info) map[uint32]string {
+	names := make(map[uint32]string)
+	for _, table := range p4Info.GetTables() {
+		names[table.GetPreamble().GetId()] = table.GetPreamble().GetName()
+	}
+	return names
+}
+
+func actionProfileNames(p4Info *p4configapi.P4Info) map[uint32]string {
+	names := make(map[uint32]string)
+	for _, actionProfile := range p4Info.GetActionProfiles() {
+		names[actionProfile.GetPreamble().GetId()] = actionProfile.GetPreamble().GetName()
+	}
+	return names
+}
+
+func hasID(actions []*p4configapi.Action, id uint32) bool {
+	for _, action := range actions {
+		if action.GetPreamble().GetId() == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasTableID(tables []*p4configapi.Table, id uint32) bool {
+	for _, table := range tables {
+		if table.GetPreamble().GetId() == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasActionProfileID(actionProfiles []*p4configapi.ActionProfile, id uint32) bool {
+	for _, actionProfile := range actionProfiles {
+		if actionProfile.GetPreamble().GetId() == id {
+			return true
+		}
+	}
+	return false
+}
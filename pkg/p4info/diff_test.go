@@ -0,0 +1,148 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4info
+
+import (
+	"testing"
+
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func newTestP4Info() *p4configapi.P4Info {
+	return &p4configapi.P4Info{
+		Tables: []*p4configapi.Table{
+			{
+				Preamble: &p4configapi.Preamble{Id: 1, Name: "ingress.wcmp_table"},
+				MatchFields: []*p4configapi.MatchField{
+					{Id: 1, Name: "hdr.ipv4.dst_addr", Bitwidth: 32, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_LPM}},
+				},
+				ActionRefs:       []*p4configapi.ActionRef{{Id: 10}},
+				ImplementationId: 100,
+				Size:             1024,
+			},
+		},
+		Actions: []*p4configapi.Action{
+			{
+				Preamble: &p4configapi.Preamble{Id: 10, Name: "ingress.set_next_hop"},
+				Params:   []*p4configapi.Action_Param{{Id: 1, Name: "port", Bitwidth: 9}},
+			},
+		},
+		ActionProfiles: []*p4configapi.ActionProfile{
+			{
+				Preamble:     &p4configapi.Preamble{Id: 100, Name: "ingress.wcmp_selector"},
+				TableIds:     []uint32{1},
+				WithSelector: true,
+				Size:         64,
+				MaxGroupSize: 16,
+			},
+		},
+	}
+}
+
+func TestCompareIdentical(t *testing.T) {
+	diff := Compare(newTestP4Info(), newTestP4Info())
+	assert.Empty(t, diff.Changes)
+	assert.True(t, diff.IsCompatible())
+}
+
+func TestCompareCompatible(t *testing.T) {
+	from := newTestP4Info()
+	to := newTestP4Info()
+	to.Tables[0].MatchFields = append(to.Tables[0].MatchFields, &p4configapi.MatchField{
+		Id: 2, Name: "hdr.ipv4.src_addr", Bitwidth: 32, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_TERNARY},
+	})
+	to.Actions = append(to.Actions, &p4configapi.Action{Preamble: &p4configapi.Preamble{Id: 11, Name: "ingress.drop"}})
+	to.Tables[0].ActionRefs = append(to.Tables[0].ActionRefs, &p4configapi.ActionRef{Id: 11})
+
+	diff := Compare(from, to)
+	assert.True(t, diff.IsCompatible())
+	assert.Len(t, diff.Changes, 3)
+}
+
+func TestCompareBreaking(t *testing.T) {
+	from := newTestP4Info()
+
+	to := proto.Clone(from).(*p4configapi.P4Info)
+	to.Tables[0].Preamble.Name = "ingress.ecmp_table"
+	diff := Compare(from, to)
+	assert.False(t, diff.IsCompatible())
+	assert.Len(t, diff.BreakingChanges(), 1)
+	assert.Equal(t, Renamed, diff.BreakingChanges()[0].Type)
+	assert.Equal(t, TableKind, diff.BreakingChanges()[0].Kind)
+
+	to = proto.Clone(from).(*p4configapi.P4Info)
+	to.Actions = nil
+	to.Tables[0].ActionRefs = nil
+	diff = Compare(from, to)
+	assert.False(t, diff.IsCompatible())
+	assert.Equal(t, Removed, diff.BreakingChanges()[0].Type)
+	assert.Equal(t, ActionKind, diff.BreakingChanges()[0].Kind)
+
+	to = proto.Clone(from).(*p4configapi.P4Info)
+	to.Tables[0].MatchFields[0].Match = &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_EXACT}
+	diff = Compare(from, to)
+	assert.False(t, diff.IsCompatible())
+	assert.Equal(t, MatchFieldKind, diff.BreakingChanges()[0].Kind)
+	assert.Equal(t, "ingress.wcmp_table/hdr.ipv4.dst_addr", diff.BreakingChanges()[0].Name)
+
+	to = proto.Clone(from).(*p4configapi.P4Info)
+	to.Tables[0].MatchFields = append(to.Tables[0].MatchFields, &p4configapi.MatchField{
+		Id: 2, Name: "standard_metadata.ingress_port", Bitwidth: 9, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_EXACT},
+	})
+	diff = Compare(from, to)
+	assert.False(t, diff.IsCompatible())
+	assert.Equal(t, Added, diff.BreakingChanges()[0].Type)
+
+	to = proto.Clone(from).(*p4configapi.P4Info)
+	to.Actions[0].Params[0].Bitwidth = 16
+	diff = Compare(from, to)
+	assert.False(t, diff.IsCompatible())
+	assert.Equal(t, ActionParamKind, diff.BreakingChanges()[0].Kind)
+
+	// Entities are referred to by ID, so ID changes are breaking
+	to = proto.Clone(from).(*p4configapi.P4Info)
+	to.Tables[0].Preamble.Id = 2
+	to.ActionProfiles[0].TableIds = []uint32{2}
+	diff = Compare(from, to)
+	assert.False(t, diff.IsCompatible())
+	assert.Len(t, diff.BreakingChanges(), 1)
+	assert.Equal(t, "ID changed from 1 to 2", diff.BreakingChanges()[0].Details)
+
+	// Renamed match fields and params are not reported as removed and added
+	to = proto.Clone(from).(*p4configapi.P4Info)
+	to.Tables[0].MatchFields[0].Name = "hdr.ipv4.dst"
+	to.Actions[0].Params[0].Name = "egress_port"
+	diff = Compare(from, to)
+	assert.Len(t, diff.Changes, 2)
+	assert.Equal(t, Change{Kind: ActionParamKind, Name: "ingress.set_next_hop/port", Type: Renamed, Breaking: true, Details: "renamed to egress_port"}, diff.Changes[0])
+	assert.Equal(t, Change{Kind: MatchFieldKind, Name: "ingress.wcmp_table/hdr.ipv4.dst_addr", Type: Renamed, Breaking: true, Details: "renamed to hdr.ipv4.dst"}, diff.Changes[1])
+
+	to = proto.Clone(from).(*p4configapi.P4Info)
+	to.ActionProfiles[0].WithSelector = false
+	to.ActionProfiles[0].MaxGroupSize = 8
+	diff = Compare(from, to)
+	assert.False(t, diff.IsCompatible())
+	assert.Len(t, diff.BreakingChanges(), 2)
+	assert.Equal(t, ActionProfileKind, diff.BreakingChanges()[0].Kind)
+}
+
+func TestDiffFilter(t *testing.T) {
+	from := newTestP4Info()
+	to := proto.Clone(from).(*p4configapi.P4Info)
+	to.Tables[0].MatchFields[0].Bitwidth = 128
+	to.Actions = append(to.Actions, &p4configapi.Action{Preamble: &p4configapi.Preamble{Id: 11, Name: "ingress.drop"}})
+	to.ActionProfiles[0].Size = 32
+	diff := Compare(from, to)
+	assert.Len(t, diff.Changes, 3)
+
+	// Match field changes affect their table
+	filtered := diff.Filter("ingress.wcmp_table", "ingress.set_next_hop")
+	assert.Len(t, filtered.Changes, 1)
+	assert.Equal(t, "ingress.wcmp_table/hdr.ipv4.dst_addr", filtered.Changes[0].Name)
+	assert.Empty(t, diff.Filter("ingress.set_next_hop").Changes)
+	assert.Len(t, diff.Filter("ingress.wcmp_selector", "ingress.drop").Changes, 2)
+}
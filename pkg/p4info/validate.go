@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4info
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+)
+
+// ValidatePkgInfo checks the PkgInfo identifies a P4 program
+func ValidatePkgInfo(pkgInfo *p4configapi.PkgInfo) error {
+	if pkgInfo == nil {
+		return errors.NewInvalid("missing PkgInfo")
+	}
+	var missing []string
+	if pkgInfo.Name == "" {
+		missing = append(missing, "name")
+	}
+	if pkgInfo.Version == "" {
+		missing = append(missing, "version")
+	}
+	if pkgInfo.Arch == "" {
+		missing = append(missing, "arch")
+	}
+	if len(missing) > 0 {
+		return errors.NewInvalid("PkgInfo is missing %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Validate checks the P4Info is well-formed: entity IDs are unique and in the range of their
+// entity kind, and the entities referenced by tables and action profiles exist
+func Validate(p4Info *p4configapi.P4Info) error {
+	if p4Info == nil {
+		return errors.NewInvalid("missing P4Info")
+	}
+	v := &validator{
+		ids:   make(map[uint32]string),
+		names: make(map[p4configapi.P4Ids_Prefix]map[string]bool),
+	}
+	v.validate(p4Info)
+	if len(v.problems) > 0 {
+		return errors.NewInvalid("invalid P4Info: %s", strings.Join(v.problems, "; "))
+	}
+	return nil
+}
+
+type validator struct {
+	// ids are the names of the entities by ID
+	ids      map[uint32]string
+	names    map[p4configapi.P4Ids_Prefix]map[string]bool
+	problems []string
+}
+
+func (v *validator) addProblem(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) addEntity(prefix p4configapi.P4Ids_Prefix, preamble *p4configapi.Preamble) {
+	if preamble == nil {
+		v.addProblem("%s without preamble", kindName(prefix))
+		return
+	}
+	if preamble.Name == "" {
+		v.addProblem("%s %d has no name", kindName(prefix), preamble.Id)
+	}
+	if p4configapi.P4Ids_Prefix(preamble.Id>>24) != prefix {
+		v.addProblem("%s '%s' ID 0x%08x is not in the %s ID range", kindName(prefix), preamble.Name, preamble.Id, prefix)
+	}
+	if name, ok := v.ids[preamble.Id]; ok {
+		v.addProblem("%s '%s' ID 0x%08x is already used by '%s'", kindName(prefix), preamble.Name, preamble.Id, name)
+	} else {
+		v.ids[preamble.Id] = preamble.Name
+	}
+	names, ok := v.names[prefix]
+	if !ok {
+		names = make(map[string]bool)
+		v.names[prefix] = names
+	}
+	if names[preamble.Name] {
+		v.addProblem("duplicate %s name '%s'", kindName(prefix), preamble.Name)
+	}
+	names[preamble.Name] = true
+}
+
+func (v *validator) validate(p4Info *p4configapi.P4Info) {
+	tables := make(map[uint32]bool)
+	for _, table := range p4Info.Tables {
+		v.addEntity(p4configapi.P4Ids_TABLE, table.Preamble)
+		tables[table.Preamble.GetId()] = true
+	}
+	actions := make(map[uint32]bool)
+	for _, action := range p4Info.Actions {
+		v.addEntity(p4configapi.P4Ids_ACTION, action.Preamble)
+		actions[action.Preamble.GetId()] = true
+	}
+	actionProfiles := make(map[uint32]bool)
+	for _, actionProfile := range p4Info.ActionProfiles {
+		v.addEntity(p4configapi.P4Ids_ACTION_PROFILE, actionProfile.Preamble)
+		actionProfiles[actionProfile.Preamble.GetId()] = true
+	}
+	directResources := make(map[uint32]bool)
+	for _, counter := range p4Info.Counters {
+		v.addEntity(p4configapi.P4Ids_COUNTER, counter.Preamble)
+	}
+	for _, counter := range p4Info.DirectCounters {
+		v.addEntity(p4configapi.P4Ids_DIRECT_COUNTER, counter.Preamble)
+		directResources[counter.Preamble.GetId()] = true
+	}
+	for _, meter := range p4Info.Meters {
+		v.addEntity(p4configapi.P4Ids_METER, meter.Preamble)
+	}
+	for _, meter := range p4Info.DirectMeters {
+		v.addEntity(p4configapi.P4Ids_DIRECT_METER, meter.Preamble)
+		directResources[meter.Preamble.GetId()] = true
+	}
+	for _, metadata := range p4Info.ControllerPacketMetadata {
+		v.addEntity(p4configapi.P4Ids_CONTROLLER_HEADER, metadata.Preamble)
+	}
+	for _, valueSet := range p4Info.ValueSets {
+		v.addEntity(p4configapi.P4Ids_VALUE_SET, valueSet.Preamble)
+	}
+	for _, register := range p4Info.Registers {
+		v.addEntity(p4configapi.P4Ids_REGISTER, register.Preamble)
+	}
+	for _, digest := range p4Info.Digests {
+		v.addEntity(p4configapi.P4Ids_DIGEST, digest.Preamble)
+	}
+
+	for _, action := range p4Info.Actions {
+		paramIDs := make(map[uint32]bool)
+		for _, param := range action.Params {
+			if paramIDs[param.Id] {
+				v.addProblem("action '%s' has duplicate param ID %d", action.Preamble.GetName(), param.Id)
+			}
+			paramIDs[param.Id] = true
+		}
+	}
+
+	for _, table := range p4Info.Tables {
+		tableName := table.Preamble.GetName()
+		matchFieldIDs := make(map[uint32]bool)
+		for _, matchField := range table.MatchFields {
+			if matchFieldIDs[matchField.Id] {
+				v.addProblem("table '%s' has duplicate match field ID %d", tableName, matchField.Id)
+			}
+			matchFieldIDs[matchField.Id] = true
+		}
+		actionRefs := make(map[uint32]bool)
+		for _, actionRef := range table.ActionRefs {
+			if !actions[actionRef.Id] {
+				v.addProblem("table '%s' references unknown action 0x%08x", tableName, actionRef.Id)
+			}
+			actionRefs[actionRef.Id] = true
+		}
+		if table.ConstDefaultActionId != 0 && !actionRefs[table.ConstDefaultActionId] {
+			v.addProblem("table '%s' default action 0x%08x is not one of its actions", tableName, table.ConstDefaultActionId)
+		}
+		if table.ImplementationId != 0 && !actionProfiles[table.ImplementationId] {
+			v.addProblem("table '%s' references unknown action profile 0x%08x", tableName, table.ImplementationId)
+		}
+		for _, resourceID := range table.DirectResourceIds {
+			if !directResources[resourceID] {
+				v.addProblem("table '%s' references unknown direct resource 0x%08x", tableName, resourceID)
+			}
+		}
+	}
+
+	for _, actionProfile := range p4Info.ActionProfiles {
+		for _, tableID := range actionProfile.TableIds {
+			if !tables[tableID] {
+				v.addProblem("action profile '%s' references unknown table 0x%08x", actionProfile.Preamble.GetName(), tableID)
+			}
+		}
+	}
+}
+
+func kindName(prefix p4configapi.P4Ids_Prefix) string {
+	return strings.ReplaceAll(strings.ToLower(prefix.String()), "_", " ")
+}
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4info
+
+import (
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newValidTestP4Info() *p4configapi.P4Info {
+	return &p4configapi.P4Info{
+		PkgInfo: &p4configapi.PkgInfo{Name: "wcmp", Version: "1.0.0", Arch: "v1model"},
+		Tables: []*p4configapi.Table{
+			{
+				Preamble: &p4configapi.Preamble{Id: 0x02000001, Name: "ingress.wcmp_table"},
+				MatchFields: []*p4configapi.MatchField{
+					{Id: 1, Name: "hdr.ipv4.dst_addr", Bitwidth: 32, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_LPM}},
+				},
+				ActionRefs:        []*p4configapi.ActionRef{{Id: 0x01000001}, {Id: 0x01000002}},
+				ImplementationId:  0x11000001,
+				DirectResourceIds: []uint32{0x13000001},
+			},
+		},
+		Actions: []*p4configapi.Action{
+			{
+				Preamble: &p4configapi.Preamble{Id: 0x01000001, Name: "ingress.set_next_hop"},
+				Params:   []*p4configapi.Action_Param{{Id: 1, Name: "port", Bitwidth: 9}},
+			},
+			{
+				Preamble: &p4configapi.Preamble{Id: 0x01000002, Name: "ingress.drop"},
+			},
+		},
+		ActionProfiles: []*p4configapi.ActionProfile{
+			{
+				Preamble:     &p4configapi.Preamble{Id: 0x11000001, Name: "ingress.wcmp_selector"},
+				TableIds:     []uint32{0x02000001},
+				WithSelector: true,
+			},
+		},
+		DirectCounters: []*p4configapi.DirectCounter{
+			{
+				Preamble:      &p4configapi.Preamble{Id: 0x13000001, Name: "ingress.wcmp_table_counter"},
+				DirectTableId: 0x02000001,
+			},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(newValidTestP4Info()))
+	assert.True(t, errors.IsInvalid(Validate(nil)))
+
+	tests := []struct {
+		name    string
+		mutate  func(p4Info *p4configapi.P4Info)
+		problem string
+	}{
+		{
+			name: "duplicate ID",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.Actions[1].Preamble.Id = 0x01000001
+			},
+			problem: "is already used by 'ingress.set_next_hop'",
+		},
+		{
+			name: "ID out of range",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.Tables[0].Preamble.Id = 1
+				p4Info.ActionProfiles[0].TableIds = []uint32{1}
+			},
+			problem: "is not in the TABLE ID range",
+		},
+		{
+			name: "duplicate name",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.Actions[1].Preamble.Name = "ingress.set_next_hop"
+			},
+			problem: "duplicate action name",
+		},
+		{
+			name: "unknown action",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.Actions = p4Info.Actions[:1]
+			},
+			problem: "references unknown action 0x01000002",
+		},
+		{
+			name: "default action not in table",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.Tables[0].ActionRefs = p4Info.Tables[0].ActionRefs[:1]
+				p4Info.Tables[0].ConstDefaultActionId = 0x01000002
+			},
+			problem: "is not one of its actions",
+		},
+		{
+			name: "unknown action profile",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.ActionProfiles = nil
+			},
+			problem: "references unknown action profile 0x11000001",
+		},
+		{
+			name: "unknown action profile table",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.ActionProfiles[0].TableIds = []uint32{0x02000002}
+			},
+			problem: "references unknown table 0x02000002",
+		},
+		{
+			name: "unknown direct resource",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.DirectCounters = nil
+			},
+			problem: "references unknown direct resource 0x13000001",
+		},
+		{
+			name: "duplicate param ID",
+			mutate: func(p4Info *p4configapi.P4Info) {
+				p4Info.Actions[0].Params = append(p4Info.Actions[0].Params, &p4configapi.Action_Param{Id: 1, Name: "weight"})
+			},
+			problem: "has duplicate param ID 1",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p4Info := newValidTestP4Info()
+			test.mutate(p4Info)
+			err := Validate(p4Info)
+			assert.True(t, errors.IsInvalid(err))
+			assert.Contains(t, err.Error(), test.problem)
+		})
+	}
+}
+
+func TestValidatePkgInfo(t *testing.T) {
+	assert.NoError(t, ValidatePkgInfo(&p4configapi.PkgInfo{Name: "wcmp", Version: "1.0.0", Arch: "v1model"}))
+	assert.True(t, errors.IsInvalid(ValidatePkgInfo(nil)))
+	err := ValidatePkgInfo(&p4configapi.PkgInfo{Name: "wcmp"})
+	assert.True(t, errors.IsInvalid(err))
+	assert.Contains(t, err.Error(), "version, arch")
+}
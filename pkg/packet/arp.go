@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package packet
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+)
+
+const (
+	// ARPRequest is the operation of ARP requests
+	ARPRequest uint16 = 1
+	// ARPReply is the operation of ARP replies
+	ARPReply uint16 = 2
+)
+
+const (
+	arpLen             = 28
+	arpHardwareEther   = 1
+	arpHardwareAddrLen = 6
+	arpProtocolAddrLen = 4
+)
+
+// ARP is an ARP packet resolving IPv4 addresses to Ethernet addresses
+type ARP struct {
+	Operation uint16
+	SenderMAC net.HardwareAddr
+	SenderIP  net.IP
+	TargetMAC net.HardwareAddr
+	TargetIP  net.IP
+}
+
+// ParseARP parses an ARP packet. Only Ethernet/IPv4 packets are supported.
+func ParseARP(data []byte) (*ARP, error) {
+	if len(data) < arpLen {
+		return nil, errors.NewInvalid("ARP packet too short: %d bytes", len(data))
+	}
+	if binary.BigEndian.Uint16(data[0:2]) != arpHardwareEther ||
+		binary.BigEndian.Uint16(data[2:4]) != EtherTypeIPv4 ||
+		data[4] != arpHardwareAddrLen || data[5] != arpProtocolAddrLen {
+		return nil, errors.NewInvalid("unsupported ARP hardware or protocol type")
+	}
+	return &ARP{
+		Operation: binary.BigEndian.Uint16(data[6:8]),
+		SenderMAC: net.HardwareAddr(data[8:14]),
+		SenderIP:  net.IP(data[14:18]),
+		TargetMAC: net.HardwareAddr(data[18:24]),
+		TargetIP:  net.IP(data[24:28]),
+	}, nil
+}
+
+// Serialize returns the bytes of the ARP packet
+func (a *ARP) Serialize() []byte {
+	data := make([]byte, arpLen)
+	binary.BigEndian.PutUint16(data[0:2], arpHardwareEther)
+	binary.BigEndian.PutUint16(data[2:4], EtherTypeIPv4)
+	data[4] = arpHardwareAddrLen
+	data[5] = arpProtocolAddrLen
+	binary.BigEndian.PutUint16(data[6:8], a.Operation)
+	copy(data[8:14], a.SenderMAC)
+	copy(data[14:18], a.SenderIP.To4())
+	copy(data[18:24], a.TargetMAC)
+	copy(data[24:28], a.TargetIP.To4())
+	return data
+}
@@ -0,0 +1,33 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package packet
+
+import (
+	"net"
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestARP(t *testing.T) {
+	arp := &ARP{
+		Operation: ARPRequest,
+		SenderMAC: net.HardwareAddr{0x02, 0, 0, 0, 0, 1},
+		SenderIP:  net.IPv4(10, 0, 0, 1).To4(),
+		TargetMAC: net.HardwareAddr{0, 0, 0, 0, 0, 0},
+		TargetIP:  net.IPv4(10, 0, 0, 2).To4(),
+	}
+	parsedARP, err := ParseARP(arp.Serialize())
+	assert.NoError(t, err)
+	assert.Equal(t, arp, parsedARP)
+
+	_, err = ParseARP(arp.Serialize()[:20])
+	assert.True(t, errors.IsInvalid(err))
+	data := arp.Serialize()
+	data[3] = 0xdd
+	_, err = ParseARP(data)
+	assert.True(t, errors.IsInvalid(err))
+}
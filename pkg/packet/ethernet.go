@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package packet
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+)
+
+const (
+	// EtherTypeIPv4 is the EtherType of IPv4 packets
+	EtherTypeIPv4 uint16 = 0x0800
+	// EtherTypeARP is the EtherType of ARP packets
+	EtherTypeARP uint16 = 0x0806
+	// EtherTypeVLAN is the EtherType of 802.1Q VLAN tags
+	EtherTypeVLAN uint16 = 0x8100
+	// EtherTypeIPv6 is the EtherType of IPv6 packets
+	EtherTypeIPv6 uint16 = 0x86dd
+	// EtherTypeLLDP is the EtherType of LLDP frames
+	EtherTypeLLDP uint16 = 0x88cc
+	// EtherTypeBDDP is the EtherType of broadcast discovery frames, which are LLDP frames flooded across
+	// non-LLDP-aware segments
+	EtherTypeBDDP uint16 = 0x8942
+)
+
+const (
+	ethernetHeaderLen = 14
+	vlanTagLen        = 4
+)
+
+// BroadcastMAC is the Ethernet broadcast address
+var BroadcastMAC = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// Ethernet is an Ethernet frame
+type Ethernet struct {
+	Dst net.HardwareAddr
+	Src net.HardwareAddr
+	// VLANID is the ID of the 802.1Q VLAN tag of the frame, or zero if the frame is untagged
+	VLANID    uint16
+	EtherType uint16
+	Payload   []byte
+}
+
+// ParseEthernet parses an Ethernet frame
+func ParseEthernet(data []byte) (*Ethernet, error) {
+	if len(data) < ethernetHeaderLen {
+		return nil, errors.NewInvalid("Ethernet frame too short: %d bytes", len(data))
+	}
+	frame := &Ethernet{
+		Dst:       net.HardwareAddr(data[0:6]),
+		Src:       net.HardwareAddr(data[6:12]),
+		EtherType: binary.BigEndian.Uint16(data[12:14]),
+	}
+	offset := ethernetHeaderLen
+	if frame.EtherType == EtherTypeVLAN {
+		if len(data) < ethernetHeaderLen+vlanTagLen {
+			return nil, errors.NewInvalid("VLAN tagged Ethernet frame too short: %d bytes", len(data))
+		}
+		frame.VLANID = binary.BigEndian.Uint16(data[14:16]) & 0x0fff
+		frame.EtherType = binary.BigEndian.Uint16(data[16:18])
+		offset += vlanTagLen
+	}
+	frame.Payload = data[offset:]
+	return frame, nil
+}
+
+// Serialize returns the bytes of the Ethernet frame
+func (e *Ethernet) Serialize() []byte {
+	headerLen := ethernetHeaderLen
+	if e.VLANID != 0 {
+		headerLen += vlanTagLen
+	}
+	data := make([]byte, headerLen+len(e.Payload))
+	copy(data[0:6], e.Dst)
+	copy(data[6:12], e.Src)
+	offset := 12
+	if e.VLANID != 0 {
+		binary.BigEndian.PutUint16(data[12:14], EtherTypeVLAN)
+		binary.BigEndian.PutUint16(data[14:16], e.VLANID&0x0fff)
+		offset += vlanTagLen
+	}
+	binary.BigEndian.PutUint16(data[offset:offset+2], e.EtherType)
+	copy(data[headerLen:], e.Payload)
+	return data
+}
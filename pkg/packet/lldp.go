@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package packet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+)
+
+// LLDPMulticastMAC is the nearest bridge group address LLDP frames are sent to
+var LLDPMulticastMAC = net.HardwareAddr{0x01, 0x80, 0xc2, 0x00, 0x00, 0x0e}
+
+const (
+	lldpEndTLV       = 0
+	lldpChassisIDTLV = 1
+	lldpPortIDTLV    = 2
+	lldpTTLTLV       = 3
+	lldpOrgTLV       = 127
+
+	// lldpLocalSubtype is the chassis and port ID subtype of locally assigned IDs
+	lldpLocalSubtype = 7
+)
+
+// lldpSignatureHeader is the organizationally unique identifier and subtype of the TLV holding the signature
+var lldpSignatureHeader = []byte{0xa4, 0x23, 0x05, 0x01}
+
+// LLDP is an LLDP data unit with locally assigned chassis and port IDs
+type LLDP struct {
+	ChassisID string
+	PortID    string
+	// TTL is the time to live of the advertised information in seconds
+	TTL uint16
+	// Signature authenticates the data unit, it is carried in an organizationally specific TLV
+	Signature []byte
+}
+
+// ParseLLDP parses an LLDP data unit. Optional TLVs other than the signature are ignored.
+func ParseLLDP(data []byte) (*LLDP, error) {
+	lldp := &LLDP{}
+	var hasChassisID, hasPortID, hasTTL bool
+	for len(data) >= 2 {
+		header := binary.BigEndian.Uint16(data[0:2])
+		tlvType := header >> 9
+		tlvLen := int(header & 0x01ff)
+		if len(data) < 2+tlvLen {
+			return nil, errors.NewInvalid("truncated LLDP TLV of type %d", tlvType)
+		}
+		value := data[2 : 2+tlvLen]
+		data = data[2+tlvLen:]
+		switch tlvType {
+		case lldpEndTLV:
+			data = nil
+		case lldpChassisIDTLV:
+			if tlvLen < 2 {
+				return nil, errors.NewInvalid("invalid LLDP chassis ID")
+			}
+			lldp.ChassisID = string(value[1:])
+			hasChassisID = true
+		case lldpPortIDTLV:
+			if tlvLen < 2 {
+				return nil, errors.NewInvalid("invalid LLDP port ID")
+			}
+			lldp.PortID = string(value[1:])
+			hasPortID = true
+		case lldpTTLTLV:
+			if tlvLen != 2 {
+				return nil, errors.NewInvalid("invalid LLDP TTL")
+			}
+			lldp.TTL = binary.BigEndian.Uint16(value)
+			hasTTL = true
+		case lldpOrgTLV:
+			if bytes.HasPrefix(value, lldpSignatureHeader) {
+				lldp.Signature = value[len(lldpSignatureHeader):]
+			}
+		}
+	}
+	if !hasChassisID || !hasPortID || !hasTTL {
+		return nil, errors.NewInvalid("LLDP data unit is missing mandatory TLVs")
+	}
+	return lldp, nil
+}
+
+// Serialize returns the bytes of the LLDP data unit
+func (l *LLDP) Serialize() []byte {
+	data := appendTLV(nil, lldpChassisIDTLV, append([]byte{lldpLocalSubtype}, l.ChassisID...))
+	data = appendTLV(data, lldpPortIDTLV, append([]byte{lldpLocalSubtype}, l.PortID...))
+	ttl := make([]byte, 2)
+	binary.BigEndian.PutUint16(ttl, l.TTL)
+	data = appendTLV(data, lldpTTLTLV, ttl)
+	if len(l.Signature) > 0 {
+		data = appendTLV(data, lldpOrgTLV, append(append([]byte{}, lldpSignatureHeader...), l.Signature...))
+	}
+	return appendTLV(data, lldpEndTLV, nil)
+}
+
+func appendTLV(data []byte, tlvType uint16, value []byte) []byte {
+	header := make([]byte, 2)
+	binary.BigEndian.PutUint16(header, tlvType<<9|uint16(len(value))&0x01ff)
+	data = append(data, header...)
+	return append(data, value...)
+}
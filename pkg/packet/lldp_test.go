@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package packet
+
+import (
+	"net"
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLLDP(t *testing.T) {
+	lldp := &LLDP{ChassisID: "switch-1", PortID: "3", TTL: 120}
+	frame := &Ethernet{
+		Dst:       LLDPMulticastMAC,
+		Src:       net.HardwareAddr{0x02, 0, 0, 0, 0, 1},
+		EtherType: EtherTypeLLDP,
+		Payload:   lldp.Serialize(),
+	}
+
+	parsedFrame, err := ParseEthernet(frame.Serialize())
+	assert.NoError(t, err)
+	assert.Equal(t, frame, parsedFrame)
+	parsedLLDP, err := ParseLLDP(parsedFrame.Payload)
+	assert.NoError(t, err)
+	assert.Equal(t, lldp, parsedLLDP)
+
+	// Signed data units
+	lldp.Signature = []byte{1, 2, 3, 4}
+	parsedLLDP, err = ParseLLDP(lldp.Serialize())
+	assert.NoError(t, err)
+	assert.Equal(t, lldp, parsedLLDP)
+
+	// VLAN tagged frames
+	frame.VLANID = 10
+	parsedFrame, err = ParseEthernet(frame.Serialize())
+	assert.NoError(t, err)
+	assert.Equal(t, frame, parsedFrame)
+
+	_, err = ParseEthernet([]byte{1, 2, 3})
+	assert.True(t, errors.IsInvalid(err))
+	_, err = ParseLLDP(lldp.Serialize()[:10])
+	assert.True(t, errors.IsInvalid(err))
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package packet
+
+import (
+	"encoding/binary"
+	"net"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+)
+
+const (
+	// ICMPv6NeighborSolicitation is the ICMPv6 type of NDP neighbor solicitations
+	ICMPv6NeighborSolicitation uint8 = 135
+	// ICMPv6NeighborAdvertisement is the ICMPv6 type of NDP neighbor advertisements
+	ICMPv6NeighborAdvertisement uint8 = 136
+)
+
+const (
+	ipv6HeaderLen       = 40
+	ipv6ProtocolICMPv6  = 58
+	ndpHopLimit         = 255
+	ndpMessageLen       = 24
+	ndpSourceLinkLayer  = 1
+	ndpTargetLinkLayer  = 2
+	ndpLinkLayerOptLen  = 8
+	ndpFlagSolicited    = 0x40
+	ndpFlagOverride     = 0x20
+	icmpv6ChecksumStart = 2
+)
+
+// NDP is an IPv6 neighbor solicitation or advertisement
+type NDP struct {
+	// SrcIP and DstIP are the addresses of the IPv6 header
+	SrcIP net.IP
+	DstIP net.IP
+	// Type is the ICMPv6 type of the message
+	Type     uint8
+	TargetIP net.IP
+	// LinkLayerAddr is the source link-layer address of solicitations or the target link-layer address
+	// of advertisements, if the message has one
+	LinkLayerAddr net.HardwareAddr
+}
+
+// ParseNDP parses an IPv6 packet carrying an NDP neighbor solicitation or advertisement. Packets with
+// IPv6 extension headers are not supported.
+func ParseNDP(data []byte) (*NDP, error) {
+	if len(data) < ipv6HeaderLen {
+		return nil, errors.NewInvalid("IPv6 packet too short: %d bytes", len(data))
+	}
+	if data[0]>>4 != 6 {
+		return nil, errors.NewInvalid("invalid IPv6 version %d", data[0]>>4)
+	}
+	if data[6] != ipv6ProtocolICMPv6 {
+		return nil, errors.NewInvalid("IPv6 packet is not an ICMPv6 packet")
+	}
+	payloadLen := int(binary.BigEndian.Uint16(data[4:6]))
+	if len(data) < ipv6HeaderLen+payloadLen {
+		return nil, errors.NewInvalid("truncated IPv6 packet")
+	}
+	ndp := &NDP{
+		SrcIP: net.IP(data[8:24]),
+		DstIP: net.IP(data[24:40]),
+	}
+	icmp := data[ipv6HeaderLen : ipv6HeaderLen+payloadLen]
+	if len(icmp) < ndpMessageLen {
+		return nil, errors.NewInvalid("ICMPv6 message too short: %d bytes", len(icmp))
+	}
+	ndp.Type = icmp[0]
+	if ndp.Type != ICMPv6NeighborSolicitation && ndp.Type != ICMPv6NeighborAdvertisement {
+		return nil, errors.NewInvalid("ICMPv6 message of type %d is not a neighbor solicitation or advertisement", ndp.Type)
+	}
+	ndp.TargetIP = net.IP(icmp[8:24])
+
+	options := icmp[ndpMessageLen:]
+	for len(options) >= 2 {
+		optionLen := int(options[1]) * 8
+		if optionLen == 0 || len(options) < optionLen {
+			return nil, errors.NewInvalid("invalid NDP option of type %d", options[0])
+		}
+		if (options[0] == ndpSourceLinkLayer && ndp.Type == ICMPv6NeighborSolicitation ||
+			options[0] == ndpTargetLinkLayer && ndp.Type == ICMPv6NeighborAdvertisement) &&
+			optionLen == ndpLinkLayerOptLen {
+			ndp.LinkLayerAddr = net.HardwareAddr(options[2:8])
+		}
+		options = options[optionLen:]
+	}
+	return ndp, nil
+}
+
+// Serialize returns the bytes of the IPv6 packet carrying the NDP message
+func (n *NDP) Serialize() []byte {
+	icmpLen := ndpMessageLen
+	if n.LinkLayerAddr != nil {
+		icmpLen += ndpLinkLayerOptLen
+	}
+	data := make([]byte, ipv6HeaderLen+icmpLen)
+	data[0] = 6 << 4
+	binary.BigEndian.PutUint16(data[4:6], uint16(icmpLen))
+	data[6] = ipv6ProtocolICMPv6
+	data[7] = ndpHopLimit
+	copy(data[8:24], n.SrcIP.To16())
+	copy(data[24:40], n.DstIP.To16())
+
+	icmp := data[ipv6HeaderLen:]
+	icmp[0] = n.Type
+	if n.Type == ICMPv6NeighborAdvertisement {
+		icmp[4] = ndpFlagSolicited | ndpFlagOverride
+	}
+	copy(icmp[8:24], n.TargetIP.To16())
+	if n.LinkLayerAddr != nil {
+		icmp[24] = ndpSourceLinkLayer
+		if n.Type == ICMPv6NeighborAdvertisement {
+			icmp[24] = ndpTargetLinkLayer
+		}
+		icmp[25] = 1
+		copy(icmp[26:32], n.LinkLayerAddr)
+	}
+	binary.BigEndian.PutUint16(icmp[icmpv6ChecksumStart:icmpv6ChecksumStart+2], icmpv6Checksum(data[8:40], icmp))
+	return data
+}
+
+// icmpv6Checksum computes the checksum of an ICMPv6 message with the IPv6 pseudo header
+func icmpv6Checksum(addrs []byte, icmp []byte) uint16 {
+	var sum uint32
+	add := func(b []byte) {
+		for i := 0; i+1 < len(b); i += 2 {
+			sum += uint32(binary.BigEndian.Uint16(b[i : i+2]))
+		}
+		if len(b)%2 == 1 {
+			sum += uint32(b[len(b)-1]) << 8
+		}
+	}
+	add(addrs)
+	sum += uint32(len(icmp))
+	sum += ipv6ProtocolICMPv6
+	add(icmp)
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
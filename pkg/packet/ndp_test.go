@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package packet
+
+import (
+	"net"
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNDP(t *testing.T) {
+	ns := &NDP{
+		SrcIP:         net.ParseIP("2001:db8::1"),
+		DstIP:         net.ParseIP("ff02::1:ff00:2"),
+		Type:          ICMPv6NeighborSolicitation,
+		TargetIP:      net.ParseIP("2001:db8::2"),
+		LinkLayerAddr: net.HardwareAddr{0x02, 0, 0, 0, 0, 1},
+	}
+	data := ns.Serialize()
+	parsedNS, err := ParseNDP(data)
+	assert.NoError(t, err)
+	assert.Equal(t, ns, parsedNS)
+	// The checksum of a message with a valid checksum is zero
+	assert.Equal(t, uint16(0), icmpv6Checksum(data[8:40], data[ipv6HeaderLen:]))
+
+	na := &NDP{
+		SrcIP:    net.ParseIP("2001:db8::2"),
+		DstIP:    net.ParseIP("2001:db8::1"),
+		Type:     ICMPv6NeighborAdvertisement,
+		TargetIP: net.ParseIP("2001:db8::2"),
+	}
+	parsedNA, err := ParseNDP(na.Serialize())
+	assert.NoError(t, err)
+	assert.Equal(t, na, parsedNA)
+
+	// Other ICMPv6 messages are rejected
+	data = ns.Serialize()
+	data[ipv6HeaderLen] = 128
+	_, err = ParseNDP(data)
+	assert.True(t, errors.IsInvalid(err))
+	_, err = ParseNDP(data[:30])
+	assert.True(t, errors.IsInvalid(err))
+}
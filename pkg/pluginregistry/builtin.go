@@ -0,0 +1,42 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"embed"
+	"io/fs"
+	"path"
+)
+
+// builtinDir is the directory of the P4 plugins compiled into the binary, laid out like a P4 plugin directory
+const builtinDir = "builtin"
+
+//go:embed builtin
+var builtinFS embed.FS
+
+// loadBuiltinPlugins loads the P4 plugins compiled into the binary
+func loadBuiltinPlugins() (map[string]P4Plugin, error) {
+	entries, err := fs.ReadDir(builtinFS, builtinDir)
+	if err != nil {
+		return nil, err
+	}
+	p4Plugins := make(map[string]P4Plugin)
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		pluginDir := path.Join(builtinDir, entry.Name())
+		pluginFS, err := fs.Sub(builtinFS, pluginDir)
+		if err != nil {
+			return nil, err
+		}
+		p4Plugin, err := loadPlugin(pluginFS, pluginDir)
+		if err != nil {
+			return nil, err
+		}
+		p4Plugins[pluginDir] = p4Plugin
+	}
+	return p4Plugins, nil
+}
@@ -0,0 +1,82 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"io/fs"
+	"regexp"
+	"strconv"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/wcmp-app/pkg/p4info"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltinPlugins(t *testing.T) {
+	p4Plugins, err := loadBuiltinPlugins()
+	assert.NoError(t, err)
+	assert.NotEmpty(t, p4Plugins)
+	for _, p4Plugin := range p4Plugins {
+		pkgInfo, err := p4Plugin.GetPkgInfo()
+		assert.NoError(t, err)
+		assert.NoError(t, p4info.ValidatePkgInfo(pkgInfo))
+		p4Info, err := p4Plugin.GetP4Info()
+		assert.NoError(t, err)
+		assert.NoError(t, p4info.Validate(p4Info))
+		_, err = GetWCMPEntities(p4Plugin)
+		assert.NoError(t, err)
+	}
+
+	// The reference WCMP pipeline is registered by default
+	registry := NewP4PluginRegistry()
+	p4Plugin, err := registry.GetPlugin(p4rtapi.NewP4PluginID("wcmp", "1.0.0", "v1model"))
+	assert.NoError(t, err)
+	p4Info, err := p4Plugin.GetP4Info()
+	assert.NoError(t, err)
+	assert.Len(t, p4Info.Tables, 5)
+
+	// Both IPv4 and IPv6 routes are mapped
+	entities, err := GetWCMPEntities(p4Plugin)
+	assert.NoError(t, err)
+	for _, dstAddrBitwidth := range []int32{32, 128} {
+		_, err = entities.GetRouting(dstAddrBitwidth)
+		assert.NoError(t, err)
+	}
+}
+
+// p4IDPattern matches the @id annotations of the P4 source and the name of the annotated entity
+var p4IDPattern = regexp.MustCompile(`@id\((0x[0-9a-f]+)\)\s*(?:@\w+\([^)]*\)\s*)*(?:table|action|direct_counter\([^)]*\)|action_selector\([^)]*\))\s+(\w+)`)
+
+func TestBuiltinWCMPSource(t *testing.T) {
+	source, err := fs.ReadFile(builtinFS, "builtin/wcmp/wcmp.p4")
+	assert.NoError(t, err)
+	sourceIDs := make(map[uint32]string)
+	for _, match := range p4IDPattern.FindAllStringSubmatch(string(source), -1) {
+		id, err := strconv.ParseUint(match[1], 0, 32)
+		assert.NoError(t, err)
+		sourceIDs[uint32(id)] = match[2]
+	}
+
+	// Every entity of the P4Info is declared in the P4 source with the same ID
+	p4Plugins, err := loadBuiltinPlugins()
+	assert.NoError(t, err)
+	p4Info, err := p4Plugins["builtin/wcmp"].GetP4Info()
+	assert.NoError(t, err)
+	p4InfoIDs := make(map[uint32]string)
+	for _, table := range p4Info.Tables {
+		p4InfoIDs[table.Preamble.Id] = table.Preamble.Alias
+	}
+	for _, action := range p4Info.Actions {
+		p4InfoIDs[action.Preamble.Id] = action.Preamble.Alias
+	}
+	for _, actionProfile := range p4Info.ActionProfiles {
+		p4InfoIDs[actionProfile.Preamble.Id] = actionProfile.Preamble.Alias
+	}
+	for _, counter := range p4Info.DirectCounters {
+		p4InfoIDs[counter.Preamble.Id] = counter.Preamble.Alias
+	}
+	assert.Equal(t, p4InfoIDs, sourceIDs)
+}
@@ -0,0 +1,61 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+)
+
+// Compatibility declares the targets a P4 plugin can be pushed to
+type Compatibility struct {
+	// ModelIDs are the switch model IDs the plugin is compatible with; any model is compatible if empty
+	ModelIDs []string `json:"modelIDs,omitempty"`
+	// Architectures are the target pipeline architectures the plugin is compatible with; any architecture
+	// is compatible if empty
+	Architectures []string `json:"architectures,omitempty"`
+}
+
+// CompatibilityProvider is implemented by P4 plugins declaring the targets they are compatible with
+type CompatibilityProvider interface {
+	GetCompatibility() (*Compatibility, error)
+}
+
+// CheckCompatibility returns an Invalid error explaining why a P4 plugin cannot be pushed to a switch
+// of the given model running the given architecture. Plugins without compatibility metadata are compatible
+// with any target.
+func CheckCompatibility(p4Plugin P4Plugin, modelID string, arch string) error {
+	provider, ok := p4Plugin.(CompatibilityProvider)
+	if !ok {
+		return nil
+	}
+	compatibility, err := provider.GetCompatibility()
+	if err != nil {
+		return err
+	}
+	if compatibility == nil {
+		return nil
+	}
+	if len(compatibility.ModelIDs) > 0 {
+		if modelID == "" {
+			return errors.NewInvalid("P4 plugin requires one of switch models %v but the target model is unknown", compatibility.ModelIDs)
+		}
+		if !contains(compatibility.ModelIDs, modelID) {
+			return errors.NewInvalid("P4 plugin is not compatible with switch model '%s', supported models are %v", modelID, compatibility.ModelIDs)
+		}
+	}
+	if len(compatibility.Architectures) > 0 && !contains(compatibility.Architectures, arch) {
+		return errors.NewInvalid("P4 plugin is not compatible with architecture '%s', supported architectures are %v", arch, compatibility.Architectures)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
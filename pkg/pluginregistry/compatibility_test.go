@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	// Plugins without compatibility metadata are compatible with any target
+	assert.NoError(t, CheckCompatibility(&filePlugin{}, "", "v1model"))
+
+	p4Plugin := &filePlugin{
+		compatibility: &Compatibility{
+			ModelIDs:      []string{"bmv2"},
+			Architectures: []string{"v1model"},
+		},
+	}
+	assert.NoError(t, CheckCompatibility(p4Plugin, "bmv2", "v1model"))
+	assert.True(t, errors.IsInvalid(CheckCompatibility(p4Plugin, "tofino", "v1model")))
+	assert.True(t, errors.IsInvalid(CheckCompatibility(p4Plugin, "", "v1model")))
+	assert.True(t, errors.IsInvalid(CheckCompatibility(p4Plugin, "bmv2", "tna")))
+}
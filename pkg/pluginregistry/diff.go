@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/wcmp-app/pkg/p4info"
+)
+
+// ComparePlugins compares the P4Info of two registered P4 plugins and classifies the changes
+// from the first to the second one as compatible or breaking
+func ComparePlugins(registry P4PluginRegistry, from, to p4rtapi.P4PluginID) (*p4info.Diff, error) {
+	fromPlugin, err := registry.GetPlugin(from)
+	if err != nil {
+		return nil, err
+	}
+	toPlugin, err := registry.GetPlugin(to)
+	if err != nil {
+		return nil, err
+	}
+	fromP4Info, err := fromPlugin.GetP4Info()
+	if err != nil {
+		return nil, err
+	}
+	toP4Info, err := toPlugin.GetP4Info()
+	if err != nil {
+		return nil, err
+	}
+	return p4info.Compare(fromP4Info, toP4Info), nil
+}
+
+// CompareWCMPPlugins compares the P4Info of two registered P4 plugins and returns the changes affecting
+// the entities of their WCMP mappings. Changes to the other entities do not affect WCMP routing.
+func CompareWCMPPlugins(registry P4PluginRegistry, from, to p4rtapi.P4PluginID) (*p4info.Diff, error) {
+	diff, err := ComparePlugins(registry, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, id := range []p4rtapi.P4PluginID{from, to} {
+		p4Plugin, err := registry.GetPlugin(id)
+		if err != nil {
+			return nil, err
+		}
+		mapping, err := getWCMPMapping(p4Plugin)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, mapping.entityNames()...)
+	}
+	return diff.Filter(names...), nil
+}
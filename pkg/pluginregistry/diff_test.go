@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"encoding/json"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/p4info"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+func newTestWCMPPlugin(t *testing.T, version string, p4Info *p4configapi.P4Info, portParam string) *filePlugin {
+	mapping := &WCMPMapping{}
+	assert.NoError(t, json.Unmarshal([]byte(testWCMPMapping), mapping))
+	mapping.NextHop.PortParam = portParam
+	return &filePlugin{
+		pkgInfo:     &p4configapi.PkgInfo{Name: "test", Version: version, Arch: "v1model"},
+		p4Info:      p4Info,
+		wcmpMapping: mapping,
+	}
+}
+
+func TestComparePlugins(t *testing.T) {
+	registry := NewP4PluginRegistry().(*pluginRegistry)
+	from := &p4configapi.P4Info{}
+	assert.NoError(t, prototext.Unmarshal([]byte(testWCMPP4InfoText), from))
+
+	// The next hop action param is renamed and a table unrelated to WCMP routing is added
+	to := &p4configapi.P4Info{}
+	assert.NoError(t, prototext.Unmarshal([]byte(testWCMPP4InfoText), to))
+	to.Actions[1].Params[0].Name = "port"
+	to.Tables = append(to.Tables, &p4configapi.Table{
+		Preamble: &p4configapi.Preamble{Id: 33554436, Name: "ingress.acl"},
+		MatchFields: []*p4configapi.MatchField{
+			{Id: 1, Name: "hdr.ethernet.ether_type", Bitwidth: 16, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_EXACT}},
+		},
+	})
+	assert.NoError(t, registry.register("test", newTestWCMPPlugin(t, "1.0.0", from, "port_num")))
+	assert.NoError(t, registry.register("test", newTestWCMPPlugin(t, "2.0.0", to, "port")))
+	fromID := p4rtapi.NewP4PluginID("test", "1.0.0", "v1model")
+	toID := p4rtapi.NewP4PluginID("test", "2.0.0", "v1model")
+
+	diff, err := ComparePlugins(registry, fromID, toID)
+	assert.NoError(t, err)
+	assert.False(t, diff.IsCompatible())
+	assert.Len(t, diff.Changes, 2)
+	assert.Equal(t, p4info.Change{
+		Kind:     p4info.ActionParamKind,
+		Name:     "ingress.route_hashed/port_num",
+		Type:     p4info.Renamed,
+		Breaking: true,
+		Details:  "renamed to port",
+	}, diff.Changes[0])
+	assert.Equal(t, p4info.TableKind, diff.Changes[1].Kind)
+	assert.Equal(t, p4info.Added, diff.Changes[1].Type)
+
+	// Only the changes to the entities of the WCMP mappings are kept
+	diff, err = CompareWCMPPlugins(registry, fromID, toID)
+	assert.NoError(t, err)
+	assert.Len(t, diff.Changes, 1)
+	assert.Equal(t, "ingress.route_hashed/port_num", diff.Changes[0].Name)
+
+	_, err = ComparePlugins(registry, fromID, "test-3.0.0-v1model")
+	assert.True(t, errors.IsNotFound(err))
+}
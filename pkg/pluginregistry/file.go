@@ -0,0 +1,169 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"encoding/json"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ManifestFile is the name of the manifest file of a P4 plugin directory
+	ManifestFile = "manifest.json"
+	// defaultP4InfoFile is the P4Info file used when the manifest does not specify one
+	defaultP4InfoFile = "p4info.txt"
+)
+
+// Manifest describes the P4 artifacts of a P4 plugin directory
+type Manifest struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Arch    string `json:"arch"`
+	// P4Info is the path of the P4Info file relative to the plugin directory; files with a .txt or .pbtxt
+	// extension are in protobuf text format, other files are in protobuf binary format
+	P4Info string `json:"p4info,omitempty"`
+	// DeviceConfig is the path of the P4 device config file relative to the plugin directory
+	DeviceConfig string `json:"deviceConfig,omitempty"`
+	// Compatibility optionally restricts the targets the plugin can be pushed to
+	Compatibility *Compatibility `json:"compatibility,omitempty"`
+	// WCMPMapping is the optional path of the WCMP mapping file relative to the plugin directory
+	WCMPMapping string `json:"wcmpMapping,omitempty"`
+}
+
+// filePlugin is a P4 plugin loaded from the files of a P4 plugin directory
+type filePlugin struct {
+	pkgInfo       *p4configapi.PkgInfo
+	p4Info        *p4configapi.P4Info
+	deviceConfig  []byte
+	compatibility *Compatibility
+	wcmpMapping   *WCMPMapping
+}
+
+func (p *filePlugin) GetPkgInfo() (*p4configapi.PkgInfo, error) {
+	return p.pkgInfo, nil
+}
+
+func (p *filePlugin) GetP4DeviceConfig() ([]byte, error) {
+	return p.deviceConfig, nil
+}
+
+func (p *filePlugin) GetP4Info() (*p4configapi.P4Info, error) {
+	return p.p4Info, nil
+}
+
+func (p *filePlugin) GetCompatibility() (*Compatibility, error) {
+	return p.compatibility, nil
+}
+
+func (p *filePlugin) GetWCMPMapping() (*WCMPMapping, error) {
+	return p.wcmpMapping, nil
+}
+
+// LoadFilePlugin loads a P4 plugin from a directory containing a manifest, a P4Info file and
+// an optional P4 device config file
+func LoadFilePlugin(dir string) (P4Plugin, error) {
+	return loadPlugin(os.DirFS(dir), dir)
+}
+
+// loadPlugin loads a P4 plugin from the files of a file system; the name of the file system is only used in errors
+func loadPlugin(fsys fs.FS, name string) (P4Plugin, error) {
+	manifestBytes, err := fs.ReadFile(fsys, ManifestFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.NewNotFound("no P4 plugin manifest found in %s", name)
+		}
+		return nil, err
+	}
+	manifest := Manifest{}
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, errors.NewInvalid("invalid P4 plugin manifest in %s: %v", name, err)
+	}
+	if manifest.Name == "" || manifest.Version == "" || manifest.Arch == "" {
+		return nil, errors.NewInvalid("P4 plugin manifest in %s must specify a name, version and arch", name)
+	}
+
+	p4InfoFile := manifest.P4Info
+	if p4InfoFile == "" {
+		p4InfoFile = defaultP4InfoFile
+	}
+	p4InfoBytes, err := fs.ReadFile(fsys, p4InfoFile)
+	if err != nil {
+		return nil, err
+	}
+	p4Info := &p4configapi.P4Info{}
+	switch strings.ToLower(filepath.Ext(p4InfoFile)) {
+	case ".txt", ".pbtxt":
+		err = prototext.Unmarshal(p4InfoBytes, p4Info)
+	default:
+		err = proto.Unmarshal(p4InfoBytes, p4Info)
+	}
+	if err != nil {
+		return nil, errors.NewInvalid("invalid P4Info %s in %s: %v", p4InfoFile, name, err)
+	}
+
+	deviceConfig := []byte{}
+	if manifest.DeviceConfig != "" {
+		deviceConfig, err = fs.ReadFile(fsys, manifest.DeviceConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var wcmpMapping *WCMPMapping
+	if manifest.WCMPMapping != "" {
+		mappingBytes, err := fs.ReadFile(fsys, manifest.WCMPMapping)
+		if err != nil {
+			return nil, err
+		}
+		wcmpMapping = &WCMPMapping{}
+		if err := json.Unmarshal(mappingBytes, wcmpMapping); err != nil {
+			return nil, errors.NewInvalid("invalid WCMP mapping %s in %s: %v", manifest.WCMPMapping, name, err)
+		}
+	}
+
+	pkgInfo := &p4configapi.PkgInfo{}
+	if p4Info.PkgInfo != nil {
+		pkgInfo = proto.Clone(p4Info.PkgInfo).(*p4configapi.PkgInfo)
+	}
+	pkgInfo.Name = manifest.Name
+	pkgInfo.Version = manifest.Version
+	pkgInfo.Arch = manifest.Arch
+	return &filePlugin{
+		pkgInfo:       pkgInfo,
+		p4Info:        p4Info,
+		deviceConfig:  deviceConfig,
+		compatibility: manifest.Compatibility,
+		wcmpMapping:   wcmpMapping,
+	}, nil
+}
+
+// RegisterFilePlugins registers a P4 plugin for each subdirectory of the given directory containing a manifest
+func RegisterFilePlugins(registry P4PluginRegistry, dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		pluginDir := filepath.Join(dir, file.Name())
+		if _, err := os.Stat(filepath.Join(pluginDir, ManifestFile)); err != nil {
+			continue
+		}
+		if err := registry.RegisterFilePlugin(pluginDir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
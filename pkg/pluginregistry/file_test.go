@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+const testP4InfoText = `
+pkg_info {
+  name: "ignored"
+  doc {
+    brief: "test pipeline"
+  }
+}
+tables {
+  preamble {
+    id: 33554433
+    name: "ingress.wcmp_table"
+  }
+}
+`
+
+func writeTestFile(t *testing.T, dir string, name string, data []byte) {
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+}
+
+func TestLoadFilePlugin(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p4plugins")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Text P4Info in the default location without device config
+	textDir := filepath.Join(dir, "text")
+	assert.NoError(t, os.Mkdir(textDir, 0755))
+	writeTestFile(t, textDir, ManifestFile, []byte(`{"name": "test", "version": "1.0.0", "arch": "v1model"}`))
+	writeTestFile(t, textDir, "p4info.txt", []byte(testP4InfoText))
+
+	p4Plugin, err := LoadFilePlugin(textDir)
+	assert.NoError(t, err)
+	pkgInfo, err := p4Plugin.GetPkgInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, "test", pkgInfo.Name)
+	assert.Equal(t, "1.0.0", pkgInfo.Version)
+	assert.Equal(t, "v1model", pkgInfo.Arch)
+	assert.Equal(t, "test pipeline", pkgInfo.GetDoc().GetBrief())
+	p4Info, err := p4Plugin.GetP4Info()
+	assert.NoError(t, err)
+	assert.Equal(t, "ingress.wcmp_table", p4Info.Tables[0].Preamble.Name)
+	deviceConfig, err := p4Plugin.GetP4DeviceConfig()
+	assert.NoError(t, err)
+	assert.Empty(t, deviceConfig)
+
+	// Binary P4Info with device config
+	binaryDir := filepath.Join(dir, "binary")
+	assert.NoError(t, os.Mkdir(binaryDir, 0755))
+	p4InfoBytes, err := proto.Marshal(p4Info)
+	assert.NoError(t, err)
+	writeTestFile(t, binaryDir, ManifestFile, []byte(`{"name": "test", "version": "2.0.0", "arch": "v1model", "p4info": "p4info.bin", "deviceConfig": "bmv2.json"}`))
+	writeTestFile(t, binaryDir, "p4info.bin", p4InfoBytes)
+	writeTestFile(t, binaryDir, "bmv2.json", []byte("{}"))
+
+	p4Plugin, err = LoadFilePlugin(binaryDir)
+	assert.NoError(t, err)
+	binaryP4Info, err := p4Plugin.GetP4Info()
+	assert.NoError(t, err)
+	assert.True(t, proto.Equal(p4Info, binaryP4Info))
+	deviceConfig, err = p4Plugin.GetP4DeviceConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("{}"), deviceConfig)
+
+	// Invalid manifests
+	invalidDir := filepath.Join(dir, "invalid")
+	assert.NoError(t, os.Mkdir(invalidDir, 0755))
+	_, err = LoadFilePlugin(invalidDir)
+	assert.True(t, errors.IsNotFound(err))
+	writeTestFile(t, invalidDir, ManifestFile, []byte(`{"name": "test"}`))
+	_, err = LoadFilePlugin(invalidDir)
+	assert.True(t, errors.IsInvalid(err))
+
+	// Plugins with an invalid P4Info are rejected
+	writeTestFile(t, invalidDir, ManifestFile, []byte(`{"name": "test", "version": "3.0.0", "arch": "v1model"}`))
+	writeTestFile(t, invalidDir, "p4info.txt", []byte(`tables { preamble { id: 1 name: "ingress.wcmp_table" } }`))
+	err = NewP4PluginRegistry().RegisterFilePlugin(invalidDir)
+	assert.True(t, errors.IsInvalid(err))
+
+	// Only the directories with a manifest are registered
+	assert.NoError(t, os.Remove(filepath.Join(invalidDir, ManifestFile)))
+	registry := NewP4PluginRegistry()
+	assert.NoError(t, RegisterFilePlugins(registry, dir))
+	assert.Len(t, registry.GetPlugins(), 3)
+	_, err = registry.GetPlugin(p4rtapi.NewP4PluginID("test", "2.0.0", "v1model"))
+	assert.NoError(t, err)
+}
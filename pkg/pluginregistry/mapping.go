@@ -0,0 +1,277 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+)
+
+// WCMPMapping names the P4 entities implementing routing and weighted next hops in a P4 program
+type WCMPMapping struct {
+	// Routing lists the routing tables of the program, e.g. one for IPv4 and one for IPv6 prefixes
+	Routing []RoutingMapping `json:"routing"`
+	NextHop NextHopMapping   `json:"nextHop"`
+}
+
+// RoutingMapping names a table mapping destination prefixes of an address family to next hop IDs
+type RoutingMapping struct {
+	// Table is the routing table, e.g. "FabricIngress.forwarding.routing_v4"
+	Table string `json:"table"`
+	// DstAddrField is the LPM match field of the destination address
+	DstAddrField string `json:"dstAddrField"`
+	// Action is the action setting the next hop ID of a route
+	Action string `json:"action"`
+	// NextIDParam is the action param holding the next hop ID
+	NextIDParam string `json:"nextIdParam"`
+}
+
+// NextHopMapping names the table selecting a weighted next hop for a next hop ID
+type NextHopMapping struct {
+	// Table is the next hop table, e.g. "FabricIngress.next.hashed"
+	Table string `json:"table"`
+	// NextIDField is the exact match field of the next hop ID
+	NextIDField string `json:"nextIdField"`
+	// ActionProfile is the action selector implementing the next hop table
+	ActionProfile string `json:"actionProfile"`
+	// Action is the action forwarding packets to a next hop
+	Action string `json:"action"`
+	// PortParam is the action param holding the egress port
+	PortParam string `json:"portParam"`
+	// SrcMacParam is the optional action param holding the source MAC address
+	SrcMacParam string `json:"srcMacParam,omitempty"`
+	// DstMacParam is the optional action param holding the destination MAC address
+	DstMacParam string `json:"dstMacParam,omitempty"`
+}
+
+// WCMPMappingProvider is implemented by P4 plugins that can be programmed with WCMP routes
+type WCMPMappingProvider interface {
+	GetWCMPMapping() (*WCMPMapping, error)
+}
+
+// entityNames returns the names of the P4Info entities of the mapping, named as in P4Info diffs
+func (m *WCMPMapping) entityNames() []string {
+	var names []string
+	for _, routing := range m.Routing {
+		names = append(names,
+			routing.Table,
+			routing.Table+"/"+routing.DstAddrField,
+			routing.Action,
+			routing.Action+"/"+routing.NextIDParam)
+	}
+	names = append(names,
+		m.NextHop.Table,
+		m.NextHop.Table+"/"+m.NextHop.NextIDField,
+		m.NextHop.ActionProfile,
+		m.NextHop.Action,
+		m.NextHop.Action+"/"+m.NextHop.PortParam)
+	for _, param := range []string{m.NextHop.SrcMacParam, m.NextHop.DstMacParam} {
+		if param != "" {
+			names = append(names, m.NextHop.Action+"/"+param)
+		}
+	}
+	return names
+}
+
+// getWCMPMapping returns the WCMP mapping of a P4 plugin, or a NotSupported error if it provides none
+func getWCMPMapping(p4Plugin P4Plugin) (*WCMPMapping, error) {
+	provider, ok := p4Plugin.(WCMPMappingProvider)
+	if !ok {
+		return nil, errors.NewNotSupported("P4 plugin provides no WCMP mapping")
+	}
+	mapping, err := provider.GetWCMPMapping()
+	if err != nil {
+		return nil, err
+	}
+	if mapping == nil {
+		return nil, errors.NewNotSupported("P4 plugin provides no WCMP mapping")
+	}
+	return mapping, nil
+}
+
+// WCMPEntities are the P4Info IDs of the entities of a WCMP mapping
+type WCMPEntities struct {
+	Routing         []RoutingEntities
+	NextHopTableID  uint32
+	NextIDFieldID   uint32
+	ActionProfileID uint32
+	NextHopActionID uint32
+	PortParamID     uint32
+	// SrcMacParamID is zero if the mapping has no source MAC address param
+	SrcMacParamID uint32
+	// DstMacParamID is zero if the mapping has no destination MAC address param
+	DstMacParamID uint32
+}
+
+// RoutingEntities are the P4Info IDs of the entities of a routing table
+type RoutingEntities struct {
+	TableID        uint32
+	DstAddrFieldID uint32
+	// DstAddrBitwidth is the width of the destination address, e.g. 32 for IPv4 or 128 for IPv6
+	DstAddrBitwidth int32
+	ActionID        uint32
+	NextIDParamID   uint32
+}
+
+// GetRouting returns the routing table entities for destination addresses of the given width
+func (e *WCMPEntities) GetRouting(dstAddrBitwidth int32) (*RoutingEntities, error) {
+	for i := range e.Routing {
+		if e.Routing[i].DstAddrBitwidth == dstAddrBitwidth {
+			return &e.Routing[i], nil
+		}
+	}
+	return nil, errors.NewNotSupported("no routing table for %d bit destination addresses", dstAddrBitwidth)
+}
+
+// GetWCMPEntities resolves the WCMP mapping of a P4 plugin against its P4Info. It returns a NotSupported
+// error if the plugin provides no mapping and an Invalid error if the mapping does not match the P4Info.
+func GetWCMPEntities(p4Plugin P4Plugin) (*WCMPEntities, error) {
+	mapping, err := getWCMPMapping(p4Plugin)
+	if err != nil {
+		return nil, err
+	}
+	p4Info, err := p4Plugin.GetP4Info()
+	if err != nil {
+		return nil, err
+	}
+	return ResolveWCMPMapping(p4Info, mapping)
+}
+
+// ResolveWCMPMapping resolves the names of a WCMP mapping to the IDs of the P4Info entities
+func ResolveWCMPMapping(p4Info *p4configapi.P4Info, mapping *WCMPMapping) (*WCMPEntities, error) {
+	entities := &WCMPEntities{}
+
+	if len(mapping.Routing) == 0 {
+		return nil, errors.NewInvalid("no routing table in WCMP mapping")
+	}
+	for _, routingMapping := range mapping.Routing {
+		routing, err := resolveRoutingMapping(p4Info, routingMapping)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := entities.GetRouting(routing.DstAddrBitwidth); err == nil {
+			return nil, errors.NewInvalid("more than one routing table for %d bit destination addresses", routing.DstAddrBitwidth)
+		}
+		entities.Routing = append(entities.Routing, *routing)
+	}
+
+	nextHopTable, err := findTable(p4Info, mapping.NextHop.Table)
+	if err != nil {
+		return nil, err
+	}
+	entities.NextHopTableID = nextHopTable.Preamble.Id
+	nextIDField, err := findMatchField(nextHopTable, mapping.NextHop.NextIDField, p4configapi.MatchField_EXACT)
+	if err != nil {
+		return nil, err
+	}
+	entities.NextIDFieldID = nextIDField.Id
+	actionProfile, err := findActionProfile(p4Info, mapping.NextHop.ActionProfile)
+	if err != nil {
+		return nil, err
+	}
+	if !actionProfile.WithSelector {
+		return nil, errors.NewInvalid("action profile '%s' is not an action selector", mapping.NextHop.ActionProfile)
+	}
+	if nextHopTable.ImplementationId != actionProfile.Preamble.Id {
+		return nil, errors.NewInvalid("table '%s' is not implemented by action profile '%s'", mapping.NextHop.Table, mapping.NextHop.ActionProfile)
+	}
+	entities.ActionProfileID = actionProfile.Preamble.Id
+	nextHopAction, err := findTableAction(p4Info, nextHopTable, mapping.NextHop.Action)
+	if err != nil {
+		return nil, err
+	}
+	entities.NextHopActionID = nextHopAction.Preamble.Id
+	if entities.PortParamID, err = findParam(nextHopAction, mapping.NextHop.PortParam, true); err != nil {
+		return nil, err
+	}
+	if entities.SrcMacParamID, err = findParam(nextHopAction, mapping.NextHop.SrcMacParam, false); err != nil {
+		return nil, err
+	}
+	if entities.DstMacParamID, err = findParam(nextHopAction, mapping.NextHop.DstMacParam, false); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+func resolveRoutingMapping(p4Info *p4configapi.P4Info, mapping RoutingMapping) (*RoutingEntities, error) {
+	routing := &RoutingEntities{}
+	table, err := findTable(p4Info, mapping.Table)
+	if err != nil {
+		return nil, err
+	}
+	routing.TableID = table.Preamble.Id
+	dstAddrField, err := findMatchField(table, mapping.DstAddrField, p4configapi.MatchField_LPM)
+	if err != nil {
+		return nil, err
+	}
+	routing.DstAddrFieldID = dstAddrField.Id
+	routing.DstAddrBitwidth = dstAddrField.Bitwidth
+	action, err := findTableAction(p4Info, table, mapping.Action)
+	if err != nil {
+		return nil, err
+	}
+	routing.ActionID = action.Preamble.Id
+	if routing.NextIDParamID, err = findParam(action, mapping.NextIDParam, true); err != nil {
+		return nil, err
+	}
+	return routing, nil
+}
+
+func findTable(p4Info *p4configapi.P4Info, name string) (*p4configapi.Table, error) {
+	for _, table := range p4Info.Tables {
+		if table.Preamble.GetName() == name {
+			return table, nil
+		}
+	}
+	return nil, errors.NewInvalid("table '%s' not found in P4Info", name)
+}
+
+func findMatchField(table *p4configapi.Table, name string, matchType p4configapi.MatchField_MatchType) (*p4configapi.MatchField, error) {
+	for _, matchField := range table.MatchFields {
+		if matchField.Name == name {
+			if matchField.GetMatchType() != matchType {
+				return nil, errors.NewInvalid("match field '%s' of table '%s' is not a %s match", name, table.Preamble.GetName(), matchType)
+			}
+			return matchField, nil
+		}
+	}
+	return nil, errors.NewInvalid("match field '%s' not found in table '%s'", name, table.Preamble.GetName())
+}
+
+func findTableAction(p4Info *p4configapi.P4Info, table *p4configapi.Table, name string) (*p4configapi.Action, error) {
+	for _, action := range p4Info.Actions {
+		if action.Preamble.GetName() != name {
+			continue
+		}
+		for _, actionRef := range table.ActionRefs {
+			if actionRef.Id == action.Preamble.Id {
+				return action, nil
+			}
+		}
+		return nil, errors.NewInvalid("action '%s' is not an action of table '%s'", name, table.Preamble.GetName())
+	}
+	return nil, errors.NewInvalid("action '%s' not found in P4Info", name)
+}
+
+func findParam(action *p4configapi.Action, name string, required bool) (uint32, error) {
+	if name == "" && !required {
+		return 0, nil
+	}
+	for _, param := range action.Params {
+		if param.Name == name {
+			return param.Id, nil
+		}
+	}
+	return 0, errors.NewInvalid("param '%s' not found in action '%s'", name, action.Preamble.GetName())
+}
+
+func findActionProfile(p4Info *p4configapi.P4Info, name string) (*p4configapi.ActionProfile, error) {
+	for _, actionProfile := range p4Info.ActionProfiles {
+		if actionProfile.Preamble.GetName() == name {
+			return actionProfile, nil
+		}
+	}
+	return nil, errors.NewInvalid("action profile '%s' not found in P4Info", name)
+}
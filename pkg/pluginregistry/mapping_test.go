@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+const testWCMPP4InfoText = `
+tables {
+  preamble { id: 33554433 name: "ingress.routing_v4" }
+  match_fields { id: 1 name: "hdr.ipv4.dst_addr" bitwidth: 32 match_type: LPM }
+  action_refs { id: 16777217 }
+}
+tables {
+  preamble { id: 33554435 name: "ingress.routing_v6" }
+  match_fields { id: 1 name: "hdr.ipv6.dst_addr" bitwidth: 128 match_type: LPM }
+  action_refs { id: 16777217 }
+}
+tables {
+  preamble { id: 33554434 name: "ingress.next_hashed" }
+  match_fields { id: 1 name: "next_id" bitwidth: 32 match_type: EXACT }
+  action_refs { id: 16777218 }
+  implementation_id: 285212673
+}
+actions {
+  preamble { id: 16777217 name: "ingress.set_next_id" }
+  params { id: 1 name: "next_id" bitwidth: 32 }
+}
+actions {
+  preamble { id: 16777218 name: "ingress.route_hashed" }
+  params { id: 1 name: "port_num" bitwidth: 9 }
+  params { id: 2 name: "smac" bitwidth: 48 }
+  params { id: 3 name: "dmac" bitwidth: 48 }
+}
+action_profiles {
+  preamble { id: 285212673 name: "ingress.hashed_selector" }
+  table_ids: 33554434
+  with_selector: true
+  size: 1024
+}
+`
+
+const testWCMPMapping = `{
+  "routing": [
+    {
+      "table": "ingress.routing_v4",
+      "dstAddrField": "hdr.ipv4.dst_addr",
+      "action": "ingress.set_next_id",
+      "nextIdParam": "next_id"
+    },
+    {
+      "table": "ingress.routing_v6",
+      "dstAddrField": "hdr.ipv6.dst_addr",
+      "action": "ingress.set_next_id",
+      "nextIdParam": "next_id"
+    }
+  ],
+  "nextHop": {
+    "table": "ingress.next_hashed",
+    "nextIdField": "next_id",
+    "actionProfile": "ingress.hashed_selector",
+    "action": "ingress.route_hashed",
+    "portParam": "port_num",
+    "dstMacParam": "dmac"
+  }
+}`
+
+func TestWCMPMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p4plugins")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writeTestFile(t, dir, ManifestFile, []byte(`{"name": "wcmp", "version": "1.0.0", "arch": "v1model", "wcmpMapping": "wcmp.json"}`))
+	writeTestFile(t, dir, "p4info.txt", []byte(testWCMPP4InfoText))
+	writeTestFile(t, dir, "wcmp.json", []byte(testWCMPMapping))
+	p4Plugin, err := LoadFilePlugin(dir)
+	assert.NoError(t, err)
+	entities, err := GetWCMPEntities(p4Plugin)
+	assert.NoError(t, err)
+	assert.Equal(t, &WCMPEntities{
+		Routing: []RoutingEntities{
+			{TableID: 33554433, DstAddrFieldID: 1, DstAddrBitwidth: 32, ActionID: 16777217, NextIDParamID: 1},
+			{TableID: 33554435, DstAddrFieldID: 1, DstAddrBitwidth: 128, ActionID: 16777217, NextIDParamID: 1},
+		},
+		NextHopTableID:  33554434,
+		NextIDFieldID:   1,
+		ActionProfileID: 285212673,
+		NextHopActionID: 16777218,
+		PortParamID:     1,
+		DstMacParamID:   3,
+	}, entities)
+	assert.NoError(t, NewP4PluginRegistry().RegisterFilePlugin(dir))
+	routing, err := entities.GetRouting(128)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(33554435), routing.TableID)
+	_, err = entities.GetRouting(48)
+	assert.True(t, errors.IsNotSupported(err))
+
+	// Plugins without a mapping cannot be programmed with WCMP routes but are registered
+	p4Info, err := p4Plugin.GetP4Info()
+	assert.NoError(t, err)
+	_, err = GetWCMPEntities(&filePlugin{p4Info: p4Info})
+	assert.True(t, errors.IsNotSupported(err))
+
+	// Plugins with a mapping that does not match their P4Info are rejected
+	writeTestFile(t, dir, "wcmp.json", []byte(`{"routing": [{"table": "ingress.routing_v6"}]}`))
+	p4Plugin, err = LoadFilePlugin(dir)
+	assert.NoError(t, err)
+	_, err = GetWCMPEntities(p4Plugin)
+	assert.True(t, errors.IsInvalid(err))
+	assert.True(t, errors.IsInvalid(NewP4PluginRegistry().RegisterFilePlugin(dir)))
+
+	mapping := &WCMPMapping{}
+	assert.NoError(t, json.Unmarshal([]byte(testWCMPMapping), mapping))
+	mapping.Routing[1] = mapping.Routing[0]
+	_, err = ResolveWCMPMapping(p4Info, mapping)
+	assert.Contains(t, err.Error(), "more than one routing table for 32 bit destination addresses")
+	mapping.Routing = nil
+	_, err = ResolveWCMPMapping(p4Info, mapping)
+	assert.True(t, errors.IsInvalid(err))
+	assert.NoError(t, json.Unmarshal([]byte(testWCMPMapping), mapping))
+	mapping.NextHop.NextIDField = "hdr.ipv4.dst_addr"
+	_, err = ResolveWCMPMapping(p4Info, mapping)
+	assert.Contains(t, err.Error(), "match field 'hdr.ipv4.dst_addr' not found in table 'ingress.next_hashed'")
+	mapping.NextHop.NextIDField = "next_id"
+	mapping.NextHop.Action = "ingress.set_next_id"
+	_, err = ResolveWCMPMapping(p4Info, mapping)
+	assert.Contains(t, err.Error(), "is not an action of table 'ingress.next_hashed'")
+}
@@ -5,9 +5,12 @@
 package pluginregistry
 
 import (
+	"context"
+	"github.com/google/uuid"
 	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
 	"github.com/onosproject/onos-lib-go/pkg/errors"
 	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"github.com/onosproject/wcmp-app/pkg/p4info"
 	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
 	"plugin"
 	"sync"
@@ -20,11 +23,100 @@ type P4PluginRegistry interface {
 	GetPlugins() map[p4rtapi.P4PluginID]P4Plugin
 	GetPlugin(id p4rtapi.P4PluginID) (P4Plugin, error)
 	RegisterPlugin(pluginName string) error
+	RegisterFilePlugin(dir string) error
+	UnregisterPlugin(id p4rtapi.P4PluginID) error
+	// WatchPluginDir registers the P4 plugins of the subdirectories of a given directory and keeps them in sync
+	// with the directory content until the context is done
+	WatchPluginDir(ctx context.Context, dir string) error
+	// Watch watches P4 plugin registrations
+	Watch(ctx context.Context, ch chan<- PluginEvent) error
+}
+
+// PluginEventType is the type of a P4 plugin registry event
+type PluginEventType int
+
+const (
+	// PluginRegistered indicates a new P4 plugin is registered
+	PluginRegistered PluginEventType = iota
+	// PluginUpdated indicates a registered P4 plugin is replaced
+	PluginUpdated
+	// PluginUnregistered indicates a P4 plugin is unregistered
+	PluginUnregistered
+)
+
+func (t PluginEventType) String() string {
+	switch t {
+	case PluginRegistered:
+		return "REGISTERED"
+	case PluginUpdated:
+		return "UPDATED"
+	case PluginUnregistered:
+		return "UNREGISTERED"
+	}
+	return "UNKNOWN"
+}
+
+// PluginEvent is a P4 plugin registry event
+type PluginEvent struct {
+	Type     PluginEventType
+	PluginID p4rtapi.P4PluginID
+}
+
+// pluginWatcher queues the events of a watcher, so a slow watcher neither blocks the registry nor misses events
+type pluginWatcher struct {
+	events  []PluginEvent
+	mu      sync.Mutex
+	eventCh chan struct{}
+}
+
+func newPluginWatcher() *pluginWatcher {
+	return &pluginWatcher{
+		eventCh: make(chan struct{}, 1),
+	}
+}
+
+func (w *pluginWatcher) push(event PluginEvent) {
+	w.mu.Lock()
+	w.events = append(w.events, event)
+	w.mu.Unlock()
+	select {
+	case w.eventCh <- struct{}{}:
+	default:
+	}
+}
+
+func (w *pluginWatcher) pop() []PluginEvent {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	events := w.events
+	w.events = nil
+	return events
+}
+
+// run forwards the queued events to a channel until the context is done
+func (w *pluginWatcher) run(ctx context.Context, ch chan<- PluginEvent) {
+	defer close(ch)
+	for {
+		select {
+		case <-w.eventCh:
+			for _, event := range w.pop() {
+				select {
+				case ch <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
 }
 
 type pluginRegistry struct {
-	plugins map[p4rtapi.P4PluginID]P4Plugin
-	mu      sync.RWMutex
+	plugins    map[p4rtapi.P4PluginID]P4Plugin
+	mu         sync.RWMutex
+	watchers   map[uuid.UUID]*pluginWatcher
+	watchersMu sync.RWMutex
 }
 
 // GetPlugins get list of p4 plugins
@@ -69,24 +161,115 @@ func (p *pluginRegistry) RegisterPlugin(pluginName string) error {
 		return errors.NewInvalid("symbol loaded from module %s is not a P4 plugin",
 			pluginName)
 	}
+	return p.register(pluginName, p4Plugin)
+}
+
+// RegisterFilePlugin registers a plugin loaded from the P4 artifacts of a given directory
+func (p *pluginRegistry) RegisterFilePlugin(dir string) error {
+	log.Infow("Loading plugin from directory", "plugin dir", dir)
+	p4Plugin, err := LoadFilePlugin(dir)
+	if err != nil {
+		log.Warnw("Unable to load P4 plugin from directory", "plugin dir", dir, "error", err)
+		return err
+	}
+	return p.register(dir, p4Plugin)
+}
+
+func (p *pluginRegistry) register(pluginName string, p4Plugin P4Plugin) error {
 	pkgInfo, err := p4Plugin.GetPkgInfo()
 	if err != nil {
 		log.Warnw("Cannot retrieve P4 Program PkgInfo", "plugin name", pluginName)
 		return err
 	}
+	if err := p4info.ValidatePkgInfo(pkgInfo); err != nil {
+		log.Warnw("Rejecting P4 plugin with invalid PkgInfo", "plugin name", pluginName, "error", err)
+		return err
+	}
+	p4Info, err := p4Plugin.GetP4Info()
+	if err != nil {
+		log.Warnw("Cannot retrieve P4Info", "plugin name", pluginName)
+		return err
+	}
+	if err := p4info.Validate(p4Info); err != nil {
+		log.Warnw("Rejecting P4 plugin with invalid P4Info", "plugin name", pluginName, "error", err)
+		return err
+	}
+	if _, err := GetWCMPEntities(p4Plugin); err != nil && !errors.IsNotSupported(err) {
+		log.Warnw("Rejecting P4 plugin with invalid WCMP mapping", "plugin name", pluginName, "error", err)
+		return err
+	}
 	pluginID := p4rtapi.NewP4PluginID(pkgInfo.Name, pkgInfo.Version, pkgInfo.Arch)
 	log.Infow("Registering a P4 plugin", "plugin ID", pluginID)
 	p.mu.Lock()
-	defer p.mu.Unlock()
+	_, ok := p.plugins[pluginID]
 	p.plugins[pluginID] = p4Plugin
+	p.mu.Unlock()
+	if ok {
+		p.notify(PluginEvent{Type: PluginUpdated, PluginID: pluginID})
+	} else {
+		p.notify(PluginEvent{Type: PluginRegistered, PluginID: pluginID})
+	}
 	return nil
 }
 
-// NewP4PluginRegistry create an instance of p4 plugin registry
+// UnregisterPlugin unregisters the plugin with a given ID
+func (p *pluginRegistry) UnregisterPlugin(id p4rtapi.P4PluginID) error {
+	p.mu.Lock()
+	_, ok := p.plugins[id]
+	delete(p.plugins, id)
+	p.mu.Unlock()
+	if !ok {
+		return errors.NewNotFound("P4 plugin with ID '%s' not found", id)
+	}
+	log.Infow("Unregistered a P4 plugin", "plugin ID", id)
+	p.notify(PluginEvent{Type: PluginUnregistered, PluginID: id})
+	return nil
+}
+
+// Watch watches P4 plugin registrations
+func (p *pluginRegistry) Watch(ctx context.Context, ch chan<- PluginEvent) error {
+	watcher := newPluginWatcher()
+	id := uuid.New()
+	p.watchersMu.Lock()
+	p.watchers[id] = watcher
+	p.watchersMu.Unlock()
+
+	go watcher.run(ctx, ch)
+
+	go func() {
+		<-ctx.Done()
+		p.watchersMu.Lock()
+		delete(p.watchers, id)
+		p.watchersMu.Unlock()
+	}()
+	return nil
+}
+
+func (p *pluginRegistry) notify(event PluginEvent) {
+	p.watchersMu.RLock()
+	defer p.watchersMu.RUnlock()
+	for _, watcher := range p.watchers {
+		watcher.push(event)
+	}
+}
+
+// NewP4PluginRegistry create an instance of p4 plugin registry with the built-in P4 plugins registered
 func NewP4PluginRegistry() P4PluginRegistry {
-	return &pluginRegistry{
-		plugins: make(map[p4rtapi.P4PluginID]P4Plugin),
+	registry := &pluginRegistry{
+		plugins:  make(map[p4rtapi.P4PluginID]P4Plugin),
+		watchers: make(map[uuid.UUID]*pluginWatcher),
+	}
+	p4Plugins, err := loadBuiltinPlugins()
+	if err != nil {
+		log.Errorw("Unable to load built-in P4 plugins", "error", err)
+		return registry
+	}
+	for pluginName, p4Plugin := range p4Plugins {
+		if err := registry.register(pluginName, p4Plugin); err != nil {
+			log.Errorw("Unable to register built-in P4 plugin", "plugin name", pluginName, "error", err)
+		}
 	}
+	return registry
 }
 
 // P4Plugin p4 plugin interface
@@ -0,0 +1,69 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"context"
+	"testing"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+func TestPluginRegistryEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	registry := NewP4PluginRegistry().(*pluginRegistry)
+	ch := make(chan PluginEvent, 10)
+	assert.NoError(t, registry.Watch(ctx, ch))
+
+	p4Info := &p4configapi.P4Info{}
+	assert.NoError(t, prototext.Unmarshal([]byte(testP4InfoText), p4Info))
+	p4Plugin := &filePlugin{
+		pkgInfo: &p4configapi.PkgInfo{Name: "test", Version: "1.0.0", Arch: "v1model"},
+		p4Info:  p4Info,
+	}
+	pluginID := p4rtapi.NewP4PluginID("test", "1.0.0", "v1model")
+
+	assert.NoError(t, registry.register("test", p4Plugin))
+	assert.Equal(t, PluginEvent{Type: PluginRegistered, PluginID: pluginID}, <-ch)
+	assert.NoError(t, registry.register("test", p4Plugin))
+	assert.Equal(t, PluginEvent{Type: PluginUpdated, PluginID: pluginID}, <-ch)
+	assert.NoError(t, registry.UnregisterPlugin(pluginID))
+	assert.Equal(t, PluginEvent{Type: PluginUnregistered, PluginID: pluginID}, <-ch)
+	_, err := registry.GetPlugin(pluginID)
+	assert.True(t, errors.IsNotFound(err))
+	assert.True(t, errors.IsNotFound(registry.UnregisterPlugin(pluginID)))
+
+	// The watch channel is closed once the context is done
+	cancel()
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestPluginRegistrySlowWatcher(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	registry := NewP4PluginRegistry().(*pluginRegistry)
+	slowCh := make(chan PluginEvent)
+	assert.NoError(t, registry.Watch(ctx, slowCh))
+
+	p4Plugin := &filePlugin{
+		pkgInfo: &p4configapi.PkgInfo{Name: "test", Version: "1.0.0", Arch: "v1model"},
+		p4Info:  &p4configapi.P4Info{},
+	}
+	pluginID := p4rtapi.NewP4PluginID("test", "1.0.0", "v1model")
+
+	// Registrations do not wait for a watcher not receiving its events, which are all delivered once it does
+	for i := 0; i < 100; i++ {
+		assert.NoError(t, registry.register("test", p4Plugin))
+	}
+	assert.Equal(t, PluginEvent{Type: PluginRegistered, PluginID: pluginID}, <-slowCh)
+	for i := 1; i < 100; i++ {
+		assert.Equal(t, PluginEvent{Type: PluginUpdated, PluginID: pluginID}, <-slowCh)
+	}
+}
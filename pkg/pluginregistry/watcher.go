@@ -0,0 +1,163 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"context"
+	"github.com/fsnotify/fsnotify"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// reloadDelay is the time without changes to a plugin directory before it is reloaded, so that
+// the plugin is not loaded while its files are still being written
+const reloadDelay = 500 * time.Millisecond
+
+// WatchPluginDir registers the P4 plugins of the subdirectories of a given directory and keeps them in sync
+// with the directory content until the context is done
+func (p *pluginRegistry) WatchPluginDir(ctx context.Context, dir string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := watcher.Add(dir); err != nil {
+		_ = watcher.Close()
+		return err
+	}
+
+	w := &dirWatcher{
+		registry: p,
+		dir:      dir,
+		watcher:  watcher,
+		plugins:  make(map[string]p4rtapi.P4PluginID),
+	}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		_ = watcher.Close()
+		return err
+	}
+	for _, file := range files {
+		if !file.IsDir() {
+			continue
+		}
+		pluginDir := filepath.Join(dir, file.Name())
+		if err := watcher.Add(pluginDir); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+		if _, err := os.Stat(filepath.Join(pluginDir, ManifestFile)); err != nil {
+			continue
+		}
+		if err := w.load(pluginDir); err != nil {
+			_ = watcher.Close()
+			return err
+		}
+	}
+	go w.run(ctx)
+	return nil
+}
+
+// dirWatcher reloads the P4 plugins of the subdirectories of a directory when their files change
+type dirWatcher struct {
+	registry *pluginRegistry
+	dir      string
+	watcher  *fsnotify.Watcher
+	// plugins are the IDs of the P4 plugins registered from each plugin directory
+	plugins map[string]p4rtapi.P4PluginID
+}
+
+func (w *dirWatcher) run(ctx context.Context) {
+	defer w.watcher.Close()
+	pending := make(map[string]bool)
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			pluginDir := event.Name
+			if filepath.Dir(event.Name) != w.dir {
+				pluginDir = filepath.Dir(event.Name)
+			} else if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					if err := w.watcher.Add(event.Name); err != nil {
+						log.Warnw("Unable to watch P4 plugin directory", "plugin dir", event.Name, "error", err)
+					}
+				}
+			}
+			if filepath.Dir(pluginDir) != w.dir {
+				continue
+			}
+			log.Debugw("P4 plugin directory changed", "plugin dir", pluginDir, "event", event.Op)
+			pending[pluginDir] = true
+			timer.Reset(reloadDelay)
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnw("Error watching P4 plugin directory", "plugin dir", w.dir, "error", err)
+		case <-timer.C:
+			for pluginDir := range pending {
+				w.reload(pluginDir)
+			}
+			pending = make(map[string]bool)
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+// load registers the P4 plugin of a plugin directory
+func (w *dirWatcher) load(pluginDir string) error {
+	p4Plugin, err := LoadFilePlugin(pluginDir)
+	if err != nil {
+		return err
+	}
+	pkgInfo, err := p4Plugin.GetPkgInfo()
+	if err != nil {
+		return err
+	}
+	pluginID := p4rtapi.NewP4PluginID(pkgInfo.Name, pkgInfo.Version, pkgInfo.Arch)
+	if previousID, ok := w.plugins[pluginDir]; ok && previousID != pluginID {
+		w.unregister(pluginDir)
+	}
+	if err := w.registry.register(pluginDir, p4Plugin); err != nil {
+		return err
+	}
+	w.plugins[pluginDir] = pluginID
+	return nil
+}
+
+// reload registers, updates or unregisters the P4 plugin of a plugin directory depending on its content
+func (w *dirWatcher) reload(pluginDir string) {
+	err := w.load(pluginDir)
+	if err == nil {
+		return
+	}
+	if errors.IsNotFound(err) {
+		w.unregister(pluginDir)
+		return
+	}
+	// Keep the previous version of the plugin until the files are fixed
+	log.Warnw("Unable to reload P4 plugin from directory", "plugin dir", pluginDir, "error", err)
+}
+
+func (w *dirWatcher) unregister(pluginDir string) {
+	pluginID, ok := w.plugins[pluginDir]
+	if !ok {
+		return
+	}
+	delete(w.plugins, pluginDir)
+	if err := w.registry.UnregisterPlugin(pluginID); err != nil {
+		log.Warnw("Unable to unregister P4 plugin", "plugin ID", pluginID, "error", err)
+	}
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pluginregistry
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func nextPluginEvent(t *testing.T, ch <-chan PluginEvent) PluginEvent {
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(10 * reloadDelay):
+		t.Fatal("timed out waiting for P4 plugin event")
+	}
+	return PluginEvent{}
+}
+
+func TestWatchPluginDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "p4plugins")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Existing plugin directories are loaded when the watch starts
+	v1Dir := filepath.Join(dir, "v1")
+	assert.NoError(t, os.Mkdir(v1Dir, 0755))
+	writeTestFile(t, v1Dir, ManifestFile, []byte(`{"name": "test", "version": "1.0.0", "arch": "v1model"}`))
+	writeTestFile(t, v1Dir, "p4info.txt", []byte(testP4InfoText))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	registry := NewP4PluginRegistry()
+	ch := make(chan PluginEvent)
+	assert.NoError(t, registry.Watch(ctx, ch))
+	assert.NoError(t, registry.WatchPluginDir(ctx, dir))
+	v1ID := p4rtapi.NewP4PluginID("test", "1.0.0", "v1model")
+	assert.Equal(t, PluginEvent{Type: PluginRegistered, PluginID: v1ID}, nextPluginEvent(t, ch))
+
+	// New plugin directories are registered
+	v2Dir := filepath.Join(dir, "v2")
+	assert.NoError(t, os.Mkdir(v2Dir, 0755))
+	time.Sleep(reloadDelay / 5)
+	writeTestFile(t, v2Dir, "p4info.txt", []byte(testP4InfoText))
+	writeTestFile(t, v2Dir, ManifestFile, []byte(`{"name": "test", "version": "2.0.0", "arch": "v1model"}`))
+	v2ID := p4rtapi.NewP4PluginID("test", "2.0.0", "v1model")
+	assert.Equal(t, PluginEvent{Type: PluginRegistered, PluginID: v2ID}, nextPluginEvent(t, ch))
+	assert.Len(t, registry.GetPlugins(), 3)
+
+	// Changed plugin files update the registered plugin
+	writeTestFile(t, v2Dir, "bmv2.json", []byte("{}"))
+	writeTestFile(t, v2Dir, ManifestFile, []byte(`{"name": "test", "version": "2.0.0", "arch": "v1model", "deviceConfig": "bmv2.json"}`))
+	assert.Equal(t, PluginEvent{Type: PluginUpdated, PluginID: v2ID}, nextPluginEvent(t, ch))
+	p4Plugin, err := registry.GetPlugin(v2ID)
+	assert.NoError(t, err)
+	deviceConfig, err := p4Plugin.GetP4DeviceConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("{}"), deviceConfig)
+
+	// Invalid plugin files keep the previous version of the plugin
+	writeTestFile(t, v2Dir, ManifestFile, []byte(`{"name": "test"}`))
+	time.Sleep(2 * reloadDelay)
+	_, err = registry.GetPlugin(v2ID)
+	assert.NoError(t, err)
+
+	// Removed plugin directories are unregistered
+	assert.NoError(t, os.RemoveAll(v1Dir))
+	assert.Equal(t, PluginEvent{Type: PluginUnregistered, PluginID: v1ID}, nextPluginEvent(t, ch))
+	_, err = registry.GetPlugin(v1ID)
+	assert.Error(t, err)
+	assert.Len(t, registry.GetPlugins(), 2)
+}
@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package builder
+
+import (
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+)
+
+// Param is the value of an action parameter
+type Param struct {
+	Name  string
+	Value []byte
+}
+
+// NewParam returns the value of an action parameter
+func NewParam(name string, value []byte) Param {
+	return Param{Name: name, Value: value}
+}
+
+// GroupMember is a member of an action profile group
+type GroupMember struct {
+	MemberID uint32
+	// Weight is the weight of the member in the group; zero means a weight of one
+	Weight int32
+}
+
+// Action builds an action with the given parameter values; all the parameters of the action must be given
+func (b *Builder) Action(name string, params ...Param) (*p4api.Action, error) {
+	action, err := b.getAction(name)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string][]byte)
+	for _, param := range params {
+		if _, ok := values[param.Name]; ok {
+			return nil, errors.NewInvalid("duplicate parameter '%s' of action '%s'", param.Name, name)
+		}
+		values[param.Name] = param.Value
+	}
+	p4Action := &p4api.Action{
+		ActionId: action.Preamble.GetId(),
+	}
+	for _, actionParam := range action.Params {
+		value, ok := values[actionParam.Name]
+		if !ok {
+			return nil, errors.NewInvalid("missing parameter '%s' of action '%s'", actionParam.Name, name)
+		}
+		delete(values, actionParam.Name)
+		value, err := encode(value, actionParam.Bitwidth, "action parameter", actionParam.Name)
+		if err != nil {
+			return nil, err
+		}
+		p4Action.Params = append(p4Action.Params, &p4api.Action_Param{
+			ParamId: actionParam.Id,
+			Value:   value,
+		})
+	}
+	for _, param := range params {
+		if _, ok := values[param.Name]; ok {
+			return nil, errors.NewInvalid("unknown parameter '%s' of action '%s'", param.Name, name)
+		}
+	}
+	return p4Action, nil
+}
+
+// ActionProfileMember builds an action profile member with the given action; the action must be an action
+// of the tables implemented by the action profile
+func (b *Builder) ActionProfileMember(profileName string, memberID uint32, actionName string, params ...Param) (*p4api.ActionProfileMember, error) {
+	actionProfile, err := b.getActionProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	action, err := b.Action(actionName, params...)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range b.p4Info.Tables {
+		if table.ImplementationId != actionProfile.Preamble.GetId() {
+			continue
+		}
+		if err := b.checkActionRef(table, action.ActionId, false); err != nil {
+			return nil, err
+		}
+	}
+	return &p4api.ActionProfileMember{
+		ActionProfileId: actionProfile.Preamble.GetId(),
+		MemberId:        memberID,
+		Action:          action,
+	}, nil
+}
+
+// ActionProfileGroup builds a group of members of an action profile with a selector
+func (b *Builder) ActionProfileGroup(profileName string, groupID uint32, maxSize int32, members ...GroupMember) (*p4api.ActionProfileGroup, error) {
+	actionProfile, err := b.getActionProfile(profileName)
+	if err != nil {
+		return nil, err
+	}
+	if !actionProfile.WithSelector {
+		return nil, errors.NewInvalid("action profile '%s' has no selector", profileName)
+	}
+	if maxSize < 0 || actionProfile.MaxGroupSize > 0 && maxSize > actionProfile.MaxGroupSize {
+		return nil, errors.NewInvalid("max size %d of group %d exceeds the max group size %d of action profile '%s'", maxSize, groupID, actionProfile.MaxGroupSize, profileName)
+	}
+	group := &p4api.ActionProfileGroup{
+		ActionProfileId: actionProfile.Preamble.GetId(),
+		GroupId:         groupID,
+		MaxSize:         maxSize,
+	}
+	var totalWeight int32
+	memberIDs := make(map[uint32]bool)
+	for _, member := range members {
+		if memberIDs[member.MemberID] {
+			return nil, errors.NewInvalid("duplicate member %d of group %d", member.MemberID, groupID)
+		}
+		memberIDs[member.MemberID] = true
+		weight := member.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		if weight < 0 {
+			return nil, errors.NewInvalid("negative weight of member %d of group %d", member.MemberID, groupID)
+		}
+		totalWeight += weight
+		group.Members = append(group.Members, &p4api.ActionProfileGroup_Member{
+			MemberId: member.MemberID,
+			Weight:   weight,
+		})
+	}
+	if maxSize > 0 && totalWeight > maxSize {
+		return nil, errors.NewInvalid("total weight %d of group %d exceeds its max size %d", totalWeight, groupID, maxSize)
+	}
+	return group, nil
+}
+
+// checkActionRef checks that a table refers to an action; default actions cannot use TABLE_ONLY actions
+// and table entries cannot use DEFAULT_ONLY actions
+func (b *Builder) checkActionRef(table *p4configapi.Table, actionID uint32, isDefault bool) error {
+	actionName := b.actionIDs[actionID].GetPreamble().GetName()
+	for _, actionRef := range table.ActionRefs {
+		if actionRef.Id != actionID {
+			continue
+		}
+		if isDefault && actionRef.Scope == p4configapi.ActionRef_TABLE_ONLY {
+			return errors.NewInvalid("action '%s' cannot be the default action of table '%s'", actionName, table.Preamble.GetName())
+		}
+		if !isDefault && actionRef.Scope == p4configapi.ActionRef_DEFAULT_ONLY {
+			return errors.NewInvalid("action '%s' can only be the default action of table '%s'", actionName, table.Preamble.GetName())
+		}
+		return nil
+	}
+	return errors.NewInvalid("action '%s' is not an action of table '%s'", actionName, table.Preamble.GetName())
+}
@@ -0,0 +1,118 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package builder
+
+import (
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/southbound/p4rt"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// Builder builds the P4Runtime entities of a pipeline from the names of its P4Info entities, validating
+// the values against the P4Info and encoding them in the canonical binary string format
+type Builder struct {
+	p4Info         *p4configapi.P4Info
+	tables         map[string]*p4configapi.Table
+	actions        map[string]*p4configapi.Action
+	actionIDs      map[uint32]*p4configapi.Action
+	actionProfiles map[string]*p4configapi.ActionProfile
+	counters       map[string]*p4configapi.Counter
+	directCounters map[string]*p4configapi.DirectCounter
+	meters         map[string]*p4configapi.Meter
+	directMeters   map[string]*p4configapi.DirectMeter
+}
+
+// New returns a builder for the entities of the pipeline described by the given P4Info
+func New(p4Info *p4configapi.P4Info) *Builder {
+	b := &Builder{
+		p4Info:         p4Info,
+		tables:         make(map[string]*p4configapi.Table),
+		actions:        make(map[string]*p4configapi.Action),
+		actionIDs:      make(map[uint32]*p4configapi.Action),
+		actionProfiles: make(map[string]*p4configapi.ActionProfile),
+		counters:       make(map[string]*p4configapi.Counter),
+		directCounters: make(map[string]*p4configapi.DirectCounter),
+		meters:         make(map[string]*p4configapi.Meter),
+		directMeters:   make(map[string]*p4configapi.DirectMeter),
+	}
+	for _, table := range p4Info.Tables {
+		b.tables[table.Preamble.GetName()] = table
+	}
+	for _, action := range p4Info.Actions {
+		b.actions[action.Preamble.GetName()] = action
+		b.actionIDs[action.Preamble.GetId()] = action
+	}
+	for _, actionProfile := range p4Info.ActionProfiles {
+		b.actionProfiles[actionProfile.Preamble.GetName()] = actionProfile
+	}
+	for _, counter := range p4Info.Counters {
+		b.counters[counter.Preamble.GetName()] = counter
+	}
+	for _, directCounter := range p4Info.DirectCounters {
+		b.directCounters[directCounter.Preamble.GetName()] = directCounter
+	}
+	for _, meter := range p4Info.Meters {
+		b.meters[meter.Preamble.GetName()] = meter
+	}
+	for _, directMeter := range p4Info.DirectMeters {
+		b.directMeters[directMeter.Preamble.GetName()] = directMeter
+	}
+	return b
+}
+
+// NewFromPipelineConfig returns a builder for the entities of the pipeline of a pipeline config
+func NewFromPipelineConfig(pipelineConfig *p4rtapi.PipelineConfig) (*Builder, error) {
+	if pipelineConfig.Spec == nil || len(pipelineConfig.Spec.P4Info) == 0 {
+		return nil, errors.NewInvalid("pipeline config %s has no P4Info", pipelineConfig.ID)
+	}
+	p4Info := &p4configapi.P4Info{}
+	if err := proto.Unmarshal(pipelineConfig.Spec.P4Info, p4Info); err != nil {
+		return nil, errors.NewInvalid("invalid P4Info in pipeline config %s: %v", pipelineConfig.ID, err)
+	}
+	return New(p4Info), nil
+}
+
+// P4Info returns the P4Info of the pipeline
+func (b *Builder) P4Info() *p4configapi.P4Info {
+	return b.p4Info
+}
+
+func (b *Builder) getTable(name string) (*p4configapi.Table, error) {
+	table, ok := b.tables[name]
+	if !ok {
+		return nil, errors.NewNotFound("unknown table '%s'", name)
+	}
+	return table, nil
+}
+
+func (b *Builder) getAction(name string) (*p4configapi.Action, error) {
+	action, ok := b.actions[name]
+	if !ok {
+		return nil, errors.NewNotFound("unknown action '%s'", name)
+	}
+	return action, nil
+}
+
+func (b *Builder) getActionProfile(name string) (*p4configapi.ActionProfile, error) {
+	actionProfile, ok := b.actionProfiles[name]
+	if !ok {
+		return nil, errors.NewNotFound("unknown action profile '%s'", name)
+	}
+	return actionProfile, nil
+}
+
+// encode returns the canonical binary string of a value of the given bit width
+func encode(value []byte, bitwidth int32, kind string, name string) ([]byte, error) {
+	if len(value) == 0 {
+		return nil, errors.NewInvalid("%s '%s' has no value", kind, name)
+	}
+	value = p4rt.CanonicalBytes(value)
+	if bitwidth > 0 && p4rt.BitLen(value) > int(bitwidth) {
+		return nil, errors.NewInvalid("value of %s '%s' exceeds %d bits", kind, name, bitwidth)
+	}
+	return value, nil
+}
@@ -0,0 +1,413 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package builder
+
+import (
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	routingTableID = 0x02000001
+	aclTableID     = 0x02000002
+	nextTableID    = 0x02000003
+	setNextID      = 0x01000001
+	dropID         = 0x01000002
+	forwardID      = 0x01000003
+	noActionID     = 0x01000004
+	selectorID     = 0x11000001
+)
+
+func newTestP4Info() *p4configapi.P4Info {
+	return &p4configapi.P4Info{
+		Tables: []*p4configapi.Table{
+			{
+				Preamble: &p4configapi.Preamble{Id: routingTableID, Name: "ingress.routing"},
+				MatchFields: []*p4configapi.MatchField{
+					{Id: 1, Name: "vrf", Bitwidth: 12, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_EXACT}},
+					{Id: 2, Name: "ipv4_dst", Bitwidth: 32, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_LPM}},
+				},
+				ActionRefs: []*p4configapi.ActionRef{
+					{Id: setNextID},
+					{Id: noActionID, Scope: p4configapi.ActionRef_DEFAULT_ONLY},
+				},
+				IdleTimeoutBehavior: p4configapi.Table_NOTIFY_CONTROL,
+				Size:                1024,
+			},
+			{
+				Preamble: &p4configapi.Preamble{Id: aclTableID, Name: "ingress.acl"},
+				MatchFields: []*p4configapi.MatchField{
+					{Id: 1, Name: "eth_type", Bitwidth: 16, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_TERNARY}},
+					{Id: 2, Name: "l4_dport", Bitwidth: 16, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_RANGE}},
+					{Id: 3, Name: "ingress_port", Bitwidth: 9, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_OPTIONAL}},
+				},
+				ActionRefs: []*p4configapi.ActionRef{
+					{Id: dropID, Scope: p4configapi.ActionRef_TABLE_ONLY},
+					{Id: noActionID},
+				},
+				Size: 128,
+			},
+			{
+				Preamble: &p4configapi.Preamble{Id: nextTableID, Name: "ingress.next"},
+				MatchFields: []*p4configapi.MatchField{
+					{Id: 1, Name: "next_id", Bitwidth: 32, Match: &p4configapi.MatchField_MatchType_{MatchType: p4configapi.MatchField_EXACT}},
+				},
+				ActionRefs: []*p4configapi.ActionRef{
+					{Id: forwardID},
+				},
+				ImplementationId: selectorID,
+				Size:             1024,
+			},
+		},
+		Actions: []*p4configapi.Action{
+			{
+				Preamble: &p4configapi.Preamble{Id: setNextID, Name: "ingress.set_next"},
+				Params:   []*p4configapi.Action_Param{{Id: 1, Name: "next_id", Bitwidth: 32}},
+			},
+			{Preamble: &p4configapi.Preamble{Id: dropID, Name: "ingress.drop"}},
+			{
+				Preamble: &p4configapi.Preamble{Id: forwardID, Name: "ingress.forward"},
+				Params: []*p4configapi.Action_Param{
+					{Id: 1, Name: "port", Bitwidth: 9},
+					{Id: 2, Name: "dmac", Bitwidth: 48},
+				},
+			},
+			{Preamble: &p4configapi.Preamble{Id: noActionID, Name: "NoAction"}},
+		},
+		ActionProfiles: []*p4configapi.ActionProfile{
+			{
+				Preamble:     &p4configapi.Preamble{Id: selectorID, Name: "ingress.next_selector"},
+				TableIds:     []uint32{nextTableID},
+				WithSelector: true,
+				Size:         1024,
+				MaxGroupSize: 16,
+			},
+		},
+		Counters: []*p4configapi.Counter{
+			{
+				Preamble: &p4configapi.Preamble{Id: 0x12000001, Name: "ingress.port_packets"},
+				Spec:     &p4configapi.CounterSpec{Unit: p4configapi.CounterSpec_PACKETS},
+				Size:     512,
+			},
+		},
+		DirectCounters: []*p4configapi.DirectCounter{
+			{
+				Preamble:      &p4configapi.Preamble{Id: 0x13000001, Name: "ingress.acl_counter"},
+				Spec:          &p4configapi.CounterSpec{Unit: p4configapi.CounterSpec_BOTH},
+				DirectTableId: aclTableID,
+			},
+		},
+		Meters: []*p4configapi.Meter{
+			{
+				Preamble: &p4configapi.Preamble{Id: 0x15000001, Name: "ingress.port_meter"},
+				Spec:     &p4configapi.MeterSpec{Unit: p4configapi.MeterSpec_BYTES},
+				Size:     512,
+			},
+		},
+		DirectMeters: []*p4configapi.DirectMeter{
+			{
+				Preamble:      &p4configapi.Preamble{Id: 0x14000001, Name: "ingress.acl_meter"},
+				Spec:          &p4configapi.MeterSpec{Unit: p4configapi.MeterSpec_PACKETS},
+				DirectTableId: aclTableID,
+			},
+		},
+	}
+}
+
+func TestNewFromPipelineConfig(t *testing.T) {
+	p4Info, err := proto.Marshal(newTestP4Info())
+	assert.NoError(t, err)
+	b, err := NewFromPipelineConfig(&p4rtapi.PipelineConfig{
+		ID:   "pipeline-1",
+		Spec: &p4rtapi.PipelineConfigSpec{P4Info: p4Info},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, b.P4Info().Tables, 3)
+
+	_, err = NewFromPipelineConfig(&p4rtapi.PipelineConfig{ID: "pipeline-2"})
+	assert.True(t, errors.IsInvalid(err))
+	_, err = NewFromPipelineConfig(&p4rtapi.PipelineConfig{
+		ID:   "pipeline-3",
+		Spec: &p4rtapi.PipelineConfigSpec{P4Info: []byte{0xff}},
+	})
+	assert.True(t, errors.IsInvalid(err))
+}
+
+func TestBuilder_Action(t *testing.T) {
+	b := New(newTestP4Info())
+
+	action, err := b.Action("ingress.forward",
+		NewParam("dmac", []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x01}),
+		NewParam("port", []byte{0x00, 0x00, 0x01, 0x01}))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(forwardID), action.ActionId)
+	assert.Len(t, action.Params, 2)
+	assert.Equal(t, uint32(1), action.Params[0].ParamId)
+	assert.Equal(t, []byte{0x01, 0x01}, action.Params[0].Value)
+	assert.Equal(t, []byte{0x01}, action.Params[1].Value)
+
+	_, err = b.Action("ingress.unknown")
+	assert.True(t, errors.IsNotFound(err))
+	_, err = b.Action("ingress.forward", NewParam("port", []byte{0x01}))
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.Action("ingress.forward", NewParam("port", []byte{0x02, 0x00}), NewParam("dmac", []byte{0x01}))
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.Action("ingress.forward", NewParam("port", []byte{0x01}), NewParam("port", []byte{0x01}), NewParam("dmac", []byte{0x01}))
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.Action("ingress.forward", NewParam("port", []byte{0x01}), NewParam("dmac", []byte{0x01}), NewParam("vlan", []byte{0x01}))
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.Action("ingress.forward", NewParam("port", nil), NewParam("dmac", []byte{0x01}))
+	assert.True(t, errors.IsInvalid(err))
+}
+
+func TestBuilder_ActionProfiles(t *testing.T) {
+	b := New(newTestP4Info())
+
+	member, err := b.ActionProfileMember("ingress.next_selector", 1, "ingress.forward",
+		NewParam("port", []byte{0x01}), NewParam("dmac", []byte{0x01}))
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(selectorID), member.ActionProfileId)
+	assert.Equal(t, uint32(1), member.MemberId)
+	assert.Equal(t, uint32(forwardID), member.Action.ActionId)
+
+	_, err = b.ActionProfileMember("ingress.next_selector", 2, "ingress.drop")
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.ActionProfileMember("ingress.unknown", 2, "ingress.drop")
+	assert.True(t, errors.IsNotFound(err))
+
+	group, err := b.ActionProfileGroup("ingress.next_selector", 10, 8,
+		GroupMember{MemberID: 1, Weight: 3}, GroupMember{MemberID: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), group.GroupId)
+	assert.Equal(t, int32(8), group.MaxSize)
+	assert.Len(t, group.Members, 2)
+	assert.Equal(t, int32(3), group.Members[0].Weight)
+	assert.Equal(t, int32(1), group.Members[1].Weight)
+
+	_, err = b.ActionProfileGroup("ingress.next_selector", 10, 32)
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.ActionProfileGroup("ingress.next_selector", 10, 4, GroupMember{MemberID: 1, Weight: 5})
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.ActionProfileGroup("ingress.next_selector", 10, 4, GroupMember{MemberID: 1}, GroupMember{MemberID: 1})
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.ActionProfileGroup("ingress.next_selector", 10, 4, GroupMember{MemberID: 1, Weight: -1})
+	assert.True(t, errors.IsInvalid(err))
+}
+
+func TestBuilder_TableEntry(t *testing.T) {
+	b := New(newTestP4Info())
+
+	entry, err := b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x00, 0x01}).
+		LPM("ipv4_dst", []byte{10, 0, 1, 0}, 24).
+		Action("ingress.set_next", NewParam("next_id", []byte{0x00, 0x00, 0x00, 0x05})).
+		IdleTimeout(time.Minute).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(routingTableID), entry.TableId)
+	assert.Len(t, entry.Match, 2)
+	assert.Equal(t, []byte{0x01}, entry.Match[0].GetExact().Value)
+	assert.Equal(t, []byte{10, 0, 1, 0}, entry.Match[1].GetLpm().Value)
+	assert.Equal(t, int32(24), entry.Match[1].GetLpm().PrefixLen)
+	assert.Equal(t, []byte{0x05}, entry.Action.GetAction().Params[0].Value)
+	assert.Equal(t, time.Minute.Nanoseconds(), entry.IdleTimeoutNs)
+
+	// Bits beyond the prefix length must be zero
+	_, err = b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x01}).
+		LPM("ipv4_dst", []byte{10, 0, 1, 1}, 24).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	// Exact match fields are required
+	_, err = b.TableEntry("ingress.routing").
+		LPM("ipv4_dst", []byte{10, 0, 0, 0}, 8).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	// Values cannot exceed the bit width of the field
+	_, err = b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x10, 0x00}).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	// Match types must match the field
+	_, err = b.TableEntry("ingress.routing").
+		Exact("ipv4_dst", []byte{10, 0, 0, 1}).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x01}).
+		Exact("vrf", []byte{0x02}).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.TableEntry("ingress.routing").
+		Exact("unknown", []byte{0x01}).
+		Build()
+	assert.True(t, errors.IsNotFound(err))
+	_, err = b.TableEntry("ingress.unknown").Build()
+	assert.True(t, errors.IsNotFound(err))
+	// Exact tables cannot have priorities
+	_, err = b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x01}).
+		Priority(10).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	// DEFAULT_ONLY actions can only be default actions
+	_, err = b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x01}).
+		Action("NoAction").
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	entry, err = b.TableEntry("ingress.routing").
+		Action("NoAction").
+		Default().
+		Build()
+	assert.NoError(t, err)
+	assert.True(t, entry.IsDefaultAction)
+	assert.Empty(t, entry.Match)
+	_, err = b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x01}).
+		Action("NoAction").
+		Default().
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+}
+
+func TestBuilder_TernaryTableEntry(t *testing.T) {
+	b := New(newTestP4Info())
+
+	entry, err := b.TableEntry("ingress.acl").
+		Ternary("eth_type", []byte{0x08, 0x00}, []byte{0xff, 0xff}).
+		Range("l4_dport", []byte{0x00, 0x50}, []byte{0x01, 0xbb}).
+		Optional("ingress_port", []byte{0x01, 0x00}).
+		Priority(10).
+		Action("ingress.drop").
+		Build()
+	assert.NoError(t, err)
+	assert.Len(t, entry.Match, 3)
+	assert.Equal(t, []byte{0x08, 0x00}, entry.Match[0].GetTernary().Value)
+	assert.Equal(t, []byte{0x50}, entry.Match[1].GetRange().Low)
+	assert.Equal(t, []byte{0x01, 0xbb}, entry.Match[1].GetRange().High)
+	assert.Equal(t, []byte{0x01, 0x00}, entry.Match[2].GetOptional().Value)
+	assert.Equal(t, int32(10), entry.Priority)
+
+	// Priorities are required
+	_, err = b.TableEntry("ingress.acl").
+		Ternary("eth_type", []byte{0x08, 0x00}, []byte{0xff, 0xff}).
+		Action("ingress.drop").
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	// Values cannot have bits set outside the mask
+	_, err = b.TableEntry("ingress.acl").
+		Ternary("eth_type", []byte{0x08, 0x01}, []byte{0xff, 0x00}).
+		Priority(10).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.TableEntry("ingress.acl").
+		Ternary("eth_type", []byte{0x00}, []byte{0x00}).
+		Priority(10).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.TableEntry("ingress.acl").
+		Range("l4_dport", []byte{0x01, 0xbb}, []byte{0x50}).
+		Priority(10).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.TableEntry("ingress.acl").
+		Optional("ingress_port", []byte{0x02, 0x00}).
+		Priority(10).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	// TABLE_ONLY actions cannot be default actions
+	_, err = b.TableEntry("ingress.acl").
+		Action("ingress.drop").
+		Default().
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	// Tables without idle timeout notifications cannot have idle timeouts
+	_, err = b.TableEntry("ingress.acl").
+		IdleTimeout(time.Minute).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+}
+
+func TestBuilder_IndirectTableEntry(t *testing.T) {
+	b := New(newTestP4Info())
+
+	entry, err := b.TableEntry("ingress.next").
+		Exact("next_id", []byte{0x05}).
+		ActionProfileGroup(10).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(10), entry.Action.GetActionProfileGroupId())
+
+	entry, err = b.TableEntry("ingress.next").
+		Exact("next_id", []byte{0x06}).
+		ActionProfileMember(1).
+		Build()
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(1), entry.Action.GetActionProfileMemberId())
+
+	_, err = b.TableEntry("ingress.next").
+		Exact("next_id", []byte{0x05}).
+		Action("ingress.forward", NewParam("port", []byte{0x01}), NewParam("dmac", []byte{0x01})).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x01}).
+		ActionProfileGroup(10).
+		Build()
+	assert.True(t, errors.IsInvalid(err))
+}
+
+func TestBuilder_Counters(t *testing.T) {
+	b := New(newTestP4Info())
+
+	counterEntry, err := b.CounterEntry("ingress.port_packets", 1, &p4api.CounterData{PacketCount: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x12000001), counterEntry.CounterId)
+	assert.Equal(t, int64(1), counterEntry.Index.Index)
+	_, err = b.CounterEntry("ingress.port_packets", 512, nil)
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.CounterEntry("ingress.port_packets", 1, &p4api.CounterData{ByteCount: 10})
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.CounterEntry("ingress.unknown", 1, nil)
+	assert.True(t, errors.IsNotFound(err))
+
+	aclEntry, err := b.TableEntry("ingress.acl").
+		Ternary("eth_type", []byte{0x08, 0x00}, []byte{0xff, 0xff}).
+		Priority(10).
+		Build()
+	assert.NoError(t, err)
+	directCounterEntry, err := b.DirectCounterEntry("ingress.acl_counter", aclEntry, &p4api.CounterData{ByteCount: 100, PacketCount: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, aclEntry, directCounterEntry.TableEntry)
+
+	routingEntry, err := b.TableEntry("ingress.routing").
+		Exact("vrf", []byte{0x01}).
+		Build()
+	assert.NoError(t, err)
+	_, err = b.DirectCounterEntry("ingress.acl_counter", routingEntry, nil)
+	assert.True(t, errors.IsInvalid(err))
+
+	meterEntry, err := b.MeterEntry("ingress.port_meter", 1, &p4api.MeterConfig{Cir: 1000, Cburst: 100, Pir: 2000, Pburst: 200})
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(0x15000001), meterEntry.MeterId)
+	_, err = b.MeterEntry("ingress.port_meter", -1, nil)
+	assert.True(t, errors.IsInvalid(err))
+	_, err = b.MeterEntry("ingress.port_meter", 1, &p4api.MeterConfig{Cir: 2000, Cburst: 100, Pir: 1000, Pburst: 200})
+	assert.True(t, errors.IsInvalid(err))
+
+	directMeterEntry, err := b.DirectMeterEntry("ingress.acl_meter", aclEntry, &p4api.MeterConfig{Cir: 10, Cburst: 10, Pir: 10, Pburst: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, aclEntry, directMeterEntry.TableEntry)
+	_, err = b.DirectMeterEntry("ingress.acl_meter", routingEntry, nil)
+	assert.True(t, errors.IsInvalid(err))
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package builder
+
+import (
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+)
+
+// CounterEntry returns the entry at the given index of an indirect counter; a nil data reads or resets the cell
+func (b *Builder) CounterEntry(name string, index int64, data *p4api.CounterData) (*p4api.CounterEntry, error) {
+	counter, ok := b.counters[name]
+	if !ok {
+		return nil, errors.NewNotFound("unknown counter '%s'", name)
+	}
+	if index < 0 || index >= counter.Size {
+		return nil, errors.NewInvalid("index %d of counter '%s' is out of range [0, %d)", index, name, counter.Size)
+	}
+	if err := checkCounterData(counter.Spec, data, name); err != nil {
+		return nil, err
+	}
+	return &p4api.CounterEntry{
+		CounterId: counter.Preamble.GetId(),
+		Index:     &p4api.Index{Index: index},
+		Data:      data,
+	}, nil
+}
+
+// DirectCounterEntry returns the entry of a direct counter for a table entry
+func (b *Builder) DirectCounterEntry(name string, tableEntry *p4api.TableEntry, data *p4api.CounterData) (*p4api.DirectCounterEntry, error) {
+	directCounter, ok := b.directCounters[name]
+	if !ok {
+		return nil, errors.NewNotFound("unknown direct counter '%s'", name)
+	}
+	if tableEntry == nil || tableEntry.TableId != directCounter.DirectTableId {
+		return nil, errors.NewInvalid("direct counter '%s' does not belong to the table of the entry", name)
+	}
+	if err := checkCounterData(directCounter.Spec, data, name); err != nil {
+		return nil, err
+	}
+	return &p4api.DirectCounterEntry{
+		TableEntry: tableEntry,
+		Data:       data,
+	}, nil
+}
+
+// MeterEntry returns the entry at the given index of an indirect meter; a nil config reads or resets the cell
+func (b *Builder) MeterEntry(name string, index int64, config *p4api.MeterConfig) (*p4api.MeterEntry, error) {
+	meter, ok := b.meters[name]
+	if !ok {
+		return nil, errors.NewNotFound("unknown meter '%s'", name)
+	}
+	if index < 0 || index >= meter.Size {
+		return nil, errors.NewInvalid("index %d of meter '%s' is out of range [0, %d)", index, name, meter.Size)
+	}
+	if err := checkMeterConfig(config, name); err != nil {
+		return nil, err
+	}
+	return &p4api.MeterEntry{
+		MeterId: meter.Preamble.GetId(),
+		Index:   &p4api.Index{Index: index},
+		Config:  config,
+	}, nil
+}
+
+// DirectMeterEntry returns the entry of a direct meter for a table entry
+func (b *Builder) DirectMeterEntry(name string, tableEntry *p4api.TableEntry, config *p4api.MeterConfig) (*p4api.DirectMeterEntry, error) {
+	directMeter, ok := b.directMeters[name]
+	if !ok {
+		return nil, errors.NewNotFound("unknown direct meter '%s'", name)
+	}
+	if tableEntry == nil || tableEntry.TableId != directMeter.DirectTableId {
+		return nil, errors.NewInvalid("direct meter '%s' does not belong to the table of the entry", name)
+	}
+	if err := checkMeterConfig(config, name); err != nil {
+		return nil, err
+	}
+	return &p4api.DirectMeterEntry{
+		TableEntry: tableEntry,
+		Config:     config,
+	}, nil
+}
+
+// checkCounterData checks that counter data only sets the counts of the unit of the counter
+func checkCounterData(spec *p4configapi.CounterSpec, data *p4api.CounterData, name string) error {
+	if data == nil {
+		return nil
+	}
+	if data.ByteCount < 0 || data.PacketCount < 0 {
+		return errors.NewInvalid("counts of counter '%s' cannot be negative", name)
+	}
+	switch spec.GetUnit() {
+	case p4configapi.CounterSpec_BYTES:
+		if data.PacketCount != 0 {
+			return errors.NewInvalid("counter '%s' does not count packets", name)
+		}
+	case p4configapi.CounterSpec_PACKETS:
+		if data.ByteCount != 0 {
+			return errors.NewInvalid("counter '%s' does not count bytes", name)
+		}
+	}
+	return nil
+}
+
+// checkMeterConfig checks that the committed rate and burst of a meter config do not exceed the peak ones
+func checkMeterConfig(config *p4api.MeterConfig, name string) error {
+	if config == nil {
+		return nil
+	}
+	if config.Cir < 0 || config.Cburst < 0 || config.Pir < 0 || config.Pburst < 0 {
+		return errors.NewInvalid("rates and bursts of meter '%s' cannot be negative", name)
+	}
+	if config.Cir > config.Pir || config.Cburst > config.Pburst {
+		return errors.NewInvalid("committed rate and burst of meter '%s' cannot exceed the peak rate and burst", name)
+	}
+	return nil
+}
@@ -0,0 +1,342 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package builder
+
+import (
+	"bytes"
+	"time"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+)
+
+// TableEntryBuilder builds a table entry; the first error is reported by Build
+type TableEntryBuilder struct {
+	builder *Builder
+	table   *p4configapi.Table
+	entry   *p4api.TableEntry
+	fields  map[string]*p4configapi.MatchField
+	err     error
+}
+
+// TableEntry returns a builder for an entry of the table with the given name
+func (b *Builder) TableEntry(tableName string) *TableEntryBuilder {
+	t := &TableEntryBuilder{
+		builder: b,
+		fields:  make(map[string]*p4configapi.MatchField),
+	}
+	t.table, t.err = b.getTable(tableName)
+	if t.err != nil {
+		return t
+	}
+	for _, field := range t.table.MatchFields {
+		t.fields[field.Name] = field
+	}
+	t.entry = &p4api.TableEntry{
+		TableId: t.table.Preamble.GetId(),
+	}
+	return t
+}
+
+// match adds a match of the given type on a field
+func (t *TableEntryBuilder) match(fieldName string, matchType p4configapi.MatchField_MatchType, match func(field *p4configapi.MatchField) (*p4api.FieldMatch, error)) *TableEntryBuilder {
+	if t.err != nil {
+		return t
+	}
+	field, ok := t.fields[fieldName]
+	if !ok {
+		t.err = errors.NewNotFound("unknown match field '%s' of table '%s'", fieldName, t.table.Preamble.GetName())
+		return t
+	}
+	if field.GetMatchType() != matchType {
+		t.err = errors.NewInvalid("match field '%s' of table '%s' is a %s field", fieldName, t.table.Preamble.GetName(), field.GetMatchType())
+		return t
+	}
+	for _, fieldMatch := range t.entry.Match {
+		if fieldMatch.FieldId == field.Id {
+			t.err = errors.NewInvalid("duplicate match on field '%s' of table '%s'", fieldName, t.table.Preamble.GetName())
+			return t
+		}
+	}
+	fieldMatch, err := match(field)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	fieldMatch.FieldId = field.Id
+	t.entry.Match = append(t.entry.Match, fieldMatch)
+	return t
+}
+
+// Exact matches a field on a value
+func (t *TableEntryBuilder) Exact(fieldName string, value []byte) *TableEntryBuilder {
+	return t.match(fieldName, p4configapi.MatchField_EXACT, func(field *p4configapi.MatchField) (*p4api.FieldMatch, error) {
+		value, err := encode(value, field.Bitwidth, "match field", field.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &p4api.FieldMatch{
+			FieldMatchType: &p4api.FieldMatch_Exact_{Exact: &p4api.FieldMatch_Exact{Value: value}},
+		}, nil
+	})
+}
+
+// LPM matches a field on a prefix; the bits of the value beyond the prefix length must be zero
+func (t *TableEntryBuilder) LPM(fieldName string, value []byte, prefixLen int32) *TableEntryBuilder {
+	return t.match(fieldName, p4configapi.MatchField_LPM, func(field *p4configapi.MatchField) (*p4api.FieldMatch, error) {
+		if prefixLen <= 0 || prefixLen > field.Bitwidth {
+			return nil, errors.NewInvalid("invalid prefix length %d of match field '%s'; a field can only be wildcarded by omitting it", prefixLen, field.Name)
+		}
+		value, err := encode(value, field.Bitwidth, "match field", field.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !isMasked(value, prefixMask(field.Bitwidth, prefixLen)) {
+			return nil, errors.NewInvalid("value of match field '%s' has bits set beyond the prefix length %d", field.Name, prefixLen)
+		}
+		return &p4api.FieldMatch{
+			FieldMatchType: &p4api.FieldMatch_Lpm{Lpm: &p4api.FieldMatch_LPM{Value: value, PrefixLen: prefixLen}},
+		}, nil
+	})
+}
+
+// Ternary matches a field on a value with a mask; the bits of the value outside the mask must be zero
+func (t *TableEntryBuilder) Ternary(fieldName string, value []byte, mask []byte) *TableEntryBuilder {
+	return t.match(fieldName, p4configapi.MatchField_TERNARY, func(field *p4configapi.MatchField) (*p4api.FieldMatch, error) {
+		value, err := encode(value, field.Bitwidth, "match field", field.Name)
+		if err != nil {
+			return nil, err
+		}
+		mask, err := encode(mask, field.Bitwidth, "mask of match field", field.Name)
+		if err != nil {
+			return nil, err
+		}
+		if isZero(mask) {
+			return nil, errors.NewInvalid("mask of match field '%s' is zero; a field can only be wildcarded by omitting it", field.Name)
+		}
+		if !isMasked(value, mask) {
+			return nil, errors.NewInvalid("value of match field '%s' has bits set outside its mask", field.Name)
+		}
+		return &p4api.FieldMatch{
+			FieldMatchType: &p4api.FieldMatch_Ternary_{Ternary: &p4api.FieldMatch_Ternary{Value: value, Mask: mask}},
+		}, nil
+	})
+}
+
+// Range matches a field on a range of values, bounds included
+func (t *TableEntryBuilder) Range(fieldName string, low []byte, high []byte) *TableEntryBuilder {
+	return t.match(fieldName, p4configapi.MatchField_RANGE, func(field *p4configapi.MatchField) (*p4api.FieldMatch, error) {
+		low, err := encode(low, field.Bitwidth, "low bound of match field", field.Name)
+		if err != nil {
+			return nil, err
+		}
+		high, err := encode(high, field.Bitwidth, "high bound of match field", field.Name)
+		if err != nil {
+			return nil, err
+		}
+		if compare(low, high) > 0 {
+			return nil, errors.NewInvalid("low bound of match field '%s' is greater than its high bound", field.Name)
+		}
+		return &p4api.FieldMatch{
+			FieldMatchType: &p4api.FieldMatch_Range_{Range: &p4api.FieldMatch_Range{Low: low, High: high}},
+		}, nil
+	})
+}
+
+// Optional matches a field on a value
+func (t *TableEntryBuilder) Optional(fieldName string, value []byte) *TableEntryBuilder {
+	return t.match(fieldName, p4configapi.MatchField_OPTIONAL, func(field *p4configapi.MatchField) (*p4api.FieldMatch, error) {
+		value, err := encode(value, field.Bitwidth, "match field", field.Name)
+		if err != nil {
+			return nil, err
+		}
+		return &p4api.FieldMatch{
+			FieldMatchType: &p4api.FieldMatch_Optional_{Optional: &p4api.FieldMatch_Optional{Value: value}},
+		}, nil
+	})
+}
+
+// Priority sets the priority of the entry; entries of tables with ternary, range or optional match fields
+// must have a priority and entries of other tables must not
+func (t *TableEntryBuilder) Priority(priority int32) *TableEntryBuilder {
+	if t.err == nil {
+		t.entry.Priority = priority
+	}
+	return t
+}
+
+// Action sets the direct action of the entry
+func (t *TableEntryBuilder) Action(actionName string, params ...Param) *TableEntryBuilder {
+	if t.err != nil {
+		return t
+	}
+	if t.table.ImplementationId != 0 {
+		t.err = errors.NewInvalid("entries of table '%s' must refer to action profile members or groups", t.table.Preamble.GetName())
+		return t
+	}
+	action, err := t.builder.Action(actionName, params...)
+	if err != nil {
+		t.err = err
+		return t
+	}
+	t.entry.Action = &p4api.TableAction{
+		Type: &p4api.TableAction_Action{Action: action},
+	}
+	return t
+}
+
+// ActionProfileMember sets the action profile member of the entry
+func (t *TableEntryBuilder) ActionProfileMember(memberID uint32) *TableEntryBuilder {
+	if t.err != nil {
+		return t
+	}
+	if t.table.ImplementationId == 0 {
+		t.err = errors.NewInvalid("table '%s' is not implemented by an action profile", t.table.Preamble.GetName())
+		return t
+	}
+	t.entry.Action = &p4api.TableAction{
+		Type: &p4api.TableAction_ActionProfileMemberId{ActionProfileMemberId: memberID},
+	}
+	return t
+}
+
+// ActionProfileGroup sets the action profile group of the entry
+func (t *TableEntryBuilder) ActionProfileGroup(groupID uint32) *TableEntryBuilder {
+	if t.err != nil {
+		return t
+	}
+	if t.table.ImplementationId == 0 {
+		t.err = errors.NewInvalid("table '%s' is not implemented by an action profile", t.table.Preamble.GetName())
+		return t
+	}
+	t.entry.Action = &p4api.TableAction{
+		Type: &p4api.TableAction_ActionProfileGroupId{ActionProfileGroupId: groupID},
+	}
+	return t
+}
+
+// Default makes the entry the default entry of the table, which has no match
+func (t *TableEntryBuilder) Default() *TableEntryBuilder {
+	if t.err == nil {
+		t.entry.IsDefaultAction = true
+	}
+	return t
+}
+
+// IdleTimeout sets the idle timeout of the entry
+func (t *TableEntryBuilder) IdleTimeout(idleTimeout time.Duration) *TableEntryBuilder {
+	if t.err != nil {
+		return t
+	}
+	if t.table.IdleTimeoutBehavior != p4configapi.Table_NOTIFY_CONTROL {
+		t.err = errors.NewInvalid("table '%s' does not support idle timeouts", t.table.Preamble.GetName())
+		return t
+	}
+	t.entry.IdleTimeoutNs = idleTimeout.Nanoseconds()
+	return t
+}
+
+// Metadata sets the controller metadata of the entry
+func (t *TableEntryBuilder) Metadata(metadata []byte) *TableEntryBuilder {
+	if t.err == nil {
+		t.entry.Metadata = metadata
+	}
+	return t
+}
+
+// Build returns the table entry
+func (t *TableEntryBuilder) Build() (*p4api.TableEntry, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	tableName := t.table.Preamble.GetName()
+	if t.entry.IsDefaultAction {
+		if len(t.entry.Match) > 0 || t.entry.Priority != 0 {
+			return nil, errors.NewInvalid("default entry of table '%s' cannot have a match or priority", tableName)
+		}
+		if t.table.ConstDefaultActionId != 0 {
+			return nil, errors.NewInvalid("default action of table '%s' is const", tableName)
+		}
+	} else {
+		needsPriority := false
+		for _, field := range t.table.MatchFields {
+			switch field.GetMatchType() {
+			case p4configapi.MatchField_TERNARY, p4configapi.MatchField_RANGE, p4configapi.MatchField_OPTIONAL:
+				needsPriority = true
+			case p4configapi.MatchField_EXACT:
+				if !t.hasMatch(field.Id) {
+					return nil, errors.NewInvalid("missing exact match field '%s' of table '%s'", field.Name, tableName)
+				}
+			}
+		}
+		if needsPriority && t.entry.Priority <= 0 {
+			return nil, errors.NewInvalid("entries of table '%s' must have a positive priority", tableName)
+		}
+		if !needsPriority && t.entry.Priority != 0 {
+			return nil, errors.NewInvalid("entries of table '%s' cannot have a priority", tableName)
+		}
+	}
+	if action := t.entry.GetAction().GetAction(); action != nil {
+		if err := t.builder.checkActionRef(t.table, action.ActionId, t.entry.IsDefaultAction); err != nil {
+			return nil, err
+		}
+	}
+	return t.entry, nil
+}
+
+func (t *TableEntryBuilder) hasMatch(fieldID uint32) bool {
+	for _, fieldMatch := range t.entry.Match {
+		if fieldMatch.FieldId == fieldID {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixMask returns the mask of a prefix of a field of the given bit width
+func prefixMask(bitwidth int32, prefixLen int32) []byte {
+	mask := make([]byte, (bitwidth+7)/8)
+	// The first byte only holds the most significant bitwidth%8 bits of the field
+	offset := int32(len(mask))*8 - bitwidth
+	for bit := offset; bit < offset+prefixLen; bit++ {
+		mask[bit/8] |= 0x80 >> (bit % 8)
+	}
+	return mask
+}
+
+// isMasked returns whether a value has no bits set outside a mask; values and masks are right-aligned
+func isMasked(value []byte, mask []byte) bool {
+	for i := 1; i <= len(value); i++ {
+		var maskByte byte
+		if i <= len(mask) {
+			maskByte = mask[len(mask)-i]
+		}
+		if value[len(value)-i]&^maskByte != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isZero(value []byte) bool {
+	for _, b := range value {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// compare compares two canonical binary strings
+func compare(a []byte, b []byte) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+		return 1
+	}
+	return bytes.Compare(a, b)
+}
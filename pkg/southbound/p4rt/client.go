@@ -18,6 +18,9 @@ type Client interface {
 	WriteClient
 	ReadClient
 	StreamClient
+	PacketClient
+	DigestClient
+	IdleTimeoutClient
 	PipelineConfigClient
 	Capabilities(ctx context.Context, request *p4api.CapabilitiesRequest, opts ...grpc.CallOption) (*p4api.CapabilitiesResponse, error)
 }
@@ -29,11 +32,14 @@ type client struct {
 	readClient           *readClient
 	pipelineConfigClient *pipelineConfigClient
 	streamClient         *streamClient
+	packetClient         *packetClient
+	digestClient         *digestClient
+	idleTimeoutClient    *idleTimeoutClient
+	p4Infos              *p4InfoCache
 }
 
-func (c *client) RecvArbitrationResponse() (*p4api.StreamMessageResponse_Arbitration, error) {
-	response, err := c.streamClient.RecvArbitrationResponse()
-	return response, errors.FromGRPC(err)
+func (c *client) Subscribe(ctx context.Context, ch chan<- *p4api.StreamMessageResponse, types ...StreamMessageType) error {
+	return c.streamClient.Subscribe(ctx, ch, types...)
 }
 
 func (c *client) SendArbitrationRequest(deviceID uint64, electionID uint64, role string) error {
@@ -41,6 +47,34 @@ func (c *client) SendArbitrationRequest(deviceID uint64, electionID uint64, role
 	return errors.FromGRPC(err)
 }
 
+func (c *client) SendPacketOut(ctx context.Context, packet *PacketOut) error {
+	return c.packetClient.SendPacketOut(ctx, packet)
+}
+
+func (c *client) SubscribePacketIn(ctx context.Context, ch chan<- *PacketIn) error {
+	return c.packetClient.SubscribePacketIn(ctx, ch)
+}
+
+func (c *client) ConfigureDigest(ctx context.Context, electionID uint64, name string, config DigestConfig) error {
+	return c.digestClient.ConfigureDigest(ctx, electionID, name, config)
+}
+
+func (c *client) RemoveDigest(ctx context.Context, electionID uint64, name string) error {
+	return c.digestClient.RemoveDigest(ctx, electionID, name)
+}
+
+func (c *client) SubscribeDigests(ctx context.Context, ch chan<- *DigestList) error {
+	return c.digestClient.SubscribeDigests(ctx, ch)
+}
+
+func (c *client) SubscribeIdleTimeouts(ctx context.Context, ch chan<- *IdleTimeout, tables ...string) error {
+	return c.idleTimeoutClient.SubscribeIdleTimeouts(ctx, ch, tables...)
+}
+
+func (c *client) HandleIdleTimeouts(ctx context.Context, electionID func() uint64, handler IdleTimeoutHandler, tables ...string) error {
+	return c.idleTimeoutClient.HandleIdleTimeouts(ctx, electionID, handler, tables...)
+}
+
 func (c *client) ReadEntities(ctx context.Context, request *p4api.ReadRequest, opts ...grpc.CallOption) ([]*p4api.Entity, error) {
 	log.Debugw("Received read entities request", "request", request)
 	entities, err := c.readClient.ReadEntities(ctx, request, opts...)
@@ -62,6 +96,10 @@ func (c *client) Write(ctx context.Context, request *p4api.WriteRequest, opts ..
 func (c *client) SetForwardingPipelineConfig(ctx context.Context, request *p4api.SetForwardingPipelineConfigRequest, opts ...grpc.CallOption) (*p4api.SetForwardingPipelineConfigResponse, error) {
 	log.Debugw("Received SetForwardingPipelineConfig request", "request", request)
 	setForwardingPipelineConfigResponse, err := c.pipelineConfigClient.SetForwardingPipelineConfig(ctx, request, opts...)
+	if err == nil {
+		// Packet metadata and digests are encoded with the P4Info of the new pipeline
+		c.p4Infos.reset()
+	}
 	return setForwardingPipelineConfigResponse, errors.FromGRPC(err)
 }
 
@@ -69,6 +107,9 @@ func (c *client) SetForwardingPipelineConfig(ctx context.Context, request *p4api
 func (c *client) GetForwardingPipelineConfig(ctx context.Context, request *p4api.GetForwardingPipelineConfigRequest, opts ...grpc.CallOption) (*p4api.GetForwardingPipelineConfigResponse, error) {
 	log.Debugw("Received GetForwardingPipelineConfig request", "request", request)
 	getForwardingPipelineConfigResponse, err := c.pipelineConfigClient.GetForwardingPipelineConfig(ctx, request, opts...)
+	if err == nil {
+		c.p4Infos.checkCookie(getForwardingPipelineConfigResponse.GetConfig().GetCookie())
+	}
 	return getForwardingPipelineConfigResponse, errors.FromGRPC(err)
 }
 
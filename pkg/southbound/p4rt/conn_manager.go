@@ -116,7 +116,7 @@ func (m *connManager) Connect(ctx context.Context, target *topoapi.Object) error
 		log.Errorw("Cannot open a p4rt stream for connection", "targetID", target.ID, "error", err)
 		return err
 	}
-	p4rtClient.streamClient.streamChannel = streamChannel
+	p4rtClient.streamClient.setStreamChannel(streamChannel)
 	go func() {
 		var conn Conn
 		state := clientConn.GetState()
@@ -144,7 +144,7 @@ func (m *connManager) Connect(ctx context.Context, target *topoapi.Object) error
 						log.Warnw("Cannot open a p4rt stream for connection", "targetID", target.ID, "error", err)
 						continue
 					}
-					p4rtClient.streamClient.streamChannel = streamChannel
+					p4rtClient.streamClient.setStreamChannel(streamChannel)
 					m.addConn(conn)
 				}
 
@@ -260,21 +260,49 @@ func connect(ctx context.Context, d Destination, opts ...grpc.DialOption) (*clie
 
 	cl := p4v1.NewP4RuntimeClient(conn)
 
-	p4rtClient := &client{
-		grpcClient:      conn,
+	pipelineConfigClient := &pipelineConfigClient{
 		p4runtimeClient: cl,
-		writeClient: &writeClient{
-			p4runtimeClient: cl,
-		},
-		readClient: &readClient{
-			p4runtimeClient: cl,
+	}
+	p4Infos := &p4InfoCache{
+		deviceID:             d.DeviceID,
+		pipelineConfigClient: pipelineConfigClient,
+	}
+	streamClient := &streamClient{
+		p4runtimeClient: cl,
+		p4Infos:         p4Infos,
+		subscribers:     make(map[uuid.UUID]*streamSubscriber),
+	}
+	writeClient := &writeClient{
+		p4runtimeClient: cl,
+	}
+	readClient := &readClient{
+		p4runtimeClient: cl,
+	}
+	p4rtClient := &client{
+		grpcClient:           conn,
+		p4runtimeClient:      cl,
+		writeClient:          writeClient,
+		readClient:           readClient,
+		pipelineConfigClient: pipelineConfigClient,
+		streamClient:         streamClient,
+		packetClient: &packetClient{
+			streamClient: streamClient,
+			p4Infos:      p4Infos,
 		},
-		pipelineConfigClient: &pipelineConfigClient{
-			p4runtimeClient: cl,
+		digestClient: &digestClient{
+			deviceID:     d.DeviceID,
+			writeClient:  writeClient,
+			streamClient: streamClient,
+			p4Infos:      p4Infos,
 		},
-		streamClient: &streamClient{
-			p4runtimeClient: cl,
+		idleTimeoutClient: &idleTimeoutClient{
+			deviceID:     d.DeviceID,
+			writeClient:  writeClient,
+			readClient:   readClient,
+			streamClient: streamClient,
+			p4Infos:      p4Infos,
 		},
+		p4Infos: p4Infos,
 	}
 
 	return p4rtClient, conn, nil
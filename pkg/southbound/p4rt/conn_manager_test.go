@@ -7,12 +7,15 @@ package p4rt
 import (
 	"context"
 	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
 	"github.com/onosproject/onos-lib-go/pkg/northbound"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
 	p4api "github.com/p4lang/p4runtime/go/p4/v1"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"sync"
+	"sync/atomic"
 
 	"io"
 	"testing"
@@ -37,16 +40,102 @@ type testServer struct {
 	p4api.UnimplementedP4RuntimeServer
 }
 
+// testStream is a stream channel of the test server
+type testStream struct {
+	server p4api.P4Runtime_StreamChannelServer
+	sendMu *sync.Mutex
+}
+
+func (s *testStream) send(response *p4api.StreamMessageResponse) {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if err := s.server.Send(response); err != nil {
+		log.Warn(err)
+	}
+}
+
+var (
+	// testStreams are the last stream channels which sent an arbitration request for each device ID
+	testStreams   = make(map[uint64]*testStream)
+	testStreamsMu sync.Mutex
+	// testDigestAcks are the digest list acknowledgements received by the test server
+	testDigestAcks = make(chan *p4api.DigestListAck, 10)
+	// testTableWrites are the write requests of table entry modifications and deletions received by the test server
+	testTableWrites = make(chan *p4api.WriteRequest, 10)
+	// testTableReads are the idle table entries read from the test server
+	testTableReads = make(chan *p4api.TableEntry, 10)
+)
+
+const testIdleTableID = 0x02000001
+
+func newTestIdleTableEntry(priority int32, idleTimeout time.Duration) *p4api.TableEntry {
+	return &p4api.TableEntry{
+		TableId: testIdleTableID,
+		Match: []*p4api.FieldMatch{
+			{
+				FieldId:        1,
+				FieldMatchType: &p4api.FieldMatch_Exact_{Exact: &p4api.FieldMatch_Exact{Value: []byte{2, 0, 0, 0, 0, 1}}},
+			},
+		},
+		Priority:      priority,
+		IdleTimeoutNs: idleTimeout.Nanoseconds(),
+	}
+}
+
 func (s testServer) Write(ctx context.Context, request *p4api.WriteRequest) (*p4api.WriteResponse, error) {
 	log.Infow("Write request is received", "request", request)
 	response := &p4api.WriteResponse{}
 
+	// Send a digest list once a digest is enabled
+	testStreamsMu.Lock()
+	stream, ok := testStreams[request.DeviceId]
+	testStreamsMu.Unlock()
+	for _, update := range request.Updates {
+		// Notify the table entries with an idle timeout right away and record their updates
+		if tableEntry := update.GetEntity().GetTableEntry(); tableEntry != nil && tableEntry.TableId == testIdleTableID {
+			if update.Type != p4api.Update_INSERT {
+				testTableWrites <- request
+			} else if ok && tableEntry.IdleTimeoutNs > 0 {
+				stream.send(&p4api.StreamMessageResponse{
+					Update: &p4api.StreamMessageResponse_IdleTimeoutNotification{
+						IdleTimeoutNotification: &p4api.IdleTimeoutNotification{
+							TableEntry: []*p4api.TableEntry{newTestIdleTableEntry(tableEntry.Priority, time.Duration(tableEntry.IdleTimeoutNs))},
+						},
+					},
+				})
+			}
+		}
+		if digestEntry := update.GetEntity().GetDigestEntry(); digestEntry != nil && update.Type == p4api.Update_INSERT && ok {
+			stream.send(&p4api.StreamMessageResponse{
+				Update: &p4api.StreamMessageResponse_Digest{
+					Digest: &p4api.DigestList{
+						DigestId: digestEntry.DigestId,
+						ListId:   1,
+						Data:     []*p4api.P4Data{newTestDigestData([]byte{2, 0, 0, 0, 0, 1}, []byte{1})},
+					},
+				},
+			})
+		}
+	}
 	return response, nil
 }
 
 func (s testServer) Read(request *p4api.ReadRequest, server p4api.P4Runtime_ReadServer) error {
 	log.Infow("Read request is received", "request", request)
 	var entities []*p4api.Entity
+	// Reads of a single idle table entry return the entry with an action and its counters
+	if len(request.Entities) == 1 && request.Entities[0].GetTableEntry().GetTableId() == testIdleTableID {
+		testTableReads <- request.Entities[0].GetTableEntry()
+		tableEntry := newTestIdleTableEntry(request.Entities[0].GetTableEntry().Priority, time.Second)
+		tableEntry.Action = &p4api.TableAction{
+			Type: &p4api.TableAction_Action{Action: &p4api.Action{ActionId: 1}},
+		}
+		tableEntry.CounterData = &p4api.CounterData{PacketCount: 10, ByteCount: 1000}
+		tableEntry.TimeSinceLastHit = &p4api.TableEntry_IdleTimeout{ElapsedNs: time.Second.Nanoseconds()}
+		return server.Send(&p4api.ReadResponse{
+			Entities: []*p4api.Entity{{Entity: &p4api.Entity_TableEntry{TableEntry: tableEntry}}},
+		})
+	}
 	entity1 := &p4api.Entity{
 		Entity: &p4api.Entity_TableEntry{},
 	}
@@ -74,13 +163,20 @@ func (s testServer) SetForwardingPipelineConfig(ctx context.Context, request *p4
 
 func (s testServer) GetForwardingPipelineConfig(ctx context.Context, request *p4api.GetForwardingPipelineConfigRequest) (*p4api.GetForwardingPipelineConfigResponse, error) {
 	log.Infow("Get forwarding pipeline config request is received", "request", request)
-	response := &p4api.GetForwardingPipelineConfigResponse{}
+	response := &p4api.GetForwardingPipelineConfigResponse{
+		Config: &p4api.ForwardingPipelineConfig{
+			P4Info: addTestTables(addTestDigests(newTestPacketP4Info())),
+		},
+	}
 	return response, nil
 }
 
 func (s testServer) StreamChannel(server p4api.P4Runtime_StreamChannelServer) error {
 	ctx := server.Context()
-
+	stream := &testStream{
+		server: server,
+		sendMu: &sync.Mutex{},
+	}
 	for {
 		select {
 		case <-ctx.Done():
@@ -113,11 +209,24 @@ func (s testServer) StreamChannel(server p4api.P4Runtime_StreamChannelServer) er
 					},
 				},
 			}
+			testStreamsMu.Lock()
+			testStreams[v.Arbitration.DeviceId] = stream
+			testStreamsMu.Unlock()
 			log.Info("Sending response")
-			if err := server.Send(&resp); err != nil {
-				log.Warn(err)
+			stream.send(&resp)
+		case *p4api.StreamMessageRequest_Packet:
+			// Loop packets back to the controller
+			resp := p4api.StreamMessageResponse{
+				Update: &p4api.StreamMessageResponse_Packet{
+					Packet: &p4api.PacketIn{
+						Payload:  v.Packet.Payload,
+						Metadata: v.Packet.Metadata,
+					},
+				},
 			}
-
+			stream.send(&resp)
+		case *p4api.StreamMessageRequest_DigestAck:
+			testDigestAcks <- v.DigestAck
 		}
 
 	}
@@ -208,7 +317,8 @@ func createTestTarget(t *testing.T, targetID string, deviceID uint64, insecure b
 			Address: targetHost,
 			Port:    targetPort,
 		},
-		Timeout: &timeout,
+		DeviceID: deviceID,
+		Timeout:  &timeout,
 	})
 
 	assert.NoError(t, err)
@@ -387,25 +497,25 @@ func TestClient_SetMasterArbitration(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, conn2)
 
+	ch1 := make(chan *p4api.StreamMessageResponse)
+	assert.NoError(t, conn1.Subscribe(ctx, ch1, ArbitrationMessage))
+	ch2 := make(chan *p4api.StreamMessageResponse)
+	assert.NoError(t, conn2.Subscribe(ctx, ch2, ArbitrationMessage))
+
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
 		for i := 0; i < 10; i++ {
-			resp, err := conn1.RecvArbitrationResponse()
-			assert.NoError(t, err)
-			assert.Equal(t, uint64(1), resp.Arbitration.ElectionId.Low)
-			//t.Log(resp)
+			resp := <-ch1
+			assert.Equal(t, uint64(1), resp.GetArbitration().ElectionId.Low)
 		}
 		for i := 0; i < 10; i++ {
-			resp, err := conn2.RecvArbitrationResponse()
-			assert.NoError(t, err)
-			assert.Equal(t, uint64(2), resp.Arbitration.ElectionId.Low)
-			//t.Log(resp)
+			resp := <-ch2
+			assert.Equal(t, uint64(2), resp.GetArbitration().ElectionId.Low)
 		}
 		wg.Done()
 	}()
 
-	assert.NoError(t, err)
 	for i := 0; i < 10; i++ {
 		err = conn2.SendArbitrationRequest(deviceID2, 2, "")
 		assert.NoError(t, err)
@@ -421,6 +531,216 @@ func TestClient_SetMasterArbitration(t *testing.T) {
 	s.Stop()
 }
 
+func TestClient_StreamSubscribers(t *testing.T) {
+	s := setup(t, getTLSServerConfig(t))
+
+	connManager := NewConnManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target1 := createTestTarget(t, targetID1, deviceID1, true)
+	err := connManager.Connect(ctx, target1)
+	assert.NoError(t, err)
+	conn, err := connManager.GetByTarget(ctx, targetID1)
+	assert.NoError(t, err)
+
+	// Subscribers only receive the messages of the types they subscribed to
+	allCh := make(chan *p4api.StreamMessageResponse, 1)
+	assert.NoError(t, conn.Subscribe(ctx, allCh))
+	packetCh := make(chan *p4api.StreamMessageResponse, 1)
+	packetCtx, packetCancel := context.WithCancel(ctx)
+	assert.NoError(t, conn.Subscribe(packetCtx, packetCh, PacketInMessage))
+
+	assert.NoError(t, conn.SendArbitrationRequest(deviceID1, 1, ""))
+	resp := <-allCh
+	assert.Equal(t, ArbitrationMessage, GetStreamMessageType(resp))
+	select {
+	case <-packetCh:
+		t.Fatal("unexpected stream message")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// The subscriber channel is closed once the subscription context is done
+	packetCancel()
+	_, ok := <-packetCh
+	assert.False(t, ok)
+	s.Stop()
+}
+
+func TestClient_PacketIO(t *testing.T) {
+	s := setup(t, getTLSServerConfig(t))
+
+	connManager := NewConnManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target1 := createTestTarget(t, targetID1, deviceID1, true)
+	err := connManager.Connect(ctx, target1)
+	assert.NoError(t, err)
+	conn, err := connManager.GetByTarget(ctx, targetID1)
+	assert.NoError(t, err)
+
+	ch := make(chan *PacketIn)
+	assert.NoError(t, conn.SubscribePacketIn(ctx, ch))
+	err = conn.SendPacketOut(ctx, &PacketOut{
+		Payload:  []byte("packet"),
+		Metadata: map[string][]byte{"egress_port": EncodeUint(3)},
+	})
+	assert.NoError(t, err)
+
+	// The test server loops the packet back with the packet-out metadata IDs
+	packet := <-ch
+	assert.Equal(t, []byte("packet"), packet.Payload)
+	assert.Equal(t, uint64(3), DecodeUint(packet.Metadata["ingress_port"]))
+
+	err = conn.SendPacketOut(ctx, &PacketOut{
+		Metadata: map[string][]byte{"unknown": EncodeUint(3)},
+	})
+	assert.True(t, errors.IsInvalid(err))
+	s.Stop()
+}
+
+func TestClient_Digests(t *testing.T) {
+	s := setup(t, getTLSServerConfig(t))
+
+	connManager := NewConnManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target1 := createTestTarget(t, targetID1, deviceID1, true)
+	err := connManager.Connect(ctx, target1)
+	assert.NoError(t, err)
+	conn, err := connManager.GetByTarget(ctx, targetID1)
+	assert.NoError(t, err)
+
+	// The test server sends digest lists on the last stream channel which sent an arbitration request
+	arbitrationCh := make(chan *p4api.StreamMessageResponse, 1)
+	assert.NoError(t, conn.Subscribe(ctx, arbitrationCh, ArbitrationMessage))
+	assert.NoError(t, conn.SendArbitrationRequest(deviceID1, 1, ""))
+	<-arbitrationCh
+
+	ch := make(chan *DigestList)
+	assert.NoError(t, conn.SubscribeDigests(ctx, ch))
+	err = conn.ConfigureDigest(ctx, 1, "unknown", DigestConfig{})
+	assert.True(t, errors.IsNotFound(err))
+
+	// The test server sends a digest list once the digest is enabled
+	err = conn.ConfigureDigest(ctx, 1, "mac_learn_digest", DigestConfig{MaxTimeout: time.Millisecond, MaxListSize: 10})
+	assert.NoError(t, err)
+
+	// Digest lists are not acknowledged before they are delivered
+	select {
+	case ack := <-testDigestAcks:
+		t.Fatalf("digest list %d acknowledged before delivery", ack.ListId)
+	case <-time.After(100 * time.Millisecond):
+	}
+	digestList := <-ch
+	assert.Equal(t, "mac_learn_digest", digestList.Name)
+	assert.Equal(t, []map[string][]byte{{"src_addr": {2, 0, 0, 0, 0, 1}, "ingress_port": {1}}}, digestList.Messages)
+
+	// and are acknowledged once delivered
+	ack := <-testDigestAcks
+	assert.Equal(t, uint32(testDigestID), ack.DigestId)
+	assert.Equal(t, uint64(1), ack.ListId)
+
+	assert.NoError(t, conn.RemoveDigest(ctx, 1, "mac_learn_digest"))
+	s.Stop()
+}
+
+// addTestTables adds a table supporting idle timeouts to a P4Info
+func addTestTables(p4Info *p4configapi.P4Info) *p4configapi.P4Info {
+	p4Info.Tables = []*p4configapi.Table{
+		{
+			Preamble:            &p4configapi.Preamble{Id: testIdleTableID, Name: "ingress.learned_hosts"},
+			IdleTimeoutBehavior: p4configapi.Table_NOTIFY_CONTROL,
+		},
+	}
+	return p4Info
+}
+
+func TestClient_IdleTimeouts(t *testing.T) {
+	s := setup(t, getTLSServerConfig(t))
+
+	connManager := NewConnManager()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	target1 := createTestTarget(t, targetID1, deviceID1, true)
+	err := connManager.Connect(ctx, target1)
+	assert.NoError(t, err)
+	conn, err := connManager.GetByTarget(ctx, targetID1)
+	assert.NoError(t, err)
+
+	// The test server sends idle timeout notifications on the last stream channel which sent an arbitration request
+	arbitrationCh := make(chan *p4api.StreamMessageResponse, 1)
+	assert.NoError(t, conn.Subscribe(ctx, arbitrationCh, ArbitrationMessage))
+	assert.NoError(t, conn.SendArbitrationRequest(deviceID1, 1, ""))
+	<-arbitrationCh
+
+	// Entries with priority 1 are deleted and entries with priority 2 are refreshed with a longer timeout,
+	// after the mastership term changed
+	electionID := uint64(1)
+	getElectionID := func() uint64 {
+		return atomic.LoadUint64(&electionID)
+	}
+	idleTimeouts := make(chan *IdleTimeout, 2)
+	err = conn.HandleIdleTimeouts(ctx, getElectionID, func(ctx context.Context, idleTimeout *IdleTimeout) IdleTimeoutAction {
+		idleTimeouts <- idleTimeout
+		if idleTimeout.Entry.Priority == 1 {
+			return IdleTimeoutDelete
+		}
+		atomic.StoreUint64(&electionID, 2)
+		idleTimeout.Entry.IdleTimeoutNs *= 2
+		return IdleTimeoutRefresh
+	}, "ingress.learned_hosts")
+	assert.NoError(t, err)
+
+	for _, priority := range []int32{1, 2} {
+		_, err = conn.Write(ctx, &p4api.WriteRequest{
+			DeviceId:   deviceID1,
+			ElectionId: &p4api.Uint128{Low: 1},
+			Updates: []*p4api.Update{
+				{
+					Type:   p4api.Update_INSERT,
+					Entity: &p4api.Entity{Entity: &p4api.Entity_TableEntry{TableEntry: newTestIdleTableEntry(priority, time.Second)}},
+				},
+			},
+		})
+		assert.NoError(t, err)
+		idleTimeout := <-idleTimeouts
+		assert.Equal(t, "ingress.learned_hosts", idleTimeout.Table)
+		assert.Equal(t, priority, idleTimeout.Entry.Priority)
+	}
+
+	write := <-testTableWrites
+	assert.Equal(t, uint64(1), write.ElectionId.Low)
+	update := write.Updates[0]
+	assert.Equal(t, p4api.Update_DELETE, update.Type)
+	assert.Equal(t, int32(1), update.GetEntity().GetTableEntry().Priority)
+	assert.Equal(t, int64(0), update.GetEntity().GetTableEntry().IdleTimeoutNs)
+
+	// Refreshed entries are read first since notifications only carry their key, then modified without
+	// the counter data and the time since last hit read from the target
+	read := <-testTableReads
+	assert.Equal(t, int32(2), read.Priority)
+	assert.Nil(t, read.Action)
+	write = <-testTableWrites
+	assert.Equal(t, uint64(2), write.ElectionId.Low)
+	update = write.Updates[0]
+	assert.Equal(t, p4api.Update_MODIFY, update.Type)
+	tableEntry := update.GetEntity().GetTableEntry()
+	assert.Equal(t, int32(2), tableEntry.Priority)
+	assert.Equal(t, (2 * time.Second).Nanoseconds(), tableEntry.IdleTimeoutNs)
+	assert.NotNil(t, tableEntry.Action)
+	assert.Nil(t, tableEntry.CounterData)
+	assert.Nil(t, tableEntry.TimeSinceLastHit)
+	s.Stop()
+}
+
 func TestClient_SetForwardingPipelineConfig(t *testing.T) {
 	s := setup(t, getTLSServerConfig(t))
 
@@ -57,6 +57,7 @@ func newDestination(target *topoapi.Object) (*Destination, error) {
 		Addrs:    []string{addr},
 		TargetID: string(target.ID),
 		Timeout:  timeout,
+		DeviceID: p4rtServerInfo.DeviceID,
 	}
 
 	if tlsOptions.Plain {
@@ -0,0 +1,329 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4rt
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DigestConfig configures how a target batches the messages of a digest into digest lists
+type DigestConfig struct {
+	// MaxTimeout is the maximum time a digest message is held by the target before its list is sent
+	MaxTimeout time.Duration
+	// MaxListSize is the maximum number of messages of a digest list; zero means no limit
+	MaxListSize int32
+	// AckTimeout is the time the target waits for a digest list to be acknowledged before sending it again
+	AckTimeout time.Duration
+}
+
+// DigestList is a list of digest messages sent by a target to the controller
+type DigestList struct {
+	// Name is the name of the digest in the P4Info of the target pipeline
+	Name      string
+	ListID    uint64
+	Timestamp time.Time
+	// Messages are the digest messages with their field values by member name; the value of digests
+	// which are not structs or headers is keyed by the digest name
+	Messages []map[string][]byte
+}
+
+// DigestClient p4runtime digest client
+type DigestClient interface {
+	// ConfigureDigest enables the digest with the given name of the target pipeline or updates its config
+	ConfigureDigest(ctx context.Context, electionID uint64, name string, config DigestConfig) error
+	// RemoveDigest disables the digest with the given name of the target pipeline
+	RemoveDigest(ctx context.Context, electionID uint64, name string) error
+	// SubscribeDigests delivers the digest lists sent by the target decoded with the P4Info of the target
+	// pipeline until the context is done. Digest lists are acknowledged once delivered, so that the target
+	// sends again the lists which could not be decoded or delivered.
+	SubscribeDigests(ctx context.Context, ch chan<- *DigestList) error
+}
+
+// digestType is the P4Info type information of a digest
+type digestType struct {
+	name string
+	// members are the names of the members of struct and header digests
+	members []string
+}
+
+// digestCodec decodes digest lists using the P4Info of a pipeline
+type digestCodec struct {
+	digests map[uint32]*digestType
+	ids     map[string]uint32
+}
+
+func newDigestCodec(p4Info *p4configapi.P4Info) *digestCodec {
+	codec := &digestCodec{
+		digests: make(map[uint32]*digestType),
+		ids:     make(map[string]uint32),
+	}
+	for _, digest := range p4Info.Digests {
+		dt := &digestType{
+			name: digest.Preamble.GetName(),
+		}
+		switch typeSpec := digest.GetTypeSpec().GetTypeSpec().(type) {
+		case *p4configapi.P4DataTypeSpec_Struct:
+			for _, member := range p4Info.GetTypeInfo().GetStructs()[typeSpec.Struct.GetName()].GetMembers() {
+				dt.members = append(dt.members, member.Name)
+			}
+		case *p4configapi.P4DataTypeSpec_Header:
+			for _, member := range p4Info.GetTypeInfo().GetHeaders()[typeSpec.Header.GetName()].GetMembers() {
+				dt.members = append(dt.members, member.Name)
+			}
+		}
+		codec.digests[digest.Preamble.GetId()] = dt
+		codec.ids[dt.name] = digest.Preamble.GetId()
+	}
+	return codec
+}
+
+// getID returns the ID of the digest with the given name
+func (c *digestCodec) getID(name string) (uint32, error) {
+	id, ok := c.ids[name]
+	if !ok {
+		return 0, errors.NewNotFound("unknown digest '%s'", name)
+	}
+	return id, nil
+}
+
+func (c *digestCodec) decode(digestList *p4api.DigestList) (*DigestList, error) {
+	dt, ok := c.digests[digestList.DigestId]
+	if !ok {
+		return nil, errors.NewNotFound("unknown digest ID %d", digestList.DigestId)
+	}
+	list := &DigestList{
+		Name:      dt.name,
+		ListID:    digestList.ListId,
+		Timestamp: time.Unix(0, digestList.Timestamp),
+		Messages:  make([]map[string][]byte, 0, len(digestList.Data)),
+	}
+	for _, data := range digestList.Data {
+		message, err := dt.decode(data)
+		if err != nil {
+			return nil, err
+		}
+		list.Messages = append(list.Messages, message)
+	}
+	return list, nil
+}
+
+func (t *digestType) decode(data *p4api.P4Data) (map[string][]byte, error) {
+	message := make(map[string][]byte)
+	switch value := data.Data.(type) {
+	case *p4api.P4Data_Struct:
+		if len(value.Struct.Members) != len(t.members) {
+			return nil, errors.NewInvalid("digest '%s' has %d members instead of %d", t.name, len(value.Struct.Members), len(t.members))
+		}
+		for i, member := range value.Struct.Members {
+			memberValue, err := decodeDigestMember(member)
+			if err != nil {
+				return nil, errors.NewInvalid("member '%s' of digest '%s': %v", t.members[i], t.name, err)
+			}
+			message[t.members[i]] = memberValue
+		}
+	case *p4api.P4Data_Header:
+		if len(value.Header.Bitstrings) != len(t.members) {
+			return nil, errors.NewInvalid("digest '%s' has %d members instead of %d", t.name, len(value.Header.Bitstrings), len(t.members))
+		}
+		for i, bitstring := range value.Header.Bitstrings {
+			message[t.members[i]] = bitstring
+		}
+	case *p4api.P4Data_Tuple:
+		for i, member := range value.Tuple.Members {
+			memberValue, err := decodeDigestMember(member)
+			if err != nil {
+				return nil, errors.NewInvalid("member %d of digest '%s': %v", i, t.name, err)
+			}
+			message[strconv.Itoa(i)] = memberValue
+		}
+	default:
+		if len(t.members) > 0 {
+			return nil, errors.NewInvalid("digest '%s' is not a struct or header", t.name)
+		}
+		memberValue, err := decodeDigestMember(data)
+		if err != nil {
+			return nil, errors.NewInvalid("digest '%s': %v", t.name, err)
+		}
+		message[t.name] = memberValue
+	}
+	return message, nil
+}
+
+// decodeDigestMember decodes the bitstring and boolean members of digests
+func decodeDigestMember(data *p4api.P4Data) ([]byte, error) {
+	switch value := data.Data.(type) {
+	case *p4api.P4Data_Bitstring:
+		return value.Bitstring, nil
+	case *p4api.P4Data_Bool:
+		if value.Bool {
+			return []byte{1}, nil
+		}
+		return []byte{0}, nil
+	case *p4api.P4Data_EnumValue:
+		return value.EnumValue, nil
+	}
+	return nil, errors.NewNotSupported("unsupported P4 data type %T", data.Data)
+}
+
+// ackDigestList acknowledges a digest list so that the target does not send it again
+func (s *streamClient) ackDigestList(digestList *p4api.DigestList) {
+	err := s.send(&p4api.StreamMessageRequest{
+		Update: &p4api.StreamMessageRequest_DigestAck{
+			DigestAck: &p4api.DigestListAck{
+				DigestId: digestList.DigestId,
+				ListId:   digestList.ListId,
+			},
+		},
+	})
+	if err != nil {
+		log.Warnw("Failed acknowledging digest list", "digest ID", digestList.DigestId, "list ID", digestList.ListId, "error", errors.FromGRPC(err))
+	}
+}
+
+// digestClient configures the digests of the target pipeline and decodes the digest lists of the stream channel
+type digestClient struct {
+	deviceID     uint64
+	writeClient  *writeClient
+	streamClient *streamClient
+	p4Infos      *p4InfoCache
+	codec        *digestCodec
+	// codecP4Info is the P4Info the digest codec was built from
+	codecP4Info *p4configapi.P4Info
+	mu          sync.Mutex
+}
+
+// getCodec returns the digest codec of the pipeline running on the target
+func (d *digestClient) getCodec(ctx context.Context) (*digestCodec, error) {
+	p4Info, err := d.p4Infos.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.codec == nil || d.codecP4Info != p4Info {
+		d.codec = newDigestCodec(p4Info)
+		d.codecP4Info = p4Info
+	}
+	return d.codec, nil
+}
+
+func (d *digestClient) writeDigestEntry(ctx context.Context, electionID uint64, updateType p4api.Update_Type, entry *p4api.DigestEntry) error {
+	_, err := d.writeClient.Write(ctx, &p4api.WriteRequest{
+		DeviceId: d.deviceID,
+		ElectionId: &p4api.Uint128{
+			Low:  electionID,
+			High: 0,
+		},
+		Updates: []*p4api.Update{
+			{
+				Type: updateType,
+				Entity: &p4api.Entity{
+					Entity: &p4api.Entity_DigestEntry{
+						DigestEntry: entry,
+					},
+				},
+			},
+		},
+	})
+	return err
+}
+
+func (d *digestClient) ConfigureDigest(ctx context.Context, electionID uint64, name string, config DigestConfig) error {
+	codec, err := d.getCodec(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := codec.getID(name)
+	if err != nil {
+		return err
+	}
+	entry := &p4api.DigestEntry{
+		DigestId: id,
+		Config: &p4api.DigestEntry_Config{
+			MaxTimeoutNs: config.MaxTimeout.Nanoseconds(),
+			MaxListSize:  config.MaxListSize,
+			AckTimeoutNs: config.AckTimeout.Nanoseconds(),
+		},
+	}
+	err = d.writeDigestEntry(ctx, electionID, p4api.Update_INSERT, entry)
+	if isAlreadyExists(err) {
+		err = d.writeDigestEntry(ctx, electionID, p4api.Update_MODIFY, entry)
+	}
+	return errors.FromGRPC(err)
+}
+
+func (d *digestClient) RemoveDigest(ctx context.Context, electionID uint64, name string) error {
+	codec, err := d.getCodec(ctx)
+	if err != nil {
+		return err
+	}
+	id, err := codec.getID(name)
+	if err != nil {
+		return err
+	}
+	err = d.writeDigestEntry(ctx, electionID, p4api.Update_DELETE, &p4api.DigestEntry{DigestId: id})
+	return errors.FromGRPC(err)
+}
+
+func (d *digestClient) SubscribeDigests(ctx context.Context, ch chan<- *DigestList) error {
+	streamCh := make(chan *p4api.StreamMessageResponse, streamBufferSize)
+	if err := d.streamClient.Subscribe(ctx, streamCh, DigestMessage); err != nil {
+		return err
+	}
+	go func() {
+		defer close(ch)
+		for response := range streamCh {
+			codec, err := d.getCodec(ctx)
+			if err != nil {
+				log.Warnw("Dropping digest list", "error", err)
+				continue
+			}
+			digestList, err := codec.decode(response.GetDigest())
+			if err != nil {
+				log.Warnw("Dropping digest list", "error", err)
+				continue
+			}
+			select {
+			case ch <- digestList:
+				d.streamClient.ackDigestList(response.GetDigest())
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// isAlreadyExists returns whether a P4Runtime write failed because the entity already exists, either
+// as the status of the request or as the status of its only update
+func isAlreadyExists(err error) bool {
+	if err == nil {
+		return false
+	}
+	st, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+	if st.Code() == codes.AlreadyExists {
+		return true
+	}
+	for _, detail := range st.Details() {
+		if p4Error, ok := detail.(*p4api.Error); ok && p4Error.CanonicalCode == int32(codes.AlreadyExists) {
+			return true
+		}
+	}
+	return false
+}
+
+var _ DigestClient = &digestClient{}
@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4rt
+
+import (
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+const testDigestID = 0x05000001
+
+// addTestDigests adds a struct digest and a bitstring digest to a P4Info
+func addTestDigests(p4Info *p4configapi.P4Info) *p4configapi.P4Info {
+	p4Info.Digests = []*p4configapi.Digest{
+		{
+			Preamble: &p4configapi.Preamble{Id: testDigestID, Name: "mac_learn_digest"},
+			TypeSpec: &p4configapi.P4DataTypeSpec{
+				TypeSpec: &p4configapi.P4DataTypeSpec_Struct{
+					Struct: &p4configapi.P4NamedType{Name: "mac_learn_digest_t"},
+				},
+			},
+		},
+		{
+			Preamble: &p4configapi.Preamble{Id: testDigestID + 1, Name: "port_digest"},
+			TypeSpec: &p4configapi.P4DataTypeSpec{
+				TypeSpec: &p4configapi.P4DataTypeSpec_Bitstring{
+					Bitstring: &p4configapi.P4BitstringLikeTypeSpec{},
+				},
+			},
+		},
+	}
+	p4Info.TypeInfo = &p4configapi.P4TypeInfo{
+		Structs: map[string]*p4configapi.P4StructTypeSpec{
+			"mac_learn_digest_t": {
+				Members: []*p4configapi.P4StructTypeSpec_Member{
+					{Name: "src_addr"},
+					{Name: "ingress_port"},
+				},
+			},
+		},
+	}
+	return p4Info
+}
+
+func newTestDigestData(srcAddr []byte, port []byte) *p4api.P4Data {
+	return &p4api.P4Data{
+		Data: &p4api.P4Data_Struct{
+			Struct: &p4api.P4StructLike{
+				Members: []*p4api.P4Data{
+					{Data: &p4api.P4Data_Bitstring{Bitstring: srcAddr}},
+					{Data: &p4api.P4Data_Bitstring{Bitstring: port}},
+				},
+			},
+		},
+	}
+}
+
+func TestDigestCodec(t *testing.T) {
+	codec := newDigestCodec(addTestDigests(&p4configapi.P4Info{}))
+
+	id, err := codec.getID("mac_learn_digest")
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(testDigestID), id)
+	_, err = codec.getID("unknown")
+	assert.True(t, errors.IsNotFound(err))
+
+	digestList, err := codec.decode(&p4api.DigestList{
+		DigestId:  testDigestID,
+		ListId:    7,
+		Timestamp: 1000,
+		Data: []*p4api.P4Data{
+			newTestDigestData([]byte{2, 0, 0, 0, 0, 1}, []byte{1}),
+			newTestDigestData([]byte{2, 0, 0, 0, 0, 2}, []byte{2}),
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "mac_learn_digest", digestList.Name)
+	assert.Equal(t, uint64(7), digestList.ListID)
+	assert.Equal(t, int64(1000), digestList.Timestamp.UnixNano())
+	assert.Equal(t, []map[string][]byte{
+		{"src_addr": {2, 0, 0, 0, 0, 1}, "ingress_port": {1}},
+		{"src_addr": {2, 0, 0, 0, 0, 2}, "ingress_port": {2}},
+	}, digestList.Messages)
+
+	// Digests which are not structs are keyed by the digest name
+	digestList, err = codec.decode(&p4api.DigestList{
+		DigestId: testDigestID + 1,
+		Data:     []*p4api.P4Data{{Data: &p4api.P4Data_Bitstring{Bitstring: []byte{3}}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []map[string][]byte{{"port_digest": {3}}}, digestList.Messages)
+
+	_, err = codec.decode(&p4api.DigestList{DigestId: 1})
+	assert.True(t, errors.IsNotFound(err))
+	_, err = codec.decode(&p4api.DigestList{
+		DigestId: testDigestID,
+		Data:     []*p4api.P4Data{{Data: &p4api.P4Data_Bitstring{Bitstring: []byte{3}}}},
+	})
+	assert.True(t, errors.IsInvalid(err))
+}
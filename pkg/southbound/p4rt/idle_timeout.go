@@ -0,0 +1,205 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4rt
+
+import (
+	"context"
+	"time"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// IdleTimeout is a table entry which was not hit for the duration of its idle timeout
+type IdleTimeout struct {
+	// Table is the name of the table of the entry in the P4Info of the target pipeline
+	Table string
+	// Entry holds the key, metadata and idle timeout of the entry
+	Entry     *p4api.TableEntry
+	Timestamp time.Time
+}
+
+// IdleTimeoutAction is the action taken for an idle table entry
+type IdleTimeoutAction int
+
+const (
+	// IdleTimeoutIgnore keeps the entry as is; the target notifies again once the entry is idle for another timeout
+	IdleTimeoutIgnore IdleTimeoutAction = iota
+	// IdleTimeoutDelete deletes the entry
+	IdleTimeoutDelete
+	// IdleTimeoutRefresh rewrites the entry with the idle timeout of the notified entry, which the handler may change
+	IdleTimeoutRefresh
+)
+
+// IdleTimeoutHandler decides what to do with an idle table entry
+type IdleTimeoutHandler func(ctx context.Context, idleTimeout *IdleTimeout) IdleTimeoutAction
+
+// IdleTimeoutClient p4runtime idle timeout client
+type IdleTimeoutClient interface {
+	// SubscribeIdleTimeouts delivers the idle entries of the tables with the given names, or of all tables
+	// if no name is given, until the context is done
+	SubscribeIdleTimeouts(ctx context.Context, ch chan<- *IdleTimeout, tables ...string) error
+	// HandleIdleTimeouts calls the handler for each idle entry of the tables with the given names, or of all
+	// tables if no name is given, and deletes or refreshes the entries as requested until the context is done.
+	// The entries are written with the election ID returned by electionID when each idle entry is handled, so
+	// that writes keep succeeding once the mastership term changes.
+	HandleIdleTimeouts(ctx context.Context, electionID func() uint64, handler IdleTimeoutHandler, tables ...string) error
+}
+
+// idleTimeoutClient decodes the idle timeout notifications of the stream channel with the P4Info of the target pipeline
+type idleTimeoutClient struct {
+	deviceID     uint64
+	writeClient  *writeClient
+	readClient   *readClient
+	streamClient *streamClient
+	p4Infos      *p4InfoCache
+}
+
+// getTables returns the names of the tables of the target pipeline by ID
+func (c *idleTimeoutClient) getTables(ctx context.Context) (map[uint32]string, error) {
+	p4Info, err := c.p4Infos.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tables := make(map[uint32]string)
+	for _, table := range p4Info.Tables {
+		tables[table.Preamble.GetId()] = table.Preamble.GetName()
+	}
+	return tables, nil
+}
+
+func (c *idleTimeoutClient) SubscribeIdleTimeouts(ctx context.Context, ch chan<- *IdleTimeout, tables ...string) error {
+	subscribed := make(map[string]bool)
+	for _, table := range tables {
+		subscribed[table] = true
+	}
+	streamCh := make(chan *p4api.StreamMessageResponse, streamBufferSize)
+	if err := c.streamClient.Subscribe(ctx, streamCh, IdleTimeoutMessage); err != nil {
+		return err
+	}
+	go func() {
+		defer close(ch)
+		for response := range streamCh {
+			tableNames, err := c.getTables(ctx)
+			if err != nil {
+				log.Warnw("Dropping idle timeout notification", "error", err)
+				continue
+			}
+			notification := response.GetIdleTimeoutNotification()
+			for _, entry := range notification.TableEntry {
+				name, ok := tableNames[entry.TableId]
+				if !ok {
+					log.Debugw("Ignoring idle entry of unknown table", "table ID", entry.TableId)
+					continue
+				}
+				if len(subscribed) > 0 && !subscribed[name] {
+					continue
+				}
+				idleTimeout := &IdleTimeout{
+					Table:     name,
+					Entry:     entry,
+					Timestamp: time.Unix(0, notification.Timestamp),
+				}
+				select {
+				case ch <- idleTimeout:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *idleTimeoutClient) HandleIdleTimeouts(ctx context.Context, electionID func() uint64, handler IdleTimeoutHandler, tables ...string) error {
+	ch := make(chan *IdleTimeout, streamBufferSize)
+	if err := c.SubscribeIdleTimeouts(ctx, ch, tables...); err != nil {
+		return err
+	}
+	go func() {
+		for idleTimeout := range ch {
+			var err error
+			switch handler(ctx, idleTimeout) {
+			case IdleTimeoutDelete:
+				err = c.deleteEntry(ctx, electionID(), idleTimeout.Entry)
+			case IdleTimeoutRefresh:
+				err = c.refreshEntry(ctx, electionID(), idleTimeout.Entry)
+			}
+			if err != nil {
+				log.Warnw("Failed handling idle entry", "table", idleTimeout.Table, "error", err)
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *idleTimeoutClient) writeTableEntry(ctx context.Context, electionID uint64, updateType p4api.Update_Type, entry *p4api.TableEntry) error {
+	_, err := c.writeClient.Write(ctx, &p4api.WriteRequest{
+		DeviceId: c.deviceID,
+		ElectionId: &p4api.Uint128{
+			Low:  electionID,
+			High: 0,
+		},
+		Updates: []*p4api.Update{
+			{
+				Type: updateType,
+				Entity: &p4api.Entity{
+					Entity: &p4api.Entity_TableEntry{
+						TableEntry: entry,
+					},
+				},
+			},
+		},
+	})
+	return errors.FromGRPC(err)
+}
+
+// deleteEntry deletes an idle entry by its key
+func (c *idleTimeoutClient) deleteEntry(ctx context.Context, electionID uint64, entry *p4api.TableEntry) error {
+	err := c.writeTableEntry(ctx, electionID, p4api.Update_DELETE, &p4api.TableEntry{
+		TableId:  entry.TableId,
+		Match:    entry.Match,
+		Priority: entry.Priority,
+	})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
+
+// refreshEntry rewrites an idle entry with its idle timeout; idle notifications only carry the key of
+// the entry, so the entry is read from the target first
+func (c *idleTimeoutClient) refreshEntry(ctx context.Context, electionID uint64, entry *p4api.TableEntry) error {
+	entities, err := c.readClient.ReadEntities(ctx, &p4api.ReadRequest{
+		DeviceId: c.deviceID,
+		Entities: []*p4api.Entity{
+			{
+				Entity: &p4api.Entity_TableEntry{
+					TableEntry: &p4api.TableEntry{
+						TableId:  entry.TableId,
+						Match:    entry.Match,
+						Priority: entry.Priority,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return errors.FromGRPC(err)
+	}
+	if len(entities) != 1 || entities[0].GetTableEntry() == nil {
+		// The entry was deleted in the meantime
+		return nil
+	}
+	current := proto.Clone(entities[0].GetTableEntry()).(*p4api.TableEntry)
+	current.IdleTimeoutNs = entry.IdleTimeoutNs
+	current.TimeSinceLastHit = nil
+	current.CounterData = nil
+	current.MeterCounterData = nil
+	return c.writeTableEntry(ctx, electionID, p4api.Update_MODIFY, current)
+}
+
+var _ IdleTimeoutClient = &idleTimeoutClient{}
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4rt
+
+import (
+	"context"
+	"sync"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+)
+
+const (
+	// packetInHeader is the name of the controller packet metadata of the packets sent to the controller
+	packetInHeader = "packet_in"
+	// packetOutHeader is the name of the controller packet metadata of the packets sent by the controller
+	packetOutHeader = "packet_out"
+)
+
+// PacketIn is a packet sent by a target to the controller
+type PacketIn struct {
+	Payload []byte
+	// Metadata are the values of the controller packet metadata by name
+	Metadata map[string][]byte
+}
+
+// PacketOut is a packet injected by the controller into a target
+type PacketOut struct {
+	Payload []byte
+	// Metadata are the values of the controller packet metadata by name
+	Metadata map[string][]byte
+}
+
+// PacketClient p4runtime packet I/O client
+type PacketClient interface {
+	// SendPacketOut sends a packet out encoding its metadata with the P4Info of the target pipeline
+	SendPacketOut(ctx context.Context, packet *PacketOut) error
+	// SubscribePacketIn delivers the packets sent by the target to the controller with their metadata
+	// decoded with the P4Info of the target pipeline until the context is done
+	SubscribePacketIn(ctx context.Context, ch chan<- *PacketIn) error
+}
+
+// packetCodec encodes and decodes controller packet metadata using the P4Info of a pipeline
+type packetCodec struct {
+	// packetIn are the names of the packet-in metadata by ID
+	packetIn map[uint32]string
+	// packetOut are the packet-out metadata by name
+	packetOut map[string]*p4configapi.ControllerPacketMetadata_Metadata
+}
+
+func newPacketCodec(p4Info *p4configapi.P4Info) *packetCodec {
+	codec := &packetCodec{
+		packetIn:  make(map[uint32]string),
+		packetOut: make(map[string]*p4configapi.ControllerPacketMetadata_Metadata),
+	}
+	for _, header := range p4Info.ControllerPacketMetadata {
+		switch header.Preamble.GetName() {
+		case packetInHeader:
+			for _, metadata := range header.Metadata {
+				codec.packetIn[metadata.Id] = metadata.Name
+			}
+		case packetOutHeader:
+			for _, metadata := range header.Metadata {
+				codec.packetOut[metadata.Name] = metadata
+			}
+		}
+	}
+	return codec
+}
+
+func (c *packetCodec) encode(packet *PacketOut) (*p4api.PacketOut, error) {
+	packetOut := &p4api.PacketOut{
+		Payload: packet.Payload,
+	}
+	for name, value := range packet.Metadata {
+		metadata, ok := c.packetOut[name]
+		if !ok {
+			return nil, errors.NewInvalid("unknown packet-out metadata '%s'", name)
+		}
+		value = CanonicalBytes(value)
+		if BitLen(value) > int(metadata.Bitwidth) {
+			return nil, errors.NewInvalid("value of packet-out metadata '%s' exceeds %d bits", name, metadata.Bitwidth)
+		}
+		packetOut.Metadata = append(packetOut.Metadata, &p4api.PacketMetadata{
+			MetadataId: metadata.Id,
+			Value:      value,
+		})
+	}
+	return packetOut, nil
+}
+
+func (c *packetCodec) decode(packetIn *p4api.PacketIn) *PacketIn {
+	packet := &PacketIn{
+		Payload:  packetIn.Payload,
+		Metadata: make(map[string][]byte),
+	}
+	for _, metadata := range packetIn.Metadata {
+		name, ok := c.packetIn[metadata.MetadataId]
+		if !ok {
+			log.Debugw("Ignoring unknown packet-in metadata", "metadata ID", metadata.MetadataId)
+			continue
+		}
+		packet.Metadata[name] = metadata.Value
+	}
+	return packet
+}
+
+// EncodeUint encodes an unsigned integer in the canonical binary string format of P4Runtime
+func EncodeUint(value uint64) []byte {
+	bytes := make([]byte, 8)
+	for i := 7; i >= 0; i-- {
+		bytes[i] = byte(value)
+		value >>= 8
+	}
+	return CanonicalBytes(bytes)
+}
+
+// DecodeUint decodes an unsigned integer from a P4Runtime binary string of at most 8 significant bytes
+func DecodeUint(bytes []byte) uint64 {
+	var value uint64
+	for _, b := range CanonicalBytes(bytes) {
+		value = value<<8 | uint64(b)
+	}
+	return value
+}
+
+// CanonicalBytes strips the leading zero bytes of a binary string, keeping at least one byte, as
+// required for the binary strings sent to P4Runtime targets
+func CanonicalBytes(bytes []byte) []byte {
+	for len(bytes) > 1 && bytes[0] == 0 {
+		bytes = bytes[1:]
+	}
+	return bytes
+}
+
+// BitLen returns the number of significant bits of a binary string
+func BitLen(bytes []byte) int {
+	if len(bytes) == 0 {
+		return 0
+	}
+	bits := (len(bytes) - 1) * 8
+	for b := bytes[0]; b != 0; b >>= 1 {
+		bits++
+	}
+	return bits
+}
+
+// packetClient encodes and decodes the packets of the stream channel with the P4Info of the target pipeline
+type packetClient struct {
+	streamClient *streamClient
+	p4Infos      *p4InfoCache
+	codec        *packetCodec
+	// codecP4Info is the P4Info the packet codec was built from
+	codecP4Info *p4configapi.P4Info
+	mu          sync.Mutex
+}
+
+// getCodec returns the packet codec of the pipeline running on the target
+func (p *packetClient) getCodec(ctx context.Context) (*packetCodec, error) {
+	p4Info, err := p.p4Infos.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.codec == nil || p.codecP4Info != p4Info {
+		p.codec = newPacketCodec(p4Info)
+		p.codecP4Info = p4Info
+	}
+	return p.codec, nil
+}
+
+func (p *packetClient) SendPacketOut(ctx context.Context, packet *PacketOut) error {
+	codec, err := p.getCodec(ctx)
+	if err != nil {
+		return err
+	}
+	packetOut, err := codec.encode(packet)
+	if err != nil {
+		return err
+	}
+	return errors.FromGRPC(p.streamClient.send(&p4api.StreamMessageRequest{
+		Update: &p4api.StreamMessageRequest_Packet{
+			Packet: packetOut,
+		},
+	}))
+}
+
+func (p *packetClient) SubscribePacketIn(ctx context.Context, ch chan<- *PacketIn) error {
+	streamCh := make(chan *p4api.StreamMessageResponse, streamBufferSize)
+	if err := p.streamClient.Subscribe(ctx, streamCh, PacketInMessage); err != nil {
+		return err
+	}
+	go func() {
+		defer close(ch)
+		for response := range streamCh {
+			codec, err := p.getCodec(ctx)
+			if err != nil {
+				log.Warnw("Dropping packet-in", "error", err)
+				continue
+			}
+			select {
+			case ch <- codec.decode(response.GetPacket()):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+var _ PacketClient = &packetClient{}
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4rt
+
+import (
+	"testing"
+
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestPacketP4Info() *p4configapi.P4Info {
+	return &p4configapi.P4Info{
+		ControllerPacketMetadata: []*p4configapi.ControllerPacketMetadata{
+			{
+				Preamble: &p4configapi.Preamble{Id: 0x04000001, Name: packetInHeader},
+				Metadata: []*p4configapi.ControllerPacketMetadata_Metadata{
+					{Id: 1, Name: "ingress_port", Bitwidth: 9},
+				},
+			},
+			{
+				Preamble: &p4configapi.Preamble{Id: 0x04000002, Name: packetOutHeader},
+				Metadata: []*p4configapi.ControllerPacketMetadata_Metadata{
+					{Id: 1, Name: "egress_port", Bitwidth: 9},
+				},
+			},
+		},
+	}
+}
+
+func TestPacketCodec(t *testing.T) {
+	codec := newPacketCodec(newTestPacketP4Info())
+
+	packetOut, err := codec.encode(&PacketOut{
+		Payload:  []byte("packet"),
+		Metadata: map[string][]byte{"egress_port": {0, 0, 1, 255}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("packet"), packetOut.Payload)
+	assert.Equal(t, []*p4api.PacketMetadata{{MetadataId: 1, Value: []byte{1, 255}}}, packetOut.Metadata)
+
+	_, err = codec.encode(&PacketOut{Metadata: map[string][]byte{"egress_port": {2, 0}}})
+	assert.True(t, errors.IsInvalid(err))
+	_, err = codec.encode(&PacketOut{Metadata: map[string][]byte{"ingress_port": {1}}})
+	assert.True(t, errors.IsInvalid(err))
+
+	packetIn := codec.decode(&p4api.PacketIn{
+		Payload: []byte("packet"),
+		Metadata: []*p4api.PacketMetadata{
+			{MetadataId: 1, Value: []byte{1}},
+			{MetadataId: 2, Value: []byte{2}},
+		},
+	})
+	assert.Equal(t, []byte("packet"), packetIn.Payload)
+	assert.Equal(t, map[string][]byte{"ingress_port": {1}}, packetIn.Metadata)
+}
+
+func TestEncodeUint(t *testing.T) {
+	assert.Equal(t, []byte{0}, EncodeUint(0))
+	assert.Equal(t, []byte{1, 0}, EncodeUint(256))
+	assert.Equal(t, uint64(256), DecodeUint([]byte{0, 1, 0}))
+	assert.Equal(t, uint64(0), DecodeUint(nil))
+}
@@ -6,8 +6,11 @@ package p4rt
 
 import (
 	"context"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
 	p4api "github.com/p4lang/p4runtime/go/p4/v1"
 	"google.golang.org/grpc"
+	"sync"
 )
 
 // PipelineConfigClient :
@@ -31,3 +34,56 @@ func (p *pipelineConfigClient) GetForwardingPipelineConfig(ctx context.Context,
 }
 
 var _ PipelineConfigClient = &pipelineConfigClient{}
+
+// p4InfoCache caches the P4Info of the pipeline running on a target
+type p4InfoCache struct {
+	deviceID             uint64
+	pipelineConfigClient *pipelineConfigClient
+	p4Info               *p4configapi.P4Info
+	// cookie is the cookie of the pipeline the P4Info was retrieved with
+	cookie *p4api.ForwardingPipelineConfig_Cookie
+	mu     sync.Mutex
+}
+
+// get returns the P4Info of the pipeline running on the target, retrieving it if needed
+func (c *p4InfoCache) get(ctx context.Context) (*p4configapi.P4Info, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.p4Info != nil {
+		return c.p4Info, nil
+	}
+	response, err := c.pipelineConfigClient.GetForwardingPipelineConfig(ctx, &p4api.GetForwardingPipelineConfigRequest{
+		DeviceId:     c.deviceID,
+		ResponseType: p4api.GetForwardingPipelineConfigRequest_P4INFO_AND_COOKIE,
+	})
+	if err != nil {
+		return nil, errors.FromGRPC(err)
+	}
+	if response.GetConfig().GetP4Info() == nil {
+		return nil, errors.NewUnavailable("no pipeline is running on the target")
+	}
+	c.p4Info = response.Config.P4Info
+	c.cookie = response.Config.Cookie
+	return c.p4Info, nil
+}
+
+// checkCookie discards the cached P4Info if the target reports a pipeline cookie different from the one of
+// the cached P4Info, e.g. because another controller changed the pipeline
+func (c *p4InfoCache) checkCookie(cookie *p4api.ForwardingPipelineConfig_Cookie) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.p4Info == nil || cookie == nil || c.cookie.GetCookie() == cookie.Cookie {
+		return
+	}
+	log.Infow("Pipeline cookie of target changed, discarding cached P4Info", "device ID", c.deviceID)
+	c.p4Info = nil
+	c.cookie = nil
+}
+
+// reset discards the cached P4Info once the pipeline of the target is changed or may have been changed
+func (c *p4InfoCache) reset() {
+	c.mu.Lock()
+	c.p4Info = nil
+	c.cookie = nil
+	c.mu.Unlock()
+}
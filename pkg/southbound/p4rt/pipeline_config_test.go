@@ -0,0 +1,110 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package p4rt
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	p4configapi "github.com/p4lang/p4runtime/go/p4/config/v1"
+	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type testPipelineClient struct {
+	p4api.P4RuntimeClient
+	cookie uint64
+	gets   int
+}
+
+func (c *testPipelineClient) GetForwardingPipelineConfig(ctx context.Context, request *p4api.GetForwardingPipelineConfigRequest, opts ...grpc.CallOption) (*p4api.GetForwardingPipelineConfigResponse, error) {
+	c.gets++
+	return &p4api.GetForwardingPipelineConfigResponse{
+		Config: &p4api.ForwardingPipelineConfig{
+			P4Info: &p4configapi.P4Info{},
+			Cookie: &p4api.ForwardingPipelineConfig_Cookie{Cookie: c.cookie},
+		},
+	}, nil
+}
+
+// testStreamChannel is a stream channel receiving the responses of a channel until it is closed
+type testStreamChannel struct {
+	p4api.P4Runtime_StreamChannelClient
+	responses chan *p4api.StreamMessageResponse
+}
+
+func (s *testStreamChannel) Recv() (*p4api.StreamMessageResponse, error) {
+	response, ok := <-s.responses
+	if !ok {
+		return nil, io.EOF
+	}
+	return response, nil
+}
+
+func TestP4InfoCacheCookie(t *testing.T) {
+	ctx := context.Background()
+	p4runtimeClient := &testPipelineClient{cookie: 1}
+	p4Infos := &p4InfoCache{
+		deviceID:             1,
+		pipelineConfigClient: &pipelineConfigClient{p4runtimeClient: p4runtimeClient},
+	}
+	_, err := p4Infos.get(ctx)
+	assert.NoError(t, err)
+	_, err = p4Infos.get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p4runtimeClient.gets)
+
+	// The P4Info is kept while the target reports the same pipeline cookie
+	p4Infos.checkCookie(&p4api.ForwardingPipelineConfig_Cookie{Cookie: 1})
+	_, err = p4Infos.get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p4runtimeClient.gets)
+
+	// and retrieved again once the pipeline is changed behind the client
+	p4runtimeClient.cookie = 2
+	p4Infos.checkCookie(&p4api.ForwardingPipelineConfig_Cookie{Cookie: 2})
+	_, err = p4Infos.get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, p4runtimeClient.gets)
+}
+
+func TestP4InfoCacheMastership(t *testing.T) {
+	ctx := context.Background()
+	p4runtimeClient := &testPipelineClient{cookie: 1}
+	p4Infos := &p4InfoCache{
+		deviceID:             1,
+		pipelineConfigClient: &pipelineConfigClient{p4runtimeClient: p4runtimeClient},
+	}
+	streamClient := &streamClient{
+		p4Infos:     p4Infos,
+		subscribers: make(map[uuid.UUID]*streamSubscriber),
+	}
+	streamChannel := &testStreamChannel{responses: make(chan *p4api.StreamMessageResponse)}
+	defer close(streamChannel.responses)
+	streamClient.setStreamChannel(streamChannel)
+
+	_, err := p4Infos.get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, p4runtimeClient.gets)
+
+	// A master arbitration update discards the cached P4Info
+	streamChannel.responses <- &p4api.StreamMessageResponse{
+		Update: &p4api.StreamMessageResponse_Arbitration{
+			Arbitration: &p4api.MasterArbitrationUpdate{DeviceId: 1},
+		},
+	}
+	assert.Eventually(t, func() bool {
+		p4Infos.mu.Lock()
+		defer p4Infos.mu.Unlock()
+		return p4Infos.p4Info == nil
+	}, time.Second, 10*time.Millisecond)
+	_, err = p4Infos.get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, p4runtimeClient.gets)
+}
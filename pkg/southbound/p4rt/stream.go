@@ -5,37 +5,201 @@
 package p4rt
 
 import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/google/uuid"
 	"github.com/onosproject/onos-lib-go/pkg/errors"
 	p4api "github.com/p4lang/p4runtime/go/p4/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// streamBufferSize is the number of stream messages buffered for each subscriber; messages are
+// dropped when a subscriber falls further behind
+const streamBufferSize = 1024
+
+// StreamMessageType is the type of a message received on a P4Runtime stream channel
+type StreamMessageType int
+
+const (
+	// ArbitrationMessage is a master arbitration update
+	ArbitrationMessage StreamMessageType = iota
+	// PacketInMessage is a packet sent by the target to the controller
+	PacketInMessage
+	// DigestMessage is a digest list
+	DigestMessage
+	// IdleTimeoutMessage is an idle timeout notification
+	IdleTimeoutMessage
+	// StreamErrorMessage is an error reported by the target for a stream message request
+	StreamErrorMessage
+	// OtherMessage is an architecture-specific message
+	OtherMessage
 )
 
+func (t StreamMessageType) String() string {
+	switch t {
+	case ArbitrationMessage:
+		return "ARBITRATION"
+	case PacketInMessage:
+		return "PACKET_IN"
+	case DigestMessage:
+		return "DIGEST"
+	case IdleTimeoutMessage:
+		return "IDLE_TIMEOUT"
+	case StreamErrorMessage:
+		return "STREAM_ERROR"
+	}
+	return "OTHER"
+}
+
+// GetStreamMessageType returns the type of a stream message response
+func GetStreamMessageType(response *p4api.StreamMessageResponse) StreamMessageType {
+	switch response.Update.(type) {
+	case *p4api.StreamMessageResponse_Arbitration:
+		return ArbitrationMessage
+	case *p4api.StreamMessageResponse_Packet:
+		return PacketInMessage
+	case *p4api.StreamMessageResponse_Digest:
+		return DigestMessage
+	case *p4api.StreamMessageResponse_IdleTimeoutNotification:
+		return IdleTimeoutMessage
+	case *p4api.StreamMessageResponse_Error:
+		return StreamErrorMessage
+	}
+	return OtherMessage
+}
+
 // StreamClient p4runtime master stream client
 type StreamClient interface {
 	SendArbitrationRequest(deviceID uint64, electionID uint64, role string) error
-	RecvArbitrationResponse() (*p4api.StreamMessageResponse_Arbitration, error)
+	// Subscribe delivers the messages of the given types received on the stream channel of the target, or
+	// the messages of all types if no type is given, until the context is done. The subscription is kept
+	// when the stream channel is reopened after a reconnection.
+	Subscribe(ctx context.Context, ch chan<- *p4api.StreamMessageResponse, types ...StreamMessageType) error
+}
+
+type streamSubscriber struct {
+	ctx   context.Context
+	ch    chan *p4api.StreamMessageResponse
+	types map[StreamMessageType]bool
 }
 
 type streamClient struct {
 	p4runtimeClient p4api.P4RuntimeClient
 	streamChannel   p4api.P4Runtime_StreamChannelClient
+	// p4Infos is the P4Info cache of the target, discarded when the mastership of the target changes
+	p4Infos *p4InfoCache
+	mu      sync.RWMutex
+	// sendMu serializes the requests sent on the stream channel
+	sendMu        sync.Mutex
+	subscribers   map[uuid.UUID]*streamSubscriber
+	subscribersMu sync.RWMutex
 }
 
-func (s *streamClient) RecvArbitrationResponse() (*p4api.StreamMessageResponse_Arbitration, error) {
-	in, err := s.streamChannel.Recv()
-	if err != nil {
-		return nil, err
-	}
+// setStreamChannel replaces the stream channel of the target and starts receiving its messages
+func (s *streamClient) setStreamChannel(streamChannel p4api.P4Runtime_StreamChannelClient) {
+	s.mu.Lock()
+	s.streamChannel = streamChannel
+	s.mu.Unlock()
+	// The pipeline may have been changed while the stream channel was closed
+	s.p4Infos.reset()
+	go s.recv(streamChannel)
+}
 
-	switch v := in.Update.(type) {
-	case *p4api.StreamMessageResponse_Arbitration:
-		log.Infow("Received arbitration response", "response", v)
+// recv dispatches the messages received on a stream channel to the subscribers until the stream is closed
+func (s *streamClient) recv(streamChannel p4api.P4Runtime_StreamChannelClient) {
+	for {
+		response, err := streamChannel.Recv()
 		if err != nil {
-			return nil, err
+			if err == io.EOF || status.Code(err) == codes.Canceled {
+				log.Infow("P4RT stream channel closed")
+			} else {
+				log.Warnw("P4RT stream channel failed", "error", errors.FromGRPC(err))
+			}
+			return
 		}
-		return v, nil
+		// A new master may change the pipeline of the target
+		if response.GetArbitration() != nil {
+			s.p4Infos.reset()
+		}
+		s.dispatch(response)
+	}
+}
+
+func (s *streamClient) dispatch(response *p4api.StreamMessageResponse) {
+	messageType := GetStreamMessageType(response)
+	log.Debugw("Received stream message", "type", messageType)
+	s.subscribersMu.RLock()
+	defer s.subscribersMu.RUnlock()
+	for _, subscriber := range s.subscribers {
+		if len(subscriber.types) > 0 && !subscriber.types[messageType] {
+			continue
+		}
+		// Master arbitration updates are never dropped: a subscriber missing one may never learn the mastership
+		// of the target, so they wait for a slow subscriber until it is unsubscribed
+		if messageType == ArbitrationMessage {
+			select {
+			case subscriber.ch <- response:
+			case <-subscriber.ctx.Done():
+			}
+			continue
+		}
+		select {
+		case subscriber.ch <- response:
+		default:
+			log.Warnw("Dropping stream message for slow subscriber", "type", messageType)
+		}
+	}
+}
+
+// Subscribe subscribes to the messages received on the stream channel
+func (s *streamClient) Subscribe(ctx context.Context, ch chan<- *p4api.StreamMessageResponse, types ...StreamMessageType) error {
+	subscriber := &streamSubscriber{
+		ctx:   ctx,
+		ch:    make(chan *p4api.StreamMessageResponse, streamBufferSize),
+		types: make(map[StreamMessageType]bool),
 	}
-	return nil, errors.NewNotSupported("not an arbitration response message")
+	for _, messageType := range types {
+		subscriber.types[messageType] = true
+	}
+	id := uuid.New()
+	s.subscribersMu.Lock()
+	s.subscribers[id] = subscriber
+	s.subscribersMu.Unlock()
+
+	go func() {
+		defer close(ch)
+		for response := range subscriber.ch {
+			select {
+			case ch <- response:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		s.subscribersMu.Lock()
+		delete(s.subscribers, id)
+		s.subscribersMu.Unlock()
+		close(subscriber.ch)
+	}()
+	return nil
+}
 
+func (s *streamClient) send(request *p4api.StreamMessageRequest) error {
+	s.mu.RLock()
+	streamChannel := s.streamChannel
+	s.mu.RUnlock()
+	if streamChannel == nil {
+		return errors.NewUnavailable("P4RT stream channel is not open")
+	}
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return streamChannel.Send(request)
 }
 
 func (s *streamClient) SendArbitrationRequest(deviceID uint64, electionID uint64, role string) error {
@@ -52,8 +216,7 @@ func (s *streamClient) SendArbitrationRequest(deviceID uint64, electionID uint64
 		}},
 	}
 	log.Infow("Sending master arbitration request", "request", request)
-	err := s.streamChannel.Send(request)
-	return err
+	return s.send(request)
 }
 
 var _ StreamClient = &streamClient{}
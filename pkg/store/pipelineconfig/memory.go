@@ -0,0 +1,416 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	"github.com/gogo/protobuf/proto"
+	"github.com/google/uuid"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"sync"
+	"time"
+)
+
+// NewMemoryStore returns a new in-memory Store for single-node deployments and tests
+func NewMemoryStore(opts ...Option) Store {
+	options := storeOptions{
+		historySize: DefaultHistorySize,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+	store := &memoryStore{
+		pipelineConfigs: make(map[p4rtapi.PipelineConfigID]*memoryEntry),
+		history:         make(map[string][]byte),
+		statusDetails:   make(map[p4rtapi.PipelineConfigID][]byte),
+		plugins:         make(map[p4rtapi.PipelineConfigID]PluginDetails),
+		historySize:     options.historySize,
+		watchers:        make(map[uuid.UUID]chan<- p4rtapi.ConfigurationEvent),
+		eventCh:         make(chan p4rtapi.ConfigurationEvent, 1000),
+	}
+	go store.processEvents()
+	return store
+}
+
+// memoryEntry is an encoded pipelineconfig with the version used as an optimistic lock
+type memoryEntry struct {
+	version uint64
+	value   []byte
+}
+
+type memoryStore struct {
+	pipelineConfigs map[p4rtapi.PipelineConfigID]*memoryEntry
+	history         map[string][]byte
+	statusDetails   map[p4rtapi.PipelineConfigID][]byte
+	plugins         map[p4rtapi.PipelineConfigID]PluginDetails
+	historySize     int
+	// version is the last version assigned to an entry; like Atomix revisions it increases on every write
+	version    uint64
+	mu         sync.RWMutex
+	watchers   map[uuid.UUID]chan<- p4rtapi.ConfigurationEvent
+	watchersMu sync.RWMutex
+	eventCh    chan p4rtapi.ConfigurationEvent
+}
+
+func (s *memoryStore) processEvents() {
+	for event := range s.eventCh {
+		s.watchersMu.RLock()
+		for _, watcher := range s.watchers {
+			watcher <- event
+		}
+		s.watchersMu.RUnlock()
+	}
+}
+
+func (s *memoryStore) Get(ctx context.Context, id p4rtapi.PipelineConfigID) (*p4rtapi.PipelineConfig, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.pipelineConfigs[id]
+	if !ok {
+		return nil, errors.NewNotFound("pipelineconfig %s not found", id)
+	}
+	pipelineConfig := &p4rtapi.PipelineConfig{}
+	if err := decodeMemoryEntry(id, entry, pipelineConfig); err != nil {
+		return nil, errors.NewInvalid("pipelineconfig decoding failed: %v", err)
+	}
+	return pipelineConfig, nil
+}
+
+func (s *memoryStore) Create(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig, opts ...CreateOption) error {
+	var options createOptions
+	for _, opt := range opts {
+		opt.applyCreate(&options)
+	}
+
+	if pipelineConfig.ID == "" {
+		return errors.NewInvalid("no pipeline pipelineconfig ID specified")
+	}
+	if pipelineConfig.TargetID == "" {
+		return errors.NewInvalid("no target ID specified")
+	}
+	if pipelineConfig.Revision != 0 {
+		return errors.NewInvalid("cannot create pipeline pipelineconfig with revision")
+	}
+	if pipelineConfig.Version != 0 {
+		return errors.NewInvalid("cannot create pipeline pipelineconfig with version")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pipelineConfigs[pipelineConfig.ID]; ok {
+		return errors.NewAlreadyExists("pipelineconfig %s already exists", pipelineConfig.ID)
+	}
+	pipelineConfig.Revision = 1
+	pipelineConfig.Created = time.Now()
+	pipelineConfig.Updated = time.Now()
+	if err := s.put(pipelineConfig, p4rtapi.ConfigurationEvent_CREATED); err != nil {
+		return err
+	}
+	if options.pluginID != "" {
+		s.plugins[pipelineConfig.ID] = PluginDetails{
+			PluginID: options.pluginID,
+			Cookie:   pipelineConfig.Cookie.GetCookie(),
+		}
+	}
+	s.recordHistory(pipelineConfig)
+	return nil
+}
+
+func (s *memoryStore) Update(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error {
+	if pipelineConfig.ID == "" {
+		return errors.NewInvalid("no pipelineconfig ID specified")
+	}
+	if pipelineConfig.TargetID == "" {
+		return errors.NewInvalid("no target ID specified")
+	}
+	if pipelineConfig.Revision == 0 {
+		return errors.NewInvalid("pipelineconfig must contain a revision on update")
+	}
+	if pipelineConfig.Version == 0 {
+		return errors.NewInvalid("pipelineconfig must contain a version on update")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkVersion(pipelineConfig); err != nil {
+		return err
+	}
+	pipelineConfig.Revision++
+	pipelineConfig.Updated = time.Now()
+	if err := s.put(pipelineConfig, p4rtapi.ConfigurationEvent_UPDATED); err != nil {
+		return err
+	}
+	s.recordHistory(pipelineConfig)
+	return nil
+}
+
+func (s *memoryStore) UpdateStatus(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error {
+	if pipelineConfig.ID == "" {
+		return errors.NewInvalid("no pipeline pipelineconfig ID specified")
+	}
+	if pipelineConfig.TargetID == "" {
+		return errors.NewInvalid("no target ID specified")
+	}
+	if pipelineConfig.Revision == 0 {
+		return errors.NewInvalid("pipeline pipelineconfig must contain a revision on update")
+	}
+	if pipelineConfig.Version == 0 {
+		return errors.NewInvalid("pipeline pipelineconfig must contain a version on update")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkVersion(pipelineConfig); err != nil {
+		return err
+	}
+	pipelineConfig.Updated = time.Now()
+	return s.put(pipelineConfig, p4rtapi.ConfigurationEvent_UPDATED)
+}
+
+func (s *memoryStore) Delete(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error {
+	if pipelineConfig.ID == "" {
+		return errors.NewInvalid("no pipelineconfig ID specified")
+	}
+	if pipelineConfig.Version == 0 {
+		return errors.NewInvalid("pipelineconfig must contain a version on delete")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.checkVersion(pipelineConfig); err != nil {
+		return err
+	}
+	entry := s.pipelineConfigs[pipelineConfig.ID]
+	delete(s.pipelineConfigs, pipelineConfig.ID)
+
+	deleted := p4rtapi.PipelineConfig{}
+	if err := decodeMemoryEntry(pipelineConfig.ID, entry, &deleted); err != nil {
+		log.Error(err)
+		return nil
+	}
+	for _, revision := range retainedRevisions(deleted.Revision, s.historySize) {
+		delete(s.history, newHistoryKey(deleted.ID, revision))
+	}
+	delete(s.statusDetails, deleted.ID)
+	delete(s.plugins, deleted.ID)
+	s.eventCh <- p4rtapi.ConfigurationEvent{
+		Type:           p4rtapi.ConfigurationEvent_DELETED,
+		PipelineConfig: deleted,
+	}
+	return nil
+}
+
+func (s *memoryStore) GetPluginDetails(ctx context.Context, id p4rtapi.PipelineConfigID) (*PluginDetails, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.pipelineConfigs[id]; !ok {
+		return nil, errors.NewNotFound("pipelineconfig %s not found", id)
+	}
+	details, ok := s.plugins[id]
+	if !ok {
+		return nil, errors.NewNotFound("no P4 plugin recorded for pipelineconfig %s", id)
+	}
+	return &details, nil
+}
+
+func (s *memoryStore) UpdatePluginDetails(ctx context.Context, id p4rtapi.PipelineConfigID, details *PluginDetails) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pipelineConfigs[id]; !ok {
+		return errors.NewNotFound("pipelineconfig %s not found", id)
+	}
+	s.plugins[id] = *details
+	return nil
+}
+
+func (s *memoryStore) History(ctx context.Context, id p4rtapi.PipelineConfigID) ([]*p4rtapi.PipelineConfig, error) {
+	pipelineConfig, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	revisions := retainedRevisions(pipelineConfig.Revision, s.historySize)
+	pipelineConfigs := make([]*p4rtapi.PipelineConfig, 0, len(revisions))
+	for _, revision := range revisions {
+		bytes, ok := s.history[newHistoryKey(id, revision)]
+		if !ok {
+			continue
+		}
+		revisionConfig := &p4rtapi.PipelineConfig{}
+		if err := proto.Unmarshal(bytes, revisionConfig); err != nil {
+			return nil, errors.NewInvalid("pipelineconfig decoding failed: %v", err)
+		}
+		pipelineConfigs = append(pipelineConfigs, revisionConfig)
+	}
+	return pipelineConfigs, nil
+}
+
+func (s *memoryStore) GetStatusDetails(ctx context.Context, id p4rtapi.PipelineConfigID) (*StatusDetails, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if _, ok := s.pipelineConfigs[id]; !ok {
+		return nil, errors.NewNotFound("pipelineconfig %s not found", id)
+	}
+	bytes, ok := s.statusDetails[id]
+	if !ok {
+		return &StatusDetails{}, nil
+	}
+	return decodeStatusDetails(bytes)
+}
+
+func (s *memoryStore) UpdateStatusDetails(ctx context.Context, id p4rtapi.PipelineConfigID, details *StatusDetails) error {
+	bytes, err := encodeStatusDetails(details)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pipelineConfigs[id]; !ok {
+		return errors.NewNotFound("pipelineconfig %s not found", id)
+	}
+	s.statusDetails[id] = bytes
+	return nil
+}
+
+func (s *memoryStore) List(ctx context.Context, opts ...ListOption) ([]*p4rtapi.PipelineConfig, error) {
+	var options listOptions
+	for _, opt := range opts {
+		opt.applyList(&options)
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	page := newPage(options.limit)
+	for id, entry := range s.pipelineConfigs {
+		if !options.selects(id, s.plugins[id].PluginID, page) {
+			continue
+		}
+		pipelineConfig := &p4rtapi.PipelineConfig{}
+		if err := decodeMemoryEntry(id, entry, pipelineConfig); err != nil {
+			log.Error(err)
+		} else if options.matches(pipelineConfig) {
+			page.add(pipelineConfig)
+		}
+	}
+	return page.pipelineConfigs, nil
+}
+
+func (s *memoryStore) Watch(ctx context.Context, ch chan<- p4rtapi.ConfigurationEvent, opts ...WatchOption) error {
+	var options watchOptions
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	watchCh := make(chan p4rtapi.ConfigurationEvent, 10)
+	id := uuid.New()
+
+	// Register the watcher while holding the store lock so that no event is missed between the replay and the watch.
+	s.mu.RLock()
+	s.watchersMu.Lock()
+	s.watchers[id] = watchCh
+	s.watchersMu.Unlock()
+	var replay []p4rtapi.ConfigurationEvent
+	if options.replay {
+		for pipelineConfigID, entry := range s.pipelineConfigs {
+			var pipelineConfig p4rtapi.PipelineConfig
+			if err := decodeMemoryEntry(pipelineConfigID, entry, &pipelineConfig); err != nil {
+				log.Error(err)
+			} else if options.matches(&pipelineConfig) {
+				replay = append(replay, p4rtapi.ConfigurationEvent{
+					Type:           p4rtapi.ConfigurationEvent_REPLAYED,
+					PipelineConfig: pipelineConfig,
+				})
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	go func() {
+		defer close(ch)
+		for _, event := range replay {
+			ch <- event
+		}
+		for event := range watchCh {
+			if options.matches(&event.PipelineConfig) {
+				ch <- event
+			}
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+		s.watchersMu.Lock()
+		delete(s.watchers, id)
+		s.watchersMu.Unlock()
+		close(watchCh)
+	}()
+	return nil
+}
+
+func (s *memoryStore) Close(ctx context.Context) error {
+	return nil
+}
+
+// checkVersion checks the version of a pipelineconfig against the stored one; the caller must hold the store lock
+func (s *memoryStore) checkVersion(pipelineConfig *p4rtapi.PipelineConfig) error {
+	entry, ok := s.pipelineConfigs[pipelineConfig.ID]
+	if !ok {
+		return errors.NewNotFound("pipelineconfig %s not found", pipelineConfig.ID)
+	}
+	if entry.version != pipelineConfig.Version {
+		return errors.NewConflict("pipelineconfig %s version %d does not match %d", pipelineConfig.ID, pipelineConfig.Version, entry.version)
+	}
+	return nil
+}
+
+// put stores a pipelineconfig with a new version and publishes an event; the caller must hold the store lock
+func (s *memoryStore) put(pipelineConfig *p4rtapi.PipelineConfig, eventType p4rtapi.ConfigurationEvent_Type) error {
+	bytes, err := proto.Marshal(pipelineConfig)
+	if err != nil {
+		return errors.NewInvalid("pipeline pipelineconfig encoding failed: %v", err)
+	}
+	s.version++
+	entry := &memoryEntry{
+		version: s.version,
+		value:   bytes,
+	}
+	s.pipelineConfigs[pipelineConfig.ID] = entry
+	if err := decodeMemoryEntry(pipelineConfig.ID, entry, pipelineConfig); err != nil {
+		return errors.NewInvalid("pipelineconfig decoding failed: %v", err)
+	}
+	s.eventCh <- p4rtapi.ConfigurationEvent{
+		Type:           eventType,
+		PipelineConfig: *pipelineConfig,
+	}
+	return nil
+}
+
+// recordHistory retains a new revision of a pipelineconfig; the caller must hold the store lock
+func (s *memoryStore) recordHistory(pipelineConfig *p4rtapi.PipelineConfig) {
+	if s.historySize <= 0 {
+		return
+	}
+	bytes, err := proto.Marshal(pipelineConfig)
+	if err != nil {
+		log.Warnw("Failed encoding pipelineconfig revision", "pipelineConfig ID", pipelineConfig.ID, "revision", pipelineConfig.Revision, "error", err)
+		return
+	}
+	s.history[newHistoryKey(pipelineConfig.ID, pipelineConfig.Revision)] = bytes
+	if pipelineConfig.Revision > p4rtapi.Revision(s.historySize) {
+		delete(s.history, newHistoryKey(pipelineConfig.ID, pipelineConfig.Revision-p4rtapi.Revision(s.historySize)))
+	}
+}
+
+func decodeMemoryEntry(id p4rtapi.PipelineConfigID, entry *memoryEntry, pipelineConfig *p4rtapi.PipelineConfig) error {
+	if err := proto.Unmarshal(entry.value, pipelineConfig); err != nil {
+		return err
+	}
+	pipelineConfig.ID = id
+	pipelineConfig.Key = string(id)
+	pipelineConfig.Version = entry.version
+	return nil
+}
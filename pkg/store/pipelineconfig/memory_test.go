@@ -0,0 +1,133 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore(WithHistorySize(2))
+
+	target1 := p4rtapi.TargetID("target-1")
+	target2 := p4rtapi.TargetID("target-2")
+
+	ch := make(chan p4rtapi.ConfigurationEvent)
+	err := store.Watch(context.Background(), ch)
+	assert.NoError(t, err)
+
+	targetConfigID1 := NewPipelineConfigID(target1, "basic", "1.0.0", "v1model")
+	target1Config := &p4rtapi.PipelineConfig{
+		ID:       targetConfigID1,
+		TargetID: target1,
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+	}
+	targetConfigID2 := NewPipelineConfigID(target2, "basic", "1.0.0", "v1model")
+	target2Config := &p4rtapi.PipelineConfig{
+		ID:       targetConfigID2,
+		TargetID: target2,
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+	}
+
+	err = store.Create(context.TODO(), target1Config)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.Revision(1), target1Config.Revision)
+	assert.NotEqual(t, uint64(0), target1Config.Version)
+
+	err = store.Create(context.TODO(), target2Config)
+	assert.NoError(t, err)
+
+	err = store.Create(context.TODO(), &p4rtapi.PipelineConfig{ID: targetConfigID1, TargetID: target1})
+	assert.True(t, errors.IsAlreadyExists(err))
+
+	event := nextMemoryEvent(t, ch)
+	assert.Equal(t, p4rtapi.ConfigurationEvent_CREATED, event.Type)
+	assert.Equal(t, targetConfigID1, event.PipelineConfig.ID)
+	event = nextMemoryEvent(t, ch)
+	assert.Equal(t, p4rtapi.ConfigurationEvent_CREATED, event.Type)
+	assert.Equal(t, targetConfigID2, event.PipelineConfig.ID)
+
+	// Watch events for a specific pipeline config with replay
+	configurationCh := make(chan p4rtapi.ConfigurationEvent)
+	err = store.Watch(context.TODO(), configurationCh, WithPipelineConfigID(targetConfigID2), WithReplay())
+	assert.NoError(t, err)
+	event = nextMemoryEvent(t, configurationCh)
+	assert.Equal(t, p4rtapi.ConfigurationEvent_REPLAYED, event.Type)
+	assert.Equal(t, targetConfigID2, event.PipelineConfig.ID)
+
+	// Update and status updates bump the version but only updates bump the revision
+	version := target2Config.Version
+	err = store.Update(context.TODO(), target2Config)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.Revision(2), target2Config.Revision)
+	assert.NotEqual(t, version, target2Config.Version)
+	event = nextMemoryEvent(t, configurationCh)
+	assert.Equal(t, p4rtapi.ConfigurationEvent_UPDATED, event.Type)
+	nextMemoryEvent(t, ch)
+
+	target2Config.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	err = store.UpdateStatus(context.TODO(), target2Config)
+	assert.NoError(t, err)
+	assert.Equal(t, p4rtapi.Revision(2), target2Config.Revision)
+	event = nextMemoryEvent(t, configurationCh)
+	assert.Equal(t, p4rtapi.PipelineConfigStatus_COMPLETE, event.PipelineConfig.Status.State)
+	nextMemoryEvent(t, ch)
+
+	// Verify that concurrent updates fail
+	target1Config11, err := store.Get(context.TODO(), targetConfigID1)
+	assert.NoError(t, err)
+	target1Config12, err := store.Get(context.TODO(), targetConfigID1)
+	assert.NoError(t, err)
+
+	target1Config11.Status.State = p4rtapi.PipelineConfigStatus_PENDING
+	err = store.Update(context.TODO(), target1Config11)
+	assert.NoError(t, err)
+	nextMemoryEvent(t, ch)
+
+	target1Config12.Status.State = p4rtapi.PipelineConfigStatus_FAILED
+	err = store.Update(context.TODO(), target1Config12)
+	assert.True(t, errors.IsConflict(err))
+	err = store.Delete(context.TODO(), target1Config12)
+	assert.True(t, errors.IsConflict(err))
+
+	configurationList, err := store.List(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, configurationList, 2)
+
+	history, err := store.History(context.TODO(), targetConfigID1)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	err = store.Delete(context.TODO(), target1Config11)
+	assert.NoError(t, err)
+	event = nextMemoryEvent(t, ch)
+	assert.Equal(t, p4rtapi.ConfigurationEvent_DELETED, event.Type)
+	assert.Equal(t, targetConfigID1, event.PipelineConfig.ID)
+
+	_, err = store.Get(context.TODO(), targetConfigID1)
+	assert.True(t, errors.IsNotFound(err))
+
+	configurationList, err = store.List(context.TODO())
+	assert.NoError(t, err)
+	assert.Len(t, configurationList, 1)
+
+	assert.NoError(t, store.Close(context.TODO()))
+}
+
+func nextMemoryEvent(t *testing.T, ch chan p4rtapi.ConfigurationEvent) p4rtapi.ConfigurationEvent {
+	select {
+	case event := <-ch:
+		return event
+	case <-time.After(5 * time.Second):
+		t.FailNow()
+	}
+	return p4rtapi.ConfigurationEvent{}
+}
@@ -0,0 +1,37 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"encoding/json"
+	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+)
+
+// PluginDetails records the P4 plugin a pipelineconfig was created for, which the onos-api
+// pipelineconfig has no field for
+type PluginDetails struct {
+	// PluginID is the ID of the P4 plugin
+	PluginID p4rtapi.P4PluginID `json:"pluginId"`
+	// Cookie is the cookie of the spec the P4 plugin provided when the pipelineconfig was last
+	// synchronized with it, which changes only when the P4 plugin does
+	Cookie uint64 `json:"cookie,omitempty"`
+}
+
+func encodePluginDetails(details *PluginDetails) ([]byte, error) {
+	bytes, err := json.Marshal(details)
+	if err != nil {
+		return nil, errors.NewInvalid("pipelineconfig plugin details encoding failed: %v", err)
+	}
+	return bytes, nil
+}
+
+func decodePluginDetails(bytes []byte) (*PluginDetails, error) {
+	details := &PluginDetails{}
+	if err := json.Unmarshal(bytes, details); err != nil {
+		return nil, errors.NewInvalid("pipelineconfig plugin details decoding failed: %v", err)
+	}
+	return details, nil
+}
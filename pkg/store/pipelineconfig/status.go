@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package pipelineconfig
+
+import (
+	"encoding/json"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"time"
+)
+
+// StatusDetails completes the status of a pipelineconfig with the information the onos-api
+// pipelineconfig status has no field for
+type StatusDetails struct {
+	// Message describes the outcome of the last action performed on the target
+	Message string `json:"message,omitempty"`
+	// Attempts is the number of failed attempts to push the pipeline config since it last succeeded
+	Attempts int `json:"attempts,omitempty"`
+	// LastError is the error returned by the last failed attempt
+	LastError string `json:"lastError,omitempty"`
+	// LastAttempt is the time of the last failed attempt
+	LastAttempt time.Time `json:"lastAttempt,omitempty"`
+}
+
+func encodeStatusDetails(details *StatusDetails) ([]byte, error) {
+	bytes, err := json.Marshal(details)
+	if err != nil {
+		return nil, errors.NewInvalid("pipelineconfig status details encoding failed: %v", err)
+	}
+	return bytes, nil
+}
+
+func decodeStatusDetails(bytes []byte) (*StatusDetails, error) {
+	details := &StatusDetails{}
+	if err := json.Unmarshal(bytes, details); err != nil {
+		return nil, errors.NewInvalid("pipelineconfig status details decoding failed: %v", err)
+	}
+	return details, nil
+}
@@ -6,6 +6,7 @@ package pipelineconfig
 
 import (
 	"context"
+	"encoding/binary"
 	"fmt"
 	"github.com/atomix/atomix-go-client/pkg/atomix"
 	_map "github.com/atomix/atomix-go-client/pkg/atomix/map"
@@ -15,6 +16,8 @@ import (
 	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
 	"github.com/onosproject/onos-lib-go/pkg/errors"
 	"github.com/onosproject/onos-lib-go/pkg/logging"
+	"hash/fnv"
+	"sort"
 	"sync"
 	"time"
 )
@@ -27,19 +30,32 @@ func NewPipelineConfigID(targetID p4rtapi.TargetID, pipelineName string, pipelin
 
 }
 
+// NewPipelineConfigCookie computes a deterministic cookie for a given pipeline config spec
+func NewPipelineConfigCookie(spec *p4rtapi.PipelineConfigSpec) *p4rtapi.Cookie {
+	hash := fnv.New64a()
+	lengthBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(lengthBytes, uint64(len(spec.GetP4Info())))
+	_, _ = hash.Write(lengthBytes)
+	_, _ = hash.Write(spec.GetP4Info())
+	_, _ = hash.Write(spec.GetP4DeviceConfig())
+	return &p4rtapi.Cookie{
+		Cookie: hash.Sum64(),
+	}
+}
+
 // Store P4 pipeline pipelineconfig store interface
 type Store interface {
 	// Get gets the pipelineconfig intended for a given target ID
 	Get(ctx context.Context, id p4rtapi.PipelineConfigID) (*p4rtapi.PipelineConfig, error)
 
 	// Create creates a p4 pipeline pipelineconfig
-	Create(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error
+	Create(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig, opts ...CreateOption) error
 
 	// Update updates a p4 pipeline pipelineconfig
 	Update(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error
 
-	// List lists all the pipelineconfig
-	List(ctx context.Context) ([]*p4rtapi.PipelineConfig, error)
+	// List lists the pipelineconfigs matching the given options, sorted by ID
+	List(ctx context.Context, opts ...ListOption) ([]*p4rtapi.PipelineConfig, error)
 
 	// Watch watches pipelineconfig changes
 	Watch(ctx context.Context, ch chan<- p4rtapi.ConfigurationEvent, opts ...WatchOption) error
@@ -47,17 +63,82 @@ type Store interface {
 	// UpdateStatus updates a pipelineconfig status
 	UpdateStatus(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error
 
+	// Delete deletes a p4 pipeline pipelineconfig
+	Delete(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error
+
+	// History lists the retained revisions of a pipelineconfig from the oldest to the most recent one
+	History(ctx context.Context, id p4rtapi.PipelineConfigID) ([]*p4rtapi.PipelineConfig, error)
+
+	// GetStatusDetails gets the status details of a pipelineconfig
+	GetStatusDetails(ctx context.Context, id p4rtapi.PipelineConfigID) (*StatusDetails, error)
+
+	// UpdateStatusDetails updates the status details of a pipelineconfig
+	UpdateStatusDetails(ctx context.Context, id p4rtapi.PipelineConfigID, details *StatusDetails) error
+
+	// GetPluginDetails gets the P4 plugin details of a pipelineconfig
+	GetPluginDetails(ctx context.Context, id p4rtapi.PipelineConfigID) (*PluginDetails, error)
+
+	// UpdatePluginDetails updates the P4 plugin details of a pipelineconfig
+	UpdatePluginDetails(ctx context.Context, id p4rtapi.PipelineConfigID, details *PluginDetails) error
+
 	Close(ctx context.Context) error
 }
 
+// DefaultHistorySize is the default number of revisions retained for each pipelineconfig
+const DefaultHistorySize = 10
+
+type storeOptions struct {
+	historySize int
+}
+
+// Option is a pipelineconfig store option
+type Option interface {
+	apply(*storeOptions)
+}
+
+type historySizeOption struct {
+	size int
+}
+
+func (o historySizeOption) apply(options *storeOptions) {
+	options.historySize = o.size
+}
+
+// WithHistorySize returns an Option that sets the number of revisions retained for each pipelineconfig
+func WithHistorySize(size int) Option {
+	return historySizeOption{size: size}
+}
+
 // NewAtomixStore returns a new persistent Store
-func NewAtomixStore(client atomix.Client) (Store, error) {
+func NewAtomixStore(client atomix.Client, opts ...Option) (Store, error) {
+	options := storeOptions{
+		historySize: DefaultHistorySize,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
 	pipelineConfigs, err := client.GetMap(context.Background(), "wcmp-app-pipeline-configurations")
 	if err != nil {
 		return nil, errors.FromAtomix(err)
 	}
+	history, err := client.GetMap(context.Background(), "wcmp-app-pipeline-configuration-history")
+	if err != nil {
+		return nil, errors.FromAtomix(err)
+	}
+	statusDetails, err := client.GetMap(context.Background(), "wcmp-app-pipeline-configuration-status")
+	if err != nil {
+		return nil, errors.FromAtomix(err)
+	}
+	plugins, err := client.GetMap(context.Background(), "wcmp-app-pipeline-configuration-plugins")
+	if err != nil {
+		return nil, errors.FromAtomix(err)
+	}
 	store := &configurationStore{
 		pipelineConfigs: pipelineConfigs,
+		history:         history,
+		statusDetails:   statusDetails,
+		plugins:         plugins,
+		historySize:     options.historySize,
 		cache:           make(map[p4rtapi.PipelineConfigID]*_map.Entry),
 		watchers:        make(map[uuid.UUID]chan<- p4rtapi.ConfigurationEvent),
 		eventCh:         make(chan p4rtapi.ConfigurationEvent, 1000),
@@ -70,9 +151,16 @@ func NewAtomixStore(client atomix.Client) (Store, error) {
 
 type watchOptions struct {
 	configurationID p4rtapi.PipelineConfigID
+	targetID        p4rtapi.TargetID
 	replay          bool
 }
 
+// matches returns whether the given pipelineconfig matches the watch filters
+func (o watchOptions) matches(pipelineConfig *p4rtapi.PipelineConfig) bool {
+	return (o.configurationID == "" || pipelineConfig.ID == o.configurationID) &&
+		(o.targetID == "" || pipelineConfig.TargetID == o.targetID)
+}
+
 // WatchOption is a pipelineconfig option for Watch calls
 type WatchOption interface {
 	apply(*watchOptions)
@@ -104,8 +192,172 @@ func WithPipelineConfigID(id p4rtapi.PipelineConfigID) WatchOption {
 	return watchIDOption{id: id}
 }
 
+type listOptions struct {
+	targetID   p4rtapi.TargetID
+	pluginID   p4rtapi.P4PluginID
+	state      *p4rtapi.PipelineConfigStatus_PipelineConfigState
+	limit      int
+	startAfter p4rtapi.PipelineConfigID
+}
+
+// selects returns whether the pipelineconfig with the given ID and P4 plugin can be part of the listed page,
+// which allows entries to be skipped before they are decoded
+func (o listOptions) selects(id p4rtapi.PipelineConfigID, pluginID p4rtapi.P4PluginID, page *page) bool {
+	if o.startAfter != "" && id <= o.startAfter {
+		return false
+	}
+	if o.pluginID != "" && pluginID != o.pluginID {
+		return false
+	}
+	return page.accepts(id)
+}
+
+// matches returns whether the given pipelineconfig matches the list filters
+func (o listOptions) matches(pipelineConfig *p4rtapi.PipelineConfig) bool {
+	if o.targetID != "" && pipelineConfig.TargetID != o.targetID {
+		return false
+	}
+	return o.state == nil || pipelineConfig.Status.State == *o.state
+}
+
+// page collects the pipelineconfigs of a List call sorted by ID, retaining at most limit of them
+type page struct {
+	limit           int
+	pipelineConfigs []*p4rtapi.PipelineConfig
+}
+
+func newPage(limit int) *page {
+	return &page{
+		limit:           limit,
+		pipelineConfigs: make([]*p4rtapi.PipelineConfig, 0),
+	}
+}
+
+// accepts returns whether a pipelineconfig with the given ID would be part of the page
+func (p *page) accepts(id p4rtapi.PipelineConfigID) bool {
+	return p.limit <= 0 || len(p.pipelineConfigs) < p.limit || id < p.pipelineConfigs[len(p.pipelineConfigs)-1].ID
+}
+
+// add inserts a pipelineconfig in the page, dropping the last one if the page is full
+func (p *page) add(pipelineConfig *p4rtapi.PipelineConfig) {
+	i := sort.Search(len(p.pipelineConfigs), func(i int) bool {
+		return p.pipelineConfigs[i].ID > pipelineConfig.ID
+	})
+	p.pipelineConfigs = append(p.pipelineConfigs, nil)
+	copy(p.pipelineConfigs[i+1:], p.pipelineConfigs[i:])
+	p.pipelineConfigs[i] = pipelineConfig
+	if p.limit > 0 && len(p.pipelineConfigs) > p.limit {
+		p.pipelineConfigs = p.pipelineConfigs[:p.limit]
+	}
+}
+
+// ListOption is a pipelineconfig option for List calls
+type ListOption interface {
+	applyList(*listOptions)
+}
+
+// ListWatchOption is a pipelineconfig option for both List and Watch calls
+type ListWatchOption interface {
+	ListOption
+	WatchOption
+}
+
+type targetIDOption struct {
+	targetID p4rtapi.TargetID
+}
+
+func (o targetIDOption) apply(options *watchOptions) {
+	options.targetID = o.targetID
+}
+
+func (o targetIDOption) applyList(options *listOptions) {
+	options.targetID = o.targetID
+}
+
+// WithTargetID returns an option that lists or watches the pipelineconfigs of the given target
+func WithTargetID(targetID p4rtapi.TargetID) ListWatchOption {
+	return targetIDOption{targetID: targetID}
+}
+
+type createOptions struct {
+	pluginID p4rtapi.P4PluginID
+}
+
+// CreateOption is a pipelineconfig option for Create calls
+type CreateOption interface {
+	applyCreate(*createOptions)
+}
+
+// ListCreateOption is a pipelineconfig option for both List and Create calls
+type ListCreateOption interface {
+	ListOption
+	CreateOption
+}
+
+type pluginIDOption struct {
+	pluginID p4rtapi.P4PluginID
+}
+
+func (o pluginIDOption) applyList(options *listOptions) {
+	options.pluginID = o.pluginID
+}
+
+func (o pluginIDOption) applyCreate(options *createOptions) {
+	options.pluginID = o.pluginID
+}
+
+// WithPluginID returns an option that records the P4 plugin of a created pipelineconfig, or that lists
+// the pipelineconfigs created for the given P4 plugin
+func WithPluginID(pluginID p4rtapi.P4PluginID) ListCreateOption {
+	return pluginIDOption{pluginID: pluginID}
+}
+
+type stateOption struct {
+	state p4rtapi.PipelineConfigStatus_PipelineConfigState
+}
+
+func (o stateOption) applyList(options *listOptions) {
+	options.state = &o.state
+}
+
+// WithState returns a List option that lists the pipelineconfigs in the given state
+func WithState(state p4rtapi.PipelineConfigStatus_PipelineConfigState) ListOption {
+	return stateOption{state: state}
+}
+
+type limitOption struct {
+	limit int
+}
+
+func (o limitOption) applyList(options *listOptions) {
+	options.limit = o.limit
+}
+
+// WithLimit returns a List option that limits the number of listed pipelineconfigs
+func WithLimit(limit int) ListOption {
+	return limitOption{limit: limit}
+}
+
+type startAfterOption struct {
+	id p4rtapi.PipelineConfigID
+}
+
+func (o startAfterOption) applyList(options *listOptions) {
+	options.startAfter = o.id
+}
+
+// WithStartAfter returns a List option that lists the pipelineconfigs following the given ID, which is
+// the ID of the last pipelineconfig of the previous page
+func WithStartAfter(id p4rtapi.PipelineConfigID) ListOption {
+	return startAfterOption{id: id}
+}
+
 type configurationStore struct {
 	pipelineConfigs _map.Map
+	history         _map.Map
+	statusDetails   _map.Map
+	plugins         _map.Map
+	historySize     int
 	cache           map[p4rtapi.PipelineConfigID]*_map.Entry
 	cacheMu         sync.RWMutex
 	watchers        map[uuid.UUID]chan<- p4rtapi.ConfigurationEvent
@@ -121,7 +373,11 @@ func (s *configurationStore) open(ctx context.Context) error {
 	go func() {
 		for event := range ch {
 			entry := event.Entry
-			s.updateCache(&entry)
+			if event.Type == _map.EventRemove {
+				s.deleteCache(&entry)
+			} else {
+				s.updateCache(&entry)
+			}
 		}
 	}()
 	go s.processEvents()
@@ -180,6 +436,28 @@ func (s *configurationStore) updateCache(newEntry *_map.Entry) {
 	}
 }
 
+func (s *configurationStore) deleteCache(deletedEntry *_map.Entry) {
+	configurationID := p4rtapi.PipelineConfigID(deletedEntry.Key)
+
+	// Only remove the pipelineconfig from the cache if the cached entry is not more recent than the deleted one.
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	entry, ok := s.cache[configurationID]
+	if !ok || entry.Revision > deletedEntry.Revision {
+		return
+	}
+	delete(s.cache, configurationID)
+	var pipelineConfig p4rtapi.PipelineConfig
+	if err := decodePipelineConfiguration(entry, &pipelineConfig); err != nil {
+		log.Error(err)
+	} else {
+		s.eventCh <- p4rtapi.ConfigurationEvent{
+			Type:           p4rtapi.ConfigurationEvent_DELETED,
+			PipelineConfig: pipelineConfig,
+		}
+	}
+}
+
 func (s *configurationStore) Get(ctx context.Context, id p4rtapi.PipelineConfigID) (*p4rtapi.PipelineConfig, error) {
 	// Check the ID cache for the latest version of the pipelineconfig.
 	s.cacheMu.RLock()
@@ -210,7 +488,12 @@ func (s *configurationStore) Get(ctx context.Context, id p4rtapi.PipelineConfigI
 	return configuration, nil
 }
 
-func (s *configurationStore) Create(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error {
+func (s *configurationStore) Create(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig, opts ...CreateOption) error {
+	var options createOptions
+	for _, opt := range opts {
+		opt.applyCreate(&options)
+	}
+
 	if pipelineConfig.ID == "" {
 		return errors.NewInvalid("no pipeline pipelineconfig ID specified")
 	}
@@ -227,6 +510,20 @@ func (s *configurationStore) Create(ctx context.Context, pipelineConfig *p4rtapi
 	pipelineConfig.Created = time.Now()
 	pipelineConfig.Updated = time.Now()
 
+	// Record the P4 plugin before creating the pipelineconfig so that it is listed with the plugin as soon as it exists.
+	if options.pluginID != "" {
+		bytes, err := encodePluginDetails(&PluginDetails{
+			PluginID: options.pluginID,
+			Cookie:   pipelineConfig.Cookie.GetCookie(),
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := s.plugins.Put(ctx, string(pipelineConfig.ID), bytes); err != nil {
+			return errors.FromAtomix(err)
+		}
+	}
+
 	// Encode the pipelineconfig bytes.
 	bytes, err := proto.Marshal(pipelineConfig)
 	if err != nil {
@@ -246,6 +543,7 @@ func (s *configurationStore) Create(ctx context.Context, pipelineConfig *p4rtapi
 
 	// Update the cache.
 	s.updateCache(entry)
+	s.recordHistory(ctx, pipelineConfig)
 	return nil
 }
 
@@ -285,6 +583,7 @@ func (s *configurationStore) Update(ctx context.Context, pipelineConfig *p4rtapi
 
 	// Update the cache.
 	s.updateCache(entry)
+	s.recordHistory(ctx, pipelineConfig)
 	return nil
 }
 
@@ -326,23 +625,224 @@ func (s *configurationStore) UpdateStatus(ctx context.Context, pipelineConfig *p
 	return nil
 }
 
-func (s *configurationStore) List(ctx context.Context) ([]*p4rtapi.PipelineConfig, error) {
+func (s *configurationStore) Delete(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) error {
+	if pipelineConfig.ID == "" {
+		return errors.NewInvalid("no pipelineconfig ID specified")
+	}
+	if pipelineConfig.Version == 0 {
+		return errors.NewInvalid("pipelineconfig must contain a version on delete")
+	}
+
+	// Remove the entry from the underlying map primitive using the pipelineconfig version
+	// as an optimistic lock.
+	entry, err := s.pipelineConfigs.Remove(ctx, string(pipelineConfig.ID), _map.IfMatch(meta.NewRevision(meta.Revision(pipelineConfig.Version))))
+	if err != nil {
+		return errors.FromAtomix(err)
+	}
+
+	// Update the cache.
+	s.deleteCache(entry)
+
+	// Remove the retained revisions of the pipelineconfig.
+	deleted := &p4rtapi.PipelineConfig{}
+	if err := decodePipelineConfiguration(entry, deleted); err != nil {
+		log.Error(err)
+		return nil
+	}
+	for _, revision := range retainedRevisions(deleted.Revision, s.historySize) {
+		if _, err := s.history.Remove(ctx, newHistoryKey(deleted.ID, revision)); err != nil && !errors.IsNotFound(errors.FromAtomix(err)) {
+			log.Warnw("Failed removing pipelineconfig revision", "pipelineConfig ID", deleted.ID, "revision", revision, "error", err)
+		}
+	}
+	if _, err := s.statusDetails.Remove(ctx, string(deleted.ID)); err != nil && !errors.IsNotFound(errors.FromAtomix(err)) {
+		log.Warnw("Failed removing pipelineconfig status details", "pipelineConfig ID", deleted.ID, "error", err)
+	}
+	if _, err := s.plugins.Remove(ctx, string(deleted.ID)); err != nil && !errors.IsNotFound(errors.FromAtomix(err)) {
+		log.Warnw("Failed removing pipelineconfig P4 plugin", "pipelineConfig ID", deleted.ID, "error", err)
+	}
+	return nil
+}
+
+func (s *configurationStore) GetStatusDetails(ctx context.Context, id p4rtapi.PipelineConfigID) (*StatusDetails, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	entry, err := s.statusDetails.Get(ctx, string(id))
+	if err != nil {
+		err = errors.FromAtomix(err)
+		if errors.IsNotFound(err) {
+			return &StatusDetails{}, nil
+		}
+		return nil, err
+	}
+	return decodeStatusDetails(entry.Value)
+}
+
+func (s *configurationStore) UpdateStatusDetails(ctx context.Context, id p4rtapi.PipelineConfigID, details *StatusDetails) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	bytes, err := encodeStatusDetails(details)
+	if err != nil {
+		return err
+	}
+	if _, err := s.statusDetails.Put(ctx, string(id), bytes); err != nil {
+		return errors.FromAtomix(err)
+	}
+	return nil
+}
+
+func (s *configurationStore) GetPluginDetails(ctx context.Context, id p4rtapi.PipelineConfigID) (*PluginDetails, error) {
+	if _, err := s.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	entry, err := s.plugins.Get(ctx, string(id))
+	if err != nil {
+		err = errors.FromAtomix(err)
+		if errors.IsNotFound(err) {
+			return nil, errors.NewNotFound("no P4 plugin recorded for pipelineconfig %s", id)
+		}
+		return nil, err
+	}
+	return decodePluginDetails(entry.Value)
+}
+
+func (s *configurationStore) UpdatePluginDetails(ctx context.Context, id p4rtapi.PipelineConfigID, details *PluginDetails) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	bytes, err := encodePluginDetails(details)
+	if err != nil {
+		return err
+	}
+	if _, err := s.plugins.Put(ctx, string(id), bytes); err != nil {
+		return errors.FromAtomix(err)
+	}
+	return nil
+}
+
+func (s *configurationStore) History(ctx context.Context, id p4rtapi.PipelineConfigID) ([]*p4rtapi.PipelineConfig, error) {
+	pipelineConfig, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	revisions := retainedRevisions(pipelineConfig.Revision, s.historySize)
+	pipelineConfigs := make([]*p4rtapi.PipelineConfig, 0, len(revisions))
+	for _, revision := range revisions {
+		entry, err := s.history.Get(ctx, newHistoryKey(id, revision))
+		if err != nil {
+			err = errors.FromAtomix(err)
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		revisionConfig := &p4rtapi.PipelineConfig{}
+		if err := proto.Unmarshal(entry.Value, revisionConfig); err != nil {
+			return nil, errors.NewInvalid("pipelineconfig decoding failed: %v", err)
+		}
+		pipelineConfigs = append(pipelineConfigs, revisionConfig)
+	}
+	return pipelineConfigs, nil
+}
+
+// recordHistory retains a new revision of a pipelineconfig and discards the revisions beyond the history size
+func (s *configurationStore) recordHistory(ctx context.Context, pipelineConfig *p4rtapi.PipelineConfig) {
+	if s.historySize <= 0 {
+		return
+	}
+	bytes, err := proto.Marshal(pipelineConfig)
+	if err != nil {
+		log.Warnw("Failed encoding pipelineconfig revision", "pipelineConfig ID", pipelineConfig.ID, "revision", pipelineConfig.Revision, "error", err)
+		return
+	}
+	if _, err := s.history.Put(ctx, newHistoryKey(pipelineConfig.ID, pipelineConfig.Revision), bytes); err != nil {
+		log.Warnw("Failed recording pipelineconfig revision", "pipelineConfig ID", pipelineConfig.ID, "revision", pipelineConfig.Revision, "error", err)
+		return
+	}
+	if pipelineConfig.Revision > p4rtapi.Revision(s.historySize) {
+		expired := pipelineConfig.Revision - p4rtapi.Revision(s.historySize)
+		if _, err := s.history.Remove(ctx, newHistoryKey(pipelineConfig.ID, expired)); err != nil && !errors.IsNotFound(errors.FromAtomix(err)) {
+			log.Warnw("Failed removing pipelineconfig revision", "pipelineConfig ID", pipelineConfig.ID, "revision", expired, "error", err)
+		}
+	}
+}
+
+// retainedRevisions returns the revisions retained for a pipelineconfig at the given revision
+func retainedRevisions(revision p4rtapi.Revision, historySize int) []p4rtapi.Revision {
+	if historySize <= 0 {
+		return nil
+	}
+	first := p4rtapi.Revision(1)
+	if revision > p4rtapi.Revision(historySize) {
+		first = revision - p4rtapi.Revision(historySize) + 1
+	}
+	revisions := make([]p4rtapi.Revision, 0, historySize)
+	for r := first; r <= revision; r++ {
+		revisions = append(revisions, r)
+	}
+	return revisions
+}
+
+func newHistoryKey(id p4rtapi.PipelineConfigID, revision p4rtapi.Revision) string {
+	return fmt.Sprintf("%s/%d", id, revision)
+}
+
+func (s *configurationStore) List(ctx context.Context, opts ...ListOption) ([]*p4rtapi.PipelineConfig, error) {
+	var options listOptions
+	for _, opt := range opts {
+		opt.applyList(&options)
+	}
+
+	var plugins map[p4rtapi.PipelineConfigID]p4rtapi.P4PluginID
+	if options.pluginID != "" {
+		var err error
+		if plugins, err = s.listPlugins(ctx, options.pluginID); err != nil {
+			return nil, err
+		}
+	}
+
 	mapCh := make(chan _map.Entry)
 	if err := s.pipelineConfigs.Entries(ctx, mapCh); err != nil {
 		return nil, errors.FromAtomix(err)
 	}
 
-	pipelineConfigs := make([]*p4rtapi.PipelineConfig, 0)
-
+	// Map entries are not sorted, so every entry must be visited to find the first page. Entries that cannot be
+	// part of the page are skipped without being decoded, and only the pipelineconfigs of the page are retained.
+	page := newPage(options.limit)
 	for entry := range mapCh {
+		id := p4rtapi.PipelineConfigID(entry.Key)
+		if !options.selects(id, plugins[id], page) {
+			continue
+		}
 		pipelineConfig := &p4rtapi.PipelineConfig{}
 		if err := decodePipelineConfiguration(&entry, pipelineConfig); err != nil {
 			log.Error(err)
-		} else {
-			pipelineConfigs = append(pipelineConfigs, pipelineConfig)
+		} else if options.matches(pipelineConfig) {
+			page.add(pipelineConfig)
 		}
 	}
-	return pipelineConfigs, nil
+	return page.pipelineConfigs, nil
+}
+
+// listPlugins returns the IDs of the pipelineconfigs created for the given P4 plugin
+func (s *configurationStore) listPlugins(ctx context.Context, pluginID p4rtapi.P4PluginID) (map[p4rtapi.PipelineConfigID]p4rtapi.P4PluginID, error) {
+	mapCh := make(chan _map.Entry)
+	if err := s.plugins.Entries(ctx, mapCh); err != nil {
+		return nil, errors.FromAtomix(err)
+	}
+	plugins := make(map[p4rtapi.PipelineConfigID]p4rtapi.P4PluginID)
+	for entry := range mapCh {
+		details, err := decodePluginDetails(entry.Value)
+		if err != nil {
+			log.Warnw("Failed decoding pipelineconfig P4 plugin", "pipelineConfig ID", entry.Key, "error", err)
+			continue
+		}
+		if details.PluginID == pluginID {
+			plugins[p4rtapi.PipelineConfigID(entry.Key)] = pluginID
+		}
+	}
+	return plugins, nil
 }
 
 func (s *configurationStore) Watch(ctx context.Context, ch chan<- p4rtapi.ConfigurationEvent, opts ...WatchOption) error {
@@ -366,7 +866,7 @@ func (s *configurationStore) Watch(ctx context.Context, ch chan<- p4rtapi.Config
 				var pipelineConfig p4rtapi.PipelineConfig
 				if err := decodePipelineConfiguration(entry, &pipelineConfig); err != nil {
 					log.Errorw("error", err)
-				} else {
+				} else if options.matches(&pipelineConfig) {
 					replay = append(replay, p4rtapi.ConfigurationEvent{
 						Type:           p4rtapi.ConfigurationEvent_REPLAYED,
 						PipelineConfig: pipelineConfig,
@@ -381,7 +881,7 @@ func (s *configurationStore) Watch(ctx context.Context, ch chan<- p4rtapi.Config
 				var pipelineConfig p4rtapi.PipelineConfig
 				if err := decodePipelineConfiguration(entry, &pipelineConfig); err != nil {
 					log.Error(err)
-				} else {
+				} else if options.matches(&pipelineConfig) {
 					replay = []p4rtapi.ConfigurationEvent{
 						{
 							Type:           p4rtapi.ConfigurationEvent_REPLAYED,
@@ -400,7 +900,7 @@ func (s *configurationStore) Watch(ctx context.Context, ch chan<- p4rtapi.Config
 			ch <- event
 		}
 		for event := range watchCh {
-			if options.configurationID == "" || event.PipelineConfig.ID == options.configurationID {
+			if options.matches(&event.PipelineConfig) {
 				ch <- event
 			}
 		}
@@ -421,6 +921,18 @@ func (s *configurationStore) Close(ctx context.Context) error {
 	if err != nil {
 		return errors.FromAtomix(err)
 	}
+	err = s.history.Close(ctx)
+	if err != nil {
+		return errors.FromAtomix(err)
+	}
+	err = s.statusDetails.Close(ctx)
+	if err != nil {
+		return errors.FromAtomix(err)
+	}
+	err = s.plugins.Close(ctx)
+	if err != nil {
+		return errors.FromAtomix(err)
+	}
 	return nil
 }
 
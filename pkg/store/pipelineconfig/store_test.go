@@ -12,6 +12,7 @@ import (
 	"github.com/atomix/atomix-go-client/pkg/atomix/test"
 	"github.com/atomix/atomix-go-client/pkg/atomix/test/rsm"
 	p4rtapi "github.com/onosproject/onos-api/go/onos/p4rt/v1"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -142,10 +143,30 @@ func TestConfigurationStore(t *testing.T) {
 	configurationEvent = nextEvent(t, ch)
 	assert.NotNil(t, configurationEvent)
 
+	// Verify that deleting a stale pipelineconfig fails
+	err = store2.Delete(context.TODO(), target1Config12)
+	assert.Error(t, err)
+	assert.True(t, errors.IsConflict(err))
+
+	// Delete a pipelineconfig
+	err = store2.Delete(context.TODO(), target1Config11)
+	assert.NoError(t, err)
+
+	select {
+	case event := <-ch:
+		assert.Equal(t, p4rtapi.ConfigurationEvent_DELETED, event.Type)
+		assert.Equal(t, targetConfigID1, event.PipelineConfig.ID)
+	case <-time.After(5 * time.Second):
+		t.FailNow()
+	}
+
+	_, err = store2.Get(context.TODO(), targetConfigID1)
+	assert.True(t, errors.IsNotFound(err))
+
 	// Checks list of pipelineconfig after deleting a pipelineconfig
 	configurationList, err = store2.List(context.TODO())
 	assert.NoError(t, err)
-	assert.Equal(t, 2, len(configurationList))
+	assert.Equal(t, 1, len(configurationList))
 
 	err = store1.Close(context.TODO())
 	assert.NoError(t, err)
@@ -164,3 +185,304 @@ func nextEvent(t *testing.T, ch chan p4rtapi.ConfigurationEvent) *p4rtapi.Pipeli
 	}
 	return nil
 }
+
+func TestNewPipelineConfigCookie(t *testing.T) {
+	spec1 := &p4rtapi.PipelineConfigSpec{
+		P4Info:         []byte{1, 2, 3},
+		P4DeviceConfig: []byte{4, 5},
+	}
+	spec2 := &p4rtapi.PipelineConfigSpec{
+		P4Info:         []byte{1, 2},
+		P4DeviceConfig: []byte{3, 4, 5},
+	}
+	assert.Equal(t, NewPipelineConfigCookie(spec1).Cookie, NewPipelineConfigCookie(spec1).Cookie)
+	assert.NotEqual(t, NewPipelineConfigCookie(spec1).Cookie, NewPipelineConfigCookie(spec2).Cookie)
+}
+
+func TestPipelineConfigHistory(t *testing.T) {
+	test := test.NewTest(
+		rsm.NewProtocol(),
+		test.WithReplicas(1),
+		test.WithPartitions(1),
+	)
+	assert.NoError(t, test.Start())
+	defer test.Stop()
+
+	client, err := test.NewClient("node-1")
+	assert.NoError(t, err)
+
+	store, err := NewAtomixStore(client, WithHistorySize(2))
+	assert.NoError(t, err)
+
+	targetConfigID := NewPipelineConfigID("target-1", "basic", "1.0.0", "v1model")
+	targetConfig := &p4rtapi.PipelineConfig{
+		ID:       targetConfigID,
+		TargetID: "target-1",
+		Spec: &p4rtapi.PipelineConfigSpec{
+			P4DeviceConfig: []byte("v1"),
+		},
+	}
+	err = store.Create(context.TODO(), targetConfig)
+	assert.NoError(t, err)
+
+	history, err := store.History(context.TODO(), targetConfigID)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+	assert.Equal(t, p4rtapi.Revision(1), history[0].Revision)
+
+	targetConfig.Spec.P4DeviceConfig = []byte("v2")
+	err = store.Update(context.TODO(), targetConfig)
+	assert.NoError(t, err)
+	targetConfig.Spec.P4DeviceConfig = []byte("v3")
+	err = store.Update(context.TODO(), targetConfig)
+	assert.NoError(t, err)
+
+	// Status updates do not create revisions
+	targetConfig.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+	err = store.UpdateStatus(context.TODO(), targetConfig)
+	assert.NoError(t, err)
+
+	// Only the last two revisions are retained
+	history, err = store.History(context.TODO(), targetConfigID)
+	assert.NoError(t, err)
+	assert.Len(t, history, 2)
+	assert.Equal(t, p4rtapi.Revision(2), history[0].Revision)
+	assert.Equal(t, []byte("v2"), history[0].Spec.P4DeviceConfig)
+	assert.Equal(t, p4rtapi.Revision(3), history[1].Revision)
+	assert.Equal(t, []byte("v3"), history[1].Spec.P4DeviceConfig)
+
+	err = store.Delete(context.TODO(), targetConfig)
+	assert.NoError(t, err)
+	_, err = store.History(context.TODO(), targetConfigID)
+	assert.True(t, errors.IsNotFound(err))
+
+	err = store.Close(context.TODO())
+	assert.NoError(t, err)
+}
+
+func TestListPipelineConfigs(t *testing.T) {
+	test := test.NewTest(
+		rsm.NewProtocol(),
+		test.WithReplicas(1),
+		test.WithPartitions(1),
+	)
+	assert.NoError(t, test.Start())
+	defer test.Stop()
+
+	client, err := test.NewClient("node-1")
+	assert.NoError(t, err)
+	atomixStore, err := NewAtomixStore(client)
+	assert.NoError(t, err)
+
+	for _, store := range []Store{atomixStore, NewMemoryStore()} {
+		testListPipelineConfigs(t, store)
+		assert.NoError(t, store.Close(context.TODO()))
+	}
+}
+
+func testListPipelineConfigs(t *testing.T, store Store) {
+	ctx := context.TODO()
+	for _, target := range []p4rtapi.TargetID{"target-1", "target-2", "target-3"} {
+		for _, version := range []string{"1.0.0", "2.0.0"} {
+			pipelineConfig := &p4rtapi.PipelineConfig{
+				ID:       NewPipelineConfigID(target, "basic", version, "v1model"),
+				TargetID: target,
+				Spec:     &p4rtapi.PipelineConfigSpec{},
+			}
+			if version == "2.0.0" {
+				pipelineConfig.Status.State = p4rtapi.PipelineConfigStatus_COMPLETE
+			}
+			assert.NoError(t, store.Create(ctx, pipelineConfig, WithPluginID(p4rtapi.NewP4PluginID("basic", version, "v1model"))))
+		}
+	}
+
+	targetCh := make(chan p4rtapi.ConfigurationEvent)
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	assert.NoError(t, store.Watch(watchCtx, targetCh, WithTargetID("target-2"), WithReplay()))
+
+	pipelineConfigs, err := store.List(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 6)
+
+	pipelineConfigs, err = store.List(ctx, WithTargetID("target-2"))
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 2)
+	assert.Equal(t, p4rtapi.PipelineConfigID("target-2-basic-1.0.0-v1model"), pipelineConfigs[0].ID)
+
+	pipelineConfigs, err = store.List(ctx, WithPluginID(p4rtapi.NewP4PluginID("basic", "2.0.0", "v1model")))
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 3)
+
+	pipelineConfigs, err = store.List(ctx, WithPluginID(p4rtapi.NewP4PluginID("basic", "2.0.0", "v1model")), WithLimit(2))
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 2)
+	assert.Equal(t, p4rtapi.PipelineConfigID("target-1-basic-2.0.0-v1model"), pipelineConfigs[0].ID)
+	assert.Equal(t, p4rtapi.PipelineConfigID("target-2-basic-2.0.0-v1model"), pipelineConfigs[1].ID)
+
+	pipelineConfigs, err = store.List(ctx, WithState(p4rtapi.PipelineConfigStatus_COMPLETE), WithTargetID("target-3"))
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 1)
+	assert.Equal(t, p4rtapi.PipelineConfigID("target-3-basic-2.0.0-v1model"), pipelineConfigs[0].ID)
+
+	// List all the pipeline configs page by page
+	var ids []p4rtapi.PipelineConfigID
+	var startAfter p4rtapi.PipelineConfigID
+	for {
+		page, err := store.List(ctx, WithLimit(4), WithStartAfter(startAfter))
+		assert.NoError(t, err)
+		if len(page) == 0 {
+			break
+		}
+		for _, pipelineConfig := range page {
+			ids = append(ids, pipelineConfig.ID)
+		}
+		startAfter = page[len(page)-1].ID
+	}
+	assert.Len(t, ids, 6)
+	assert.Equal(t, p4rtapi.PipelineConfigID("target-1-basic-1.0.0-v1model"), ids[0])
+	assert.Equal(t, p4rtapi.PipelineConfigID("target-3-basic-2.0.0-v1model"), ids[5])
+
+	// Pipeline configs are listed by the P4 plugin recorded on creation rather than by their ID
+	assert.NoError(t, store.Create(ctx, &p4rtapi.PipelineConfig{
+		ID:       NewPipelineConfigID("target-4", "basic", "2.0.0", "v1model"),
+		TargetID: "target-4",
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+	}))
+	pipelineConfigs, err = store.List(ctx, WithPluginID(p4rtapi.NewP4PluginID("basic", "2.0.0", "v1model")))
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 3)
+
+	// Pipeline configs are listed by the target they configure rather than by their ID
+	assert.NoError(t, store.Create(ctx, &p4rtapi.PipelineConfig{
+		ID:       "pipeline-5",
+		TargetID: "target-5",
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+	}))
+	assert.NoError(t, store.Create(ctx, &p4rtapi.PipelineConfig{
+		ID:       NewPipelineConfigID("target-5-a", "basic", "2.0.0", "v1model"),
+		TargetID: "target-5-a",
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+	}))
+	pipelineConfigs, err = store.List(ctx, WithTargetID("target-5"))
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 1)
+	assert.Equal(t, p4rtapi.PipelineConfigID("pipeline-5"), pipelineConfigs[0].ID)
+
+	// Only the events of the watched target are received
+	received := 0
+	for {
+		select {
+		case event := <-targetCh:
+			assert.Equal(t, p4rtapi.TargetID("target-2"), event.PipelineConfig.TargetID)
+			received++
+			continue
+		case <-time.After(100 * time.Millisecond):
+		}
+		break
+	}
+	assert.GreaterOrEqual(t, received, 2)
+}
+
+func TestPipelineConfigStatusDetails(t *testing.T) {
+	test := test.NewTest(
+		rsm.NewProtocol(),
+		test.WithReplicas(1),
+		test.WithPartitions(1),
+	)
+	assert.NoError(t, test.Start())
+	defer test.Stop()
+
+	client, err := test.NewClient("node-1")
+	assert.NoError(t, err)
+
+	store, err := NewAtomixStore(client)
+	assert.NoError(t, err)
+	testStatusDetails(t, store)
+	assert.NoError(t, store.Close(context.TODO()))
+
+	testStatusDetails(t, NewMemoryStore())
+}
+
+func testStatusDetails(t *testing.T, store Store) {
+	targetConfigID := NewPipelineConfigID("target-1", "basic", "1.0.0", "v1model")
+	_, err := store.GetStatusDetails(context.TODO(), targetConfigID)
+	assert.True(t, errors.IsNotFound(err))
+	err = store.UpdateStatusDetails(context.TODO(), targetConfigID, &StatusDetails{Message: "saved"})
+	assert.True(t, errors.IsNotFound(err))
+
+	targetConfig := &p4rtapi.PipelineConfig{
+		ID:       targetConfigID,
+		TargetID: "target-1",
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+	}
+	assert.NoError(t, store.Create(context.TODO(), targetConfig))
+	details, err := store.GetStatusDetails(context.TODO(), targetConfigID)
+	assert.NoError(t, err)
+	assert.Empty(t, details.Message)
+
+	assert.NoError(t, store.UpdateStatusDetails(context.TODO(), targetConfigID, &StatusDetails{Message: "saved"}))
+	details, err = store.GetStatusDetails(context.TODO(), targetConfigID)
+	assert.NoError(t, err)
+	assert.Equal(t, "saved", details.Message)
+
+	// Details are removed with the pipeline config
+	assert.NoError(t, store.Delete(context.TODO(), targetConfig))
+	assert.NoError(t, store.Create(context.TODO(), &p4rtapi.PipelineConfig{
+		ID:       targetConfigID,
+		TargetID: "target-1",
+		Spec:     &p4rtapi.PipelineConfigSpec{},
+	}))
+	details, err = store.GetStatusDetails(context.TODO(), targetConfigID)
+	assert.NoError(t, err)
+	assert.Empty(t, details.Message)
+}
+
+func TestPipelineConfigPluginDetails(t *testing.T) {
+	test := test.NewTest(
+		rsm.NewProtocol(),
+		test.WithReplicas(1),
+		test.WithPartitions(1),
+	)
+	assert.NoError(t, test.Start())
+	defer test.Stop()
+
+	client, err := test.NewClient("node-1")
+	assert.NoError(t, err)
+
+	store, err := NewAtomixStore(client)
+	assert.NoError(t, err)
+	testPluginDetails(t, store)
+	assert.NoError(t, store.Close(context.TODO()))
+
+	testPluginDetails(t, NewMemoryStore())
+}
+
+func testPluginDetails(t *testing.T, store Store) {
+	targetConfigID := NewPipelineConfigID("target-1", "basic", "1.0.0", "v1model")
+	pluginID := p4rtapi.NewP4PluginID("basic", "1.0.0", "v1model")
+	_, err := store.GetPluginDetails(context.TODO(), targetConfigID)
+	assert.True(t, errors.IsNotFound(err))
+	err = store.UpdatePluginDetails(context.TODO(), targetConfigID, &PluginDetails{PluginID: pluginID})
+	assert.True(t, errors.IsNotFound(err))
+
+	spec := &p4rtapi.PipelineConfigSpec{P4DeviceConfig: []byte("basic")}
+	assert.NoError(t, store.Create(context.TODO(), &p4rtapi.PipelineConfig{
+		ID:       targetConfigID,
+		TargetID: "target-1",
+		Cookie:   NewPipelineConfigCookie(spec),
+		Spec:     spec,
+	}, WithPluginID(pluginID)))
+	details, err := store.GetPluginDetails(context.TODO(), targetConfigID)
+	assert.NoError(t, err)
+	assert.Equal(t, pluginID, details.PluginID)
+	assert.Equal(t, NewPipelineConfigCookie(spec).Cookie, details.Cookie)
+
+	assert.NoError(t, store.UpdatePluginDetails(context.TODO(), targetConfigID, &PluginDetails{PluginID: pluginID, Cookie: 1}))
+	details, err = store.GetPluginDetails(context.TODO(), targetConfigID)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), details.Cookie)
+	pipelineConfigs, err := store.List(context.TODO(), WithPluginID(pluginID))
+	assert.NoError(t, err)
+	assert.Len(t, pipelineConfigs, 1)
+}
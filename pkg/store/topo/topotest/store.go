@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2022-present Intel Corporation
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package topotest provides an in-memory topology store for the tests of the topology clients
+package topotest
+
+import (
+	"context"
+	"sync"
+
+	topoapi "github.com/onosproject/onos-api/go/onos/topo"
+	"github.com/onosproject/onos-lib-go/pkg/errors"
+	"github.com/onosproject/wcmp-app/pkg/store/topo"
+)
+
+// Store is an in-memory topology store. Objects are stored and returned as given, so that tests can
+// change them in place, and no events are delivered to watchers.
+type Store struct {
+	objects map[topoapi.ID]*topoapi.Object
+	mu      sync.RWMutex
+}
+
+// NewStore returns an in-memory topology store holding the given objects
+func NewStore(objects ...*topoapi.Object) *Store {
+	store := &Store{
+		objects: make(map[topoapi.ID]*topoapi.Object),
+	}
+	for _, object := range objects {
+		store.objects[object.ID] = object
+	}
+	return store
+}
+
+// Create creates a topology object
+func (s *Store) Create(ctx context.Context, object *topoapi.Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[object.ID]; ok {
+		return errors.NewAlreadyExists("object %s already exists", object.ID)
+	}
+	s.objects[object.ID] = object
+	return nil
+}
+
+// Update updates a topology object, creating it if it does not exist
+func (s *Store) Update(ctx context.Context, object *topoapi.Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[object.ID] = object
+	return nil
+}
+
+// Get gets a topology object
+func (s *Store) Get(ctx context.Context, id topoapi.ID) (*topoapi.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[id]
+	if !ok {
+		return nil, errors.NewNotFound("object %s not found", id)
+	}
+	return object, nil
+}
+
+// List lists the topology objects matching the object types and kind filters
+func (s *Store) List(ctx context.Context, filters *topoapi.Filters) ([]topoapi.Object, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	objects := make([]topoapi.Object, 0, len(s.objects))
+	for _, object := range s.objects {
+		if matches(object, filters) {
+			objects = append(objects, *object)
+		}
+	}
+	return objects, nil
+}
+
+// Delete deletes a topology object
+func (s *Store) Delete(ctx context.Context, object *topoapi.Object) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, object.ID)
+	return nil
+}
+
+// Watch watches topology events; the channel is closed once the context is done
+func (s *Store) Watch(ctx context.Context, ch chan<- topoapi.Event, filters *topoapi.Filters) error {
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return nil
+}
+
+func matches(object *topoapi.Object, filters *topoapi.Filters) bool {
+	if filters == nil {
+		return true
+	}
+	if len(filters.ObjectTypes) > 0 {
+		found := false
+		for _, objectType := range filters.ObjectTypes {
+			if object.Type == objectType {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return matchesKind(getKindID(object), filters.KindFilter)
+}
+
+func getKindID(object *topoapi.Object) topoapi.ID {
+	if entity := object.GetEntity(); entity != nil {
+		return entity.KindID
+	}
+	if relation := object.GetRelation(); relation != nil {
+		return relation.KindID
+	}
+	return ""
+}
+
+func matchesKind(kindID topoapi.ID, filter *topoapi.Filter) bool {
+	switch f := filter.GetFilter().(type) {
+	case *topoapi.Filter_Equal_:
+		return string(kindID) == f.Equal_.Value
+	case *topoapi.Filter_Not:
+		return !matchesKind(kindID, f.Not.Inner)
+	case *topoapi.Filter_In:
+		for _, value := range f.In.Values {
+			if string(kindID) == value {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+var _ topo.Store = &Store{}